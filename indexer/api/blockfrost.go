@@ -0,0 +1,372 @@
+package indexerapi
+
+import (
+	"cmp"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/Ethernal-Tech/cardano-infrastructure/indexer/gouroboros"
+	"github.com/Ethernal-Tech/cardano-infrastructure/wallet"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	blockfrostAuthHeaderKey = "project_id"
+	blockfrostMaxPageSize   = 100
+	blockfrostMaxTxSize     = 1 << 16
+)
+
+type BlockfrostServerConfig struct {
+	// ListenAddress is the address of the http server, for example "localhost:8080"
+	ListenAddress string `json:"listenAddress"`
+	// PathPrefix is prepended to all the endpoints, for example "/api/v0"
+	PathPrefix string `json:"pathPrefix"`
+	// ProjectID is checked against `project_id` header of the requests if set
+	ProjectID    string        `json:"projectId"`
+	ReadTimeout  time.Duration `json:"readTimeout"`
+	WriteTimeout time.Duration `json:"writeTimeout"`
+}
+
+// BlockfrostNode is the node connection used for the tip, protocol parameters and submitting txs
+// (for example wallet.TxProviderGoUroBoros or wallet.TxProviderCli)
+type BlockfrostNode interface {
+	wallet.ITxSubmitter
+	wallet.ITxDataRetriever
+}
+
+type blockfrostAmount struct {
+	Unit     string `json:"unit"`
+	Quantity string `json:"quantity"`
+}
+
+type blockfrostUtxo struct {
+	Address     string             `json:"address"`
+	TxHash      string             `json:"tx_hash"`
+	TxIndex     uint32             `json:"tx_index"`
+	OutputIndex uint32             `json:"output_index"`
+	Amount      []blockfrostAmount `json:"amount"`
+	DataHash    *string            `json:"data_hash"`
+	InlineDatum *string            `json:"inline_datum"`
+}
+
+type blockfrostBlock struct {
+	Hash      string `json:"hash"`
+	Slot      uint64 `json:"slot"`
+	Height    uint64 `json:"height"`
+	Epoch     uint64 `json:"epoch"`
+	EpochSlot uint64 `json:"epoch_slot"`
+}
+
+type blockfrostProtocolParameters struct {
+	Epoch               uint64                      `json:"epoch"`
+	ProtocolMajorVer    uint64                      `json:"protocol_major_ver"`
+	ProtocolMinorVer    uint64                      `json:"protocol_minor_ver"`
+	MaxBlockHeaderSize  uint64                      `json:"max_block_header_size"`
+	MaxBlockSize        uint64                      `json:"max_block_size"`
+	MaxTxSize           uint64                      `json:"max_tx_size"`
+	MinFeeA             uint64                      `json:"min_fee_a"`
+	MinFeeB             uint64                      `json:"min_fee_b"`
+	KeyDeposit          string                      `json:"key_deposit"`
+	PoolDeposit         string                      `json:"pool_deposit"`
+	MinPoolCost         string                      `json:"min_pool_cost"`
+	EMax                uint64                      `json:"e_max"`
+	NOpt                uint64                      `json:"n_opt"`
+	A0                  float64                     `json:"a0"`
+	Rho                 float64                     `json:"rho"`
+	Tau                 float64                     `json:"tau"`
+	CollateralPercent   uint64                      `json:"collateral_percent"`
+	PriceMem            float64                     `json:"price_mem"`
+	PriceStep           float64                     `json:"price_step"`
+	CoinsPerUtxoSize    string                      `json:"coins_per_utxo_size"`
+	CoinsPerUtxoWord    string                      `json:"coins_per_utxo_word"`
+	MaxTxExMem          string                      `json:"max_tx_ex_mem"`
+	MaxTxExSteps        string                      `json:"max_tx_ex_steps"`
+	MaxBlockExMem       string                      `json:"max_block_ex_mem"`
+	MaxBlockExSteps     string                      `json:"max_block_ex_steps"`
+	MaxCollateralInputs uint64                      `json:"max_collateral_inputs"`
+	MaxValSize          string                      `json:"max_val_size"`
+	CostModels          map[string]map[string]int64 `json:"cost_models"`
+}
+
+type blockfrostError struct {
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error"`
+	Message    string `json:"message"`
+}
+
+// BlockfrostServer implements the subset of the Blockfrost api used by wallet.TxProviderBlockFrost.
+// Utxos are retrieved from the indexer database and everything else from the node
+type BlockfrostServer struct {
+	*httpService
+	config *BlockfrostServerConfig
+	db     core.Database
+	node   BlockfrostNode
+}
+
+var (
+	_ core.Service = (*BlockfrostServer)(nil)
+	_ http.Handler = (*BlockfrostServer)(nil)
+)
+
+func NewBlockfrostServer(
+	config *BlockfrostServerConfig, db core.Database, node BlockfrostNode, logger hclog.Logger,
+) *BlockfrostServer {
+	server := &BlockfrostServer{
+		config: config,
+		db:     db,
+		node:   node,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+config.PathPrefix+"/addresses/{address}/utxos", server.handleUtxos)
+	mux.HandleFunc("GET "+config.PathPrefix+"/blocks/latest", server.handleLatestBlock)
+	mux.HandleFunc("GET "+config.PathPrefix+"/epochs/latest/parameters", server.handleProtocolParameters)
+	mux.HandleFunc("POST "+config.PathPrefix+"/tx/submit", server.handleSubmitTx)
+
+	server.httpService = newHTTPService(
+		"blockfrost api", config.ListenAddress, config.ReadTimeout, config.WriteTimeout,
+		server.authorize(mux), logger)
+
+	return server
+}
+
+func (s *BlockfrostServer) authorize(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.config.ProjectID != "" && r.Header.Get(blockfrostAuthHeaderKey) != s.config.ProjectID {
+			s.writeError(w, http.StatusForbidden, errors.New("invalid project token"))
+
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// handleUtxos returns unspent outputs of the address ordered by creation (slot).
+// Blockfrost pagination parameters (count, page and order) are supported
+func (s *BlockfrostServer) handleUtxos(w http.ResponseWriter, r *http.Request) {
+	count, err := getUint64Param(r, "count", blockfrostMaxPageSize)
+	if err != nil || count == 0 || count > blockfrostMaxPageSize {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("count must be between 1 and %d", blockfrostMaxPageSize))
+
+		return
+	}
+
+	page, err := getUint64Param(r, "page", 1)
+	if err != nil || page == 0 || page > maxPageNumber {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("page must be between 1 and %d", maxPageNumber))
+
+		return
+	}
+
+	order := r.URL.Query().Get("order")
+	if order != "" && order != "asc" && order != "desc" {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid order: %s", order))
+
+		return
+	}
+
+	txOutputs, err := s.db.GetAllTxOutputs(r.PathValue("address"), true)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	} else if len(txOutputs) == 0 {
+		s.writeError(w, http.StatusNotFound, errors.New("the requested component has not been found"))
+
+		return
+	}
+
+	slices.SortFunc(txOutputs, func(a, b *core.TxInputOutput) int {
+		return cmp.Or(
+			cmp.Compare(a.Output.Slot, b.Output.Slot),
+			slices.Compare(a.Input.Hash[:], b.Input.Hash[:]),
+			cmp.Compare(a.Input.Index, b.Input.Index))
+	})
+
+	if order == "desc" {
+		slices.Reverse(txOutputs)
+	}
+
+	start := min(int((page-1)*count), len(txOutputs)) //nolint:gosec
+	end := min(start+int(count), len(txOutputs))      //nolint:gosec
+	response := make([]blockfrostUtxo, 0, end-start)
+
+	for _, txOutput := range txOutputs[start:end] {
+		response = append(response, newBlockfrostUtxo(txOutput))
+	}
+
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+func (s *BlockfrostServer) handleLatestBlock(w http.ResponseWriter, r *http.Request) {
+	tip, err := s.node.GetTip(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, blockfrostBlock{
+		Hash:      tip.Hash,
+		Slot:      tip.Slot,
+		Height:    tip.Block,
+		Epoch:     tip.Epoch,
+		EpochSlot: tip.SlotInEpoch,
+	})
+}
+
+func (s *BlockfrostServer) handleProtocolParameters(w http.ResponseWriter, r *http.Request) {
+	tip, err := s.node.GetTip(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	ppBytes, err := s.node.GetProtocolParameters(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	var pp wallet.ProtocolParameters
+
+	if err := json.Unmarshal(ppBytes, &pp); err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("invalid protocol parameters: %w", err))
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, newBlockfrostProtocolParameters(tip.Epoch, &pp))
+}
+
+// handleSubmitTx submits cbor serialized signed tx and returns its hash
+func (s *BlockfrostServer) handleSubmitTx(w http.ResponseWriter, r *http.Request) {
+	txSigned, err := io.ReadAll(http.MaxBytesReader(w, r.Body, blockfrostMaxTxSize))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read tx: %w", err))
+
+		return
+	}
+
+	txInfo, err := gouroboros.ParseTxInfo(txSigned, false)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if err := s.node.SubmitTx(r.Context(), txSigned); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("failed to submit tx: %w", err))
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, txInfo.Hash)
+}
+
+func (s *BlockfrostServer) writeError(w http.ResponseWriter, statusCode int, err error) {
+	if statusCode == http.StatusInternalServerError {
+		s.logger.Error("Blockfrost api request failed", "err", err)
+	}
+
+	s.writeJSON(w, statusCode, blockfrostError{
+		StatusCode: statusCode,
+		Error:      http.StatusText(statusCode),
+		Message:    err.Error(),
+	})
+}
+
+func newBlockfrostUtxo(txOutput *core.TxInputOutput) blockfrostUtxo {
+	amount := make([]blockfrostAmount, 0, len(txOutput.Output.Tokens)+1)
+	amount = append(amount, blockfrostAmount{
+		Unit:     wallet.AdaTokenName,
+		Quantity: strconv.FormatUint(txOutput.Output.Amount, 10),
+	})
+
+	for _, token := range txOutput.Output.Tokens {
+		amount = append(amount, blockfrostAmount{
+			Unit:     token.PolicyID + hex.EncodeToString([]byte(token.Name)),
+			Quantity: strconv.FormatUint(token.Amount, 10),
+		})
+	}
+
+	utxo := blockfrostUtxo{
+		Address:     txOutput.Output.Address,
+		TxHash:      txOutput.Input.Hash.String(),
+		TxIndex:     txOutput.Input.Index,
+		OutputIndex: txOutput.Input.Index,
+		Amount:      amount,
+	}
+
+	if len(txOutput.Output.Datum) > 0 {
+		dataHash := wallet.GetDatumHash(txOutput.Output.Datum)
+		inlineDatum := hex.EncodeToString(txOutput.Output.Datum)
+		utxo.DataHash, utxo.InlineDatum = &dataHash, &inlineDatum
+	} else if txOutput.Output.DatumHash != (core.Hash{}) {
+		dataHash := txOutput.Output.DatumHash.String()
+		utxo.DataHash = &dataHash
+	}
+
+	return utxo
+}
+
+func newBlockfrostProtocolParameters(epoch uint64, pp *wallet.ProtocolParameters) blockfrostProtocolParameters {
+	uint64ToStr := func(value uint64) string {
+		return strconv.FormatUint(value, 10)
+	}
+
+	result := blockfrostProtocolParameters{
+		Epoch:               epoch,
+		ProtocolMajorVer:    pp.ProtocolVersion.Major,
+		ProtocolMinorVer:    pp.ProtocolVersion.Minor,
+		MaxBlockHeaderSize:  pp.MaxBlockHeaderSize,
+		MaxBlockSize:        pp.MaxBlockBodySize,
+		MaxTxSize:           pp.MaxTxSize,
+		MinFeeA:             pp.TxFeePerByte,
+		MinFeeB:             pp.TxFeeFixed,
+		KeyDeposit:          uint64ToStr(pp.StakeAddressDeposit),
+		PoolDeposit:         uint64ToStr(pp.StakePoolDeposit),
+		MinPoolCost:         uint64ToStr(pp.MinPoolCost),
+		EMax:                pp.PoolRetireMaxEpoch,
+		NOpt:                pp.StakePoolTargetNum,
+		A0:                  pp.PoolPledgeInfluence,
+		Rho:                 pp.MonetaryExpansion,
+		Tau:                 pp.TreasuryCut,
+		CollateralPercent:   pp.CollateralPercentage,
+		PriceMem:            pp.ExecutionUnitPrices.PriceMemory,
+		PriceStep:           pp.ExecutionUnitPrices.PriceSteps,
+		CoinsPerUtxoSize:    uint64ToStr(pp.UtxoCostPerByte),
+		CoinsPerUtxoWord:    uint64ToStr(pp.UtxoCostPerByte),
+		MaxTxExMem:          uint64ToStr(pp.MaxTxExecutionUnits.Memory),
+		MaxTxExSteps:        uint64ToStr(pp.MaxTxExecutionUnits.Steps),
+		MaxBlockExMem:       uint64ToStr(pp.MaxBlockExecutionUnits.Memory),
+		MaxBlockExSteps:     uint64ToStr(pp.MaxBlockExecutionUnits.Steps),
+		MaxCollateralInputs: pp.MaxCollateralInputs,
+		MaxValSize:          uint64ToStr(pp.MaxValueSize),
+		CostModels:          make(map[string]map[string]int64, len(pp.CostModels)),
+	}
+
+	// cost models are returned with the parameter indexes as keys
+	for name, values := range pp.CostModels {
+		costModel := make(map[string]int64, len(values))
+
+		for i, value := range values {
+			costModel[strconv.Itoa(i)] = value
+		}
+
+		result.CostModels[name] = costModel
+	}
+
+	return result
+}
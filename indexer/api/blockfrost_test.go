@@ -0,0 +1,192 @@
+package indexerapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/Ethernal-Tech/cardano-infrastructure/wallet"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+type blockfrostNodeMock struct {
+	tip         wallet.QueryTipData
+	pp          []byte
+	submitErr   error
+	submittedTx []byte
+}
+
+func (m *blockfrostNodeMock) GetTip(_ context.Context) (wallet.QueryTipData, error) {
+	return m.tip, nil
+}
+
+func (m *blockfrostNodeMock) GetProtocolParameters(_ context.Context) ([]byte, error) {
+	return m.pp, nil
+}
+
+func (m *blockfrostNodeMock) SubmitTx(_ context.Context, txSigned []byte) error {
+	m.submittedTx = txSigned
+
+	return m.submitErr
+}
+
+func TestBlockfrostServer(t *testing.T) {
+	t.Parallel()
+
+	const (
+		address   = "addr_test1"
+		projectID = "project"
+		policyID  = "29f2fe0e9ae1bc0fdff1d0a3e9d13f4e5c2bb2fdb6d0a9b5bb2a6f5a"
+		txRawHex  = "84a50081825820bb88a2541d545044e400d37c3db3eeb7a452fd9f2c461c89451f7191cc4f4079000181825839301" +
+			"ab8db33cbfe7f75036e213f48500a4723c6f48311beb8e39884ceeab59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e" +
+			"2f08f15f1a00956a53021a00032c2d031923fb048182008201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2" +
+			"f08f15fa10181830303848200581c0fb340e2fc18865fbf406dce76f743de13c46d2eb91d6e87e6eb63c68200581c41b46f772b" +
+			"622e7e5bc8970d128faccb7a457c610a48d514801a04118200581c5282885af1f234cb9407f05b120f2eb06872f297864ca9066" +
+			"a6570118200581c6a2f73455484b658c168c18ed54222d189e7e746ec3dc2d8d8891e42f5f6"
+	)
+
+	pp := wallet.ProtocolParameters{
+		CostModels:             map[string][]int64{"PlutusV2": {100, 200, 300}},
+		ProtocolVersion:        wallet.NewProtocolParametersVersion(9, 1),
+		MaxBlockHeaderSize:     1100,
+		MaxBlockBodySize:       90112,
+		MaxTxSize:              16384,
+		TxFeeFixed:             155381,
+		TxFeePerByte:           44,
+		StakeAddressDeposit:    2_000_000,
+		StakePoolDeposit:       500_000_000,
+		MinPoolCost:            170_000_000,
+		PoolRetireMaxEpoch:     18,
+		StakePoolTargetNum:     500,
+		PoolPledgeInfluence:    0.3,
+		MonetaryExpansion:      0.003,
+		TreasuryCut:            0.2,
+		CollateralPercentage:   150,
+		ExecutionUnitPrices:    wallet.NewProtocolParametersPriceMemorySteps(0.0577, 0.0000721),
+		UtxoCostPerByte:        4310,
+		MaxTxExecutionUnits:    wallet.NewProtocolParametersMemorySteps(14_000_000, 10_000_000_000),
+		MaxBlockExecutionUnits: wallet.NewProtocolParametersMemorySteps(62_000_000, 20_000_000_000),
+		MaxCollateralInputs:    3,
+		MaxValueSize:           5000,
+	}
+
+	ppBytes, err := json.Marshal(pp)
+	require.NoError(t, err)
+
+	txRaw, err := hex.DecodeString(txRawHex)
+	require.NoError(t, err)
+
+	dbMock := &core.DatabaseMock{}
+	node := &blockfrostNodeMock{
+		tip: wallet.QueryTipData{Block: 10, Epoch: 2, Hash: "ff", Slot: 1000, SlotInEpoch: 100},
+		pp:  ppBytes,
+	}
+	httpServer := httptest.NewServer(NewBlockfrostServer(&BlockfrostServerConfig{
+		PathPrefix: "/api/v0",
+		ProjectID:  projectID,
+	}, dbMock, node, hclog.NewNullLogger()))
+
+	t.Cleanup(httpServer.Close)
+
+	provider := wallet.NewTxProviderBlockFrost(httpServer.URL+"/api/v0", projectID)
+
+	dbMock.On("GetAllTxOutputs", address, true).Return([]*core.TxInputOutput{
+		{
+			Input: core.TxInput{Hash: core.Hash{2}, Index: 1},
+			Output: core.TxOutput{
+				Address: address, Slot: 20, Amount: 200, Datum: []byte{0x18, 0x2a},
+				Tokens: []core.TokenAmount{{PolicyID: policyID, Name: "Route3", Amount: 5}},
+			},
+		},
+		{
+			Input:  core.TxInput{Hash: core.Hash{1}},
+			Output: core.TxOutput{Address: address, Slot: 10, Amount: 100},
+		},
+	}, nil)
+	dbMock.On("GetAllTxOutputs", "addr_test2", true).Return([]*core.TxInputOutput(nil), nil)
+
+	utxos, err := provider.GetUtxos(context.Background(), address)
+	require.NoError(t, err)
+	require.Equal(t, []wallet.Utxo{
+		{Hash: core.Hash{1}.String(), Index: 0, Amount: 100},
+		{
+			Hash: core.Hash{2}.String(), Index: 1, Amount: 200,
+			Tokens: []wallet.TokenAmount{wallet.NewTokenAmount(wallet.NewToken(policyID, "Route3"), 5)},
+		},
+	}, utxos)
+
+	utxos, err = provider.GetUtxos(context.Background(), "addr_test2")
+	require.NoError(t, err)
+	require.Empty(t, utxos)
+
+	tip, err := provider.GetTip(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, node.tip, tip)
+
+	ppResult, err := provider.GetProtocolParameters(context.Background())
+	require.NoError(t, err)
+	require.JSONEq(t, string(ppBytes), string(ppResult))
+
+	require.NoError(t, provider.SubmitTx(context.Background(), txRaw))
+	require.Equal(t, txRaw, node.submittedTx)
+
+	node.submitErr = errors.New("tx rejected")
+
+	require.ErrorContains(t, provider.SubmitTx(context.Background(), txRaw), "tx rejected")
+	require.ErrorContains(t, provider.SubmitTx(context.Background(), []byte{1, 2}), "status code 400")
+
+	require.ErrorContains(t, wallet.NewTxProviderBlockFrost(httpServer.URL+"/api/v0", "invalid").
+		SubmitTx(context.Background(), txRaw), "invalid project token")
+}
+
+func TestBlockfrostServer_UtxosPagination(t *testing.T) {
+	t.Parallel()
+
+	const address = "addr_test1"
+
+	dbMock := &core.DatabaseMock{}
+	server := NewBlockfrostServer(&BlockfrostServerConfig{}, dbMock, &blockfrostNodeMock{}, hclog.NewNullLogger())
+
+	dbMock.On("GetAllTxOutputs", address, true).Return([]*core.TxInputOutput{
+		{Input: core.TxInput{Hash: core.Hash{3}}, Output: core.TxOutput{Address: address, Slot: 30}},
+		{Input: core.TxInput{Hash: core.Hash{1}}, Output: core.TxOutput{Address: address, Slot: 10}},
+		{Input: core.TxInput{Hash: core.Hash{2}}, Output: core.TxOutput{Address: address, Slot: 20}},
+	}, nil)
+
+	getHashes := func(url string, statusCode int) (result []string) {
+		recorder := httptest.NewRecorder()
+
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+		require.Equal(t, statusCode, recorder.Code, recorder.Body.String())
+
+		if statusCode == http.StatusOK {
+			var utxos []blockfrostUtxo
+
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&utxos))
+
+			for _, utxo := range utxos {
+				result = append(result, utxo.TxHash)
+			}
+		}
+
+		return result
+	}
+
+	require.Equal(t, []string{core.Hash{1}.String(), core.Hash{2}.String()},
+		getHashes("/addresses/"+address+"/utxos?count=2", http.StatusOK))
+	require.Equal(t, []string{core.Hash{3}.String()},
+		getHashes("/addresses/"+address+"/utxos?count=2&page=2", http.StatusOK))
+	require.Equal(t, []string{core.Hash{3}.String(), core.Hash{2}.String()},
+		getHashes("/addresses/"+address+"/utxos?count=2&order=desc", http.StatusOK))
+	require.Empty(t, getHashes("/addresses/"+address+"/utxos?page=3&count=2", http.StatusOK))
+
+	getHashes("/addresses/"+address+"/utxos?count=101", http.StatusBadRequest)
+	getHashes("/addresses/"+address+"/utxos?order=random", http.StatusBadRequest)
+}
@@ -0,0 +1,108 @@
+package indexerapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	defaultReadTimeout  = 10 * time.Second
+	defaultWriteTimeout = 30 * time.Second
+	shutdownTimeout     = 5 * time.Second
+)
+
+// httpService is the common part of the api servers: lifecycle of the http server and writing responses
+type httpService struct {
+	name          string
+	listenAddress string
+	readTimeout   time.Duration
+	writeTimeout  time.Duration
+	handler       http.Handler
+	httpServer    *http.Server
+	isClosed      uint32
+	errorCh       chan error
+	logger        hclog.Logger
+}
+
+func newHTTPService(
+	name, listenAddress string, readTimeout, writeTimeout time.Duration, handler http.Handler, logger hclog.Logger,
+) *httpService {
+	return &httpService{
+		name:          name,
+		listenAddress: listenAddress,
+		readTimeout:   getDuration(readTimeout, defaultReadTimeout),
+		writeTimeout:  getDuration(writeTimeout, defaultWriteTimeout),
+		handler:       handler,
+		errorCh:       make(chan error, 1),
+		logger:        logger,
+	}
+}
+
+// Start starts listening on the configured address. Serving errors are sent to the error channel
+func (hs *httpService) Start() error {
+	listener, err := net.Listen("tcp", hs.listenAddress)
+	if err != nil {
+		return err
+	}
+
+	hs.httpServer = &http.Server{
+		Handler:      hs.handler,
+		ReadTimeout:  hs.readTimeout,
+		WriteTimeout: hs.writeTimeout,
+	}
+
+	go func() {
+		hs.logger.Info("Server has been started", "name", hs.name, "address", listener.Addr())
+
+		if err := hs.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			hs.errorCh <- err
+		}
+	}()
+
+	return nil
+}
+
+func (hs *httpService) Close() error {
+	if !atomic.CompareAndSwapUint32(&hs.isClosed, 0, 1) || hs.httpServer == nil {
+		return nil
+	}
+
+	hs.logger.Info("Closing server", "name", hs.name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return hs.httpServer.Shutdown(ctx)
+}
+
+func (hs *httpService) ErrorCh() <-chan error {
+	return hs.errorCh
+}
+
+func (hs *httpService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hs.handler.ServeHTTP(w, r)
+}
+
+func (hs *httpService) writeJSON(w http.ResponseWriter, statusCode int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		hs.logger.Debug("Failed to write response", "name", hs.name, "err", err)
+	}
+}
+
+func getDuration(value, defaultValue time.Duration) time.Duration {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
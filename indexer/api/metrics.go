@@ -0,0 +1,296 @@
+package indexerapi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+)
+
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultDurationBuckets are the upper bounds (in seconds) of the duration histogram buckets
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type metricType string
+
+const (
+	metricTypeCounter   metricType = "counter"
+	metricTypeGauge     metricType = "gauge"
+	metricTypeHistogram metricType = "histogram"
+)
+
+type metricSeries struct {
+	labels string
+	value  float64
+	// histogram only: cumulative counts are calculated when written
+	bucketCounts []uint64
+	count        uint64
+}
+
+type metricFamily struct {
+	name   string
+	help   string
+	kind   metricType
+	series map[string]*metricSeries
+}
+
+// PrometheusMetrics keeps measurements in memory and exposes them in the prometheus text format.
+// Durations are exposed as histograms in seconds
+type PrometheusMetrics struct {
+	buckets  []float64
+	families map[string]*metricFamily
+	lock     sync.Mutex
+}
+
+var (
+	_ core.Metrics = (*PrometheusMetrics)(nil)
+	_ http.Handler = (*PrometheusMetrics)(nil)
+)
+
+// NewPrometheusMetrics creates metrics with the given histogram buckets, DefaultDurationBuckets are used if empty
+func NewPrometheusMetrics(buckets []float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &PrometheusMetrics{
+		buckets:  buckets,
+		families: map[string]*metricFamily{},
+	}
+}
+
+func (m *PrometheusMetrics) AddCounter(name string, value float64, labels ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if series := m.getSeriesNoLock(name, metricTypeCounter, labels); series != nil && value > 0 {
+		series.value += value
+	}
+}
+
+func (m *PrometheusMetrics) SetGauge(name string, value float64, labels ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if series := m.getSeriesNoLock(name, metricTypeGauge, labels); series != nil {
+		series.value = value
+	}
+}
+
+func (m *PrometheusMetrics) ObserveDuration(name string, duration time.Duration, labels ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	series := m.getSeriesNoLock(name, metricTypeHistogram, labels)
+	if series == nil {
+		return
+	}
+
+	seconds := duration.Seconds()
+
+	if series.bucketCounts == nil {
+		series.bucketCounts = make([]uint64, len(m.buckets))
+	}
+
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			series.bucketCounts[i]++
+
+			break
+		}
+	}
+
+	series.value += seconds
+	series.count++
+}
+
+// WriteTo writes all the metrics sorted by name in the prometheus text format
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	for _, family := range m.sortedFamiliesNoLock() {
+		if family.help != "" {
+			fmt.Fprintf(cw, "# HELP %s %s\n", family.name, escapeHelp(family.help))
+		}
+
+		fmt.Fprintf(cw, "# TYPE %s %s\n", family.name, family.kind)
+
+		for _, series := range sortedSeries(family) {
+			if family.kind != metricTypeHistogram {
+				fmt.Fprintf(cw, "%s%s %s\n", family.name, wrapLabels(series.labels), formatFloat(series.value))
+
+				continue
+			}
+
+			cumulative := uint64(0)
+
+			for i, bound := range m.buckets {
+				if series.bucketCounts != nil {
+					cumulative += series.bucketCounts[i]
+				}
+
+				fmt.Fprintf(cw, "%s_bucket%s %d\n",
+					family.name, wrapLabels(joinLabels(series.labels, "le", formatFloat(bound))), cumulative)
+			}
+
+			fmt.Fprintf(cw, "%s_bucket%s %d\n",
+				family.name, wrapLabels(joinLabels(series.labels, "le", "+Inf")), series.count)
+			fmt.Fprintf(cw, "%s_sum%s %s\n", family.name, wrapLabels(series.labels), formatFloat(series.value))
+			fmt.Fprintf(cw, "%s_count%s %d\n", family.name, wrapLabels(series.labels), series.count)
+		}
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = m.WriteTo(w)
+}
+
+// getSeriesNoLock returns nil if the metric is already registered with the different type
+func (m *PrometheusMetrics) getSeriesNoLock(name string, kind metricType, labels []string) *metricSeries {
+	family, exists := m.families[name]
+	if !exists {
+		family = &metricFamily{
+			name:   name,
+			help:   core.MetricDescriptions[name],
+			kind:   kind,
+			series: map[string]*metricSeries{},
+		}
+		m.families[name] = family
+	} else if family.kind != kind {
+		return nil
+	}
+
+	key := formatLabels(labels)
+
+	series, exists := family.series[key]
+	if !exists {
+		series = &metricSeries{labels: key}
+		family.series[key] = series
+	}
+
+	return series
+}
+
+func (m *PrometheusMetrics) sortedFamiliesNoLock() []*metricFamily {
+	families := make([]*metricFamily, 0, len(m.families))
+	for _, family := range m.families {
+		families = append(families, family)
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	return families
+}
+
+func sortedSeries(family *metricFamily) []*metricSeries {
+	result := make([]*metricSeries, 0, len(family.series))
+	for _, series := range family.series {
+		result = append(result, series)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].labels < result[j].labels
+	})
+
+	return result
+}
+
+// formatLabels formats key value pairs without braces. Last key without value is ignored
+func formatLabels(labels []string) string {
+	var sb strings.Builder
+
+	for i := 0; i+1 < len(labels); i += 2 {
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+
+		sb.WriteString(labels[i])
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(labels[i+1]))
+		sb.WriteByte('"')
+	}
+
+	return sb.String()
+}
+
+func joinLabels(labels string, key string, value string) string {
+	label := formatLabels([]string{key, value})
+	if labels == "" {
+		return label
+	}
+
+	return labels + "," + label
+}
+
+func wrapLabels(labels string) string {
+	if labels == "" {
+		return ""
+	}
+
+	return "{" + labels + "}"
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+
+	return n, err
+}
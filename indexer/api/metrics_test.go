@@ -0,0 +1,76 @@
+package indexerapi
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := NewPrometheusMetrics([]float64{1, 0.1})
+
+	metrics.AddCounter(core.MetricBlocksRolledForward, 2)
+	metrics.AddCounter(core.MetricBlocksRolledForward, 3)
+	metrics.AddCounter(core.MetricSyncerReconnects, 1, "peer", "b:3001")
+	metrics.AddCounter(core.MetricSyncerReconnects, 1, "peer", `a"\`+"\n")
+	metrics.SetGauge(core.MetricRunnerQueueDepth, 7)
+	metrics.SetGauge(core.MetricRunnerQueueDepth, 4)
+	metrics.ObserveDuration(core.MetricDBCommitDuration, time.Millisecond*50)
+	metrics.ObserveDuration(core.MetricDBCommitDuration, time.Millisecond*500)
+	metrics.ObserveDuration(core.MetricDBCommitDuration, time.Second*2)
+	// registered with the different type -> ignored
+	metrics.SetGauge(core.MetricBlocksRolledForward, 100)
+
+	var buf bytes.Buffer
+
+	n, err := metrics.WriteTo(&buf)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(buf.Len()), n)
+	require.Equal(t, `# HELP cardano_indexer_blocks_rolled_forward_total Number of the blocks rolled forward by the block indexer
+# TYPE cardano_indexer_blocks_rolled_forward_total counter
+cardano_indexer_blocks_rolled_forward_total 5
+# HELP cardano_indexer_db_commit_duration_seconds Duration of the database transactions which save confirmed blocks
+# TYPE cardano_indexer_db_commit_duration_seconds histogram
+cardano_indexer_db_commit_duration_seconds_bucket{le="0.1"} 1
+cardano_indexer_db_commit_duration_seconds_bucket{le="1"} 2
+cardano_indexer_db_commit_duration_seconds_bucket{le="+Inf"} 3
+cardano_indexer_db_commit_duration_seconds_sum 2.55
+cardano_indexer_db_commit_duration_seconds_count 3
+# HELP cardano_indexer_runner_queue_depth Number of the items waiting in the block indexer runner queue
+# TYPE cardano_indexer_runner_queue_depth gauge
+cardano_indexer_runner_queue_depth 4
+# HELP cardano_indexer_syncer_reconnects_total Number of the block syncer restarts after the synchronization error
+# TYPE cardano_indexer_syncer_reconnects_total counter
+cardano_indexer_syncer_reconnects_total{peer="a\"\\\n"} 1
+cardano_indexer_syncer_reconnects_total{peer="b:3001"} 1
+`, buf.String())
+
+	t.Run("server endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		server := NewServer(&ServerConfig{PathPrefix: "/api"}, &core.DatabaseMock{}, hclog.NewNullLogger())
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/metrics", nil))
+
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+
+		server.SetMetricsHandler(metrics)
+
+		recorder = httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/metrics", nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, prometheusContentType, recorder.Header().Get("Content-Type"))
+		require.Contains(t, recorder.Body.String(), "cardano_indexer_runner_queue_depth 4\n")
+	})
+}
@@ -0,0 +1,400 @@
+package indexerapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	defaultPageSize    = 100
+	defaultMaxPageSize = 1000
+	// maxPageNumber prevents overflow of the page offset
+	maxPageNumber = 1 << 20
+)
+
+type ServerConfig struct {
+	// ListenAddress is the address of the http server, for example "localhost:8080"
+	ListenAddress string `json:"listenAddress"`
+	// PathPrefix is prepended to all the endpoints, for example "/api/v1"
+	PathPrefix      string        `json:"pathPrefix"`
+	DefaultPageSize int           `json:"defaultPageSize"`
+	MaxPageSize     int           `json:"maxPageSize"`
+	ReadTimeout     time.Duration `json:"readTimeout"`
+	WriteTimeout    time.Duration `json:"writeTimeout"`
+}
+
+// SyncStatus is the response of the status endpoint
+type SyncStatus struct {
+	LatestBlockPoint     *core.BlockPoint   `json:"latestPoint"`
+	LatestConfirmedBlock *core.CardanoBlock `json:"latestBlock"`
+	// Progress is set only if the sync progress provider is set
+	Progress *core.SyncProgress `json:"progress,omitempty"`
+}
+
+// PageResponse is the response of the paginated endpoints. Next is set only if there are more items
+type PageResponse[T any] struct {
+	Items []T    `json:"items"`
+	Page  int    `json:"page,omitempty"`
+	Count int    `json:"count"`
+	Next  string `json:"next,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server is read only http/json query api over the indexer database.
+// It can be started as standalone service or embedded as http.Handler
+type Server struct {
+	*httpService
+	config           *ServerConfig
+	db               core.Database
+	progressProvider core.SyncProgressProvider
+	metricsHandler   http.Handler
+}
+
+var (
+	_ core.Service = (*Server)(nil)
+	_ http.Handler = (*Server)(nil)
+)
+
+func NewServer(config *ServerConfig, db core.Database, logger hclog.Logger) *Server {
+	server := &Server{
+		config: config,
+		db:     db,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+config.PathPrefix+"/utxos/{address}", server.handleUtxos)
+	mux.HandleFunc("GET "+config.PathPrefix+"/txs/{hash}", server.handleTx)
+	mux.HandleFunc("GET "+config.PathPrefix+"/blocks", server.handleBlocks)
+	mux.HandleFunc("GET "+config.PathPrefix+"/blocks/latest", server.handleLatestBlocks)
+	mux.HandleFunc("GET "+config.PathPrefix+"/blocks/{slot}/txs", server.handleBlockTxs)
+	mux.HandleFunc("GET "+config.PathPrefix+"/latest-point", server.handleLatestPoint)
+	mux.HandleFunc("GET "+config.PathPrefix+"/status", server.handleStatus)
+	mux.HandleFunc("GET "+config.PathPrefix+"/metrics", server.handleMetrics)
+
+	server.httpService = newHTTPService(
+		"indexer api", config.ListenAddress, config.ReadTimeout, config.WriteTimeout, mux, logger)
+
+	return server
+}
+
+// handleUtxos returns outputs of the address sorted by tx hash and index.
+// Used (soft deleted) outputs are returned only if `includeUsed=true`
+func (s *Server) handleUtxos(w http.ResponseWriter, r *http.Request) {
+	page, count, err := s.getPagination(r, "page")
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	includeUsed, err := getBoolParam(r, "includeUsed")
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	txOutputs, err := s.db.GetAllTxOutputs(r.PathValue("address"), !includeUsed)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	core.SortTxInputOutputs(txOutputs)
+
+	response := PageResponse[*core.TxInputOutput]{
+		Items: []*core.TxInputOutput{},
+		Page:  page,
+	}
+
+	if start := (page - 1) * count; start < len(txOutputs) {
+		end := min(start+count, len(txOutputs))
+		response.Items = txOutputs[start:end]
+
+		if end < len(txOutputs) {
+			response.Next = strconv.Itoa(page + 1)
+		}
+	}
+
+	response.Count = len(response.Items)
+
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleTx(w http.ResponseWriter, r *http.Request) {
+	hash, err := getHashParam(r.PathValue("hash"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	tx, err := s.db.GetTxByHash(hash)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	} else if tx == nil {
+		s.writeError(w, http.StatusNotFound, fmt.Errorf("tx %s not found", hash))
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, tx)
+}
+
+// handleBlocks returns confirmed blocks with slot in [from, to] range. Next is the `from` value of the next page
+func (s *Server) handleBlocks(w http.ResponseWriter, r *http.Request) {
+	_, count, err := s.getPagination(r, "")
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	fromSlot, err := getUint64Param(r, "from", 0)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	toSlot, err := getUint64Param(r, "to", ^uint64(0))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	// one more block is retrieved to check if there is the next page
+	blocks, err := s.db.GetConfirmedBlocksFrom(fromSlot, count+1)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	response := PageResponse[*core.CardanoBlock]{
+		Items: make([]*core.CardanoBlock, 0, len(blocks)),
+	}
+
+	for i, block := range blocks {
+		if block.Slot > toSlot {
+			break
+		} else if i == count {
+			response.Next = strconv.FormatUint(block.Slot, 10)
+
+			break
+		}
+
+		response.Items = append(response.Items, block)
+	}
+
+	response.Count = len(response.Items)
+
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleLatestBlocks returns the latest confirmed blocks (newest first)
+func (s *Server) handleLatestBlocks(w http.ResponseWriter, r *http.Request) {
+	_, count, err := s.getPagination(r, "")
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	blocks, err := s.db.GetLatestConfirmedBlocks(count)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	if blocks == nil {
+		blocks = []*core.CardanoBlock{}
+	}
+
+	s.writeJSON(w, http.StatusOK, PageResponse[*core.CardanoBlock]{Items: blocks, Count: len(blocks)})
+}
+
+// handleBlockTxs returns saved txs (only txs of interest are saved) of the confirmed block
+func (s *Server) handleBlockTxs(w http.ResponseWriter, r *http.Request) {
+	slot, err := strconv.ParseUint(r.PathValue("slot"), 10, 64)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid slot: %s", r.PathValue("slot")))
+
+		return
+	}
+
+	txs, err := s.db.GetConfirmedBlockTxs(slot)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	if txs == nil {
+		txs = []*core.Tx{}
+	}
+
+	s.writeJSON(w, http.StatusOK, PageResponse[*core.Tx]{Items: txs, Count: len(txs)})
+}
+
+func (s *Server) handleLatestPoint(w http.ResponseWriter, _ *http.Request) {
+	point, err := s.db.GetLatestBlockPoint()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	} else if point == nil {
+		s.writeError(w, http.StatusNotFound, errors.New("latest block point not found"))
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, point)
+}
+
+// SetSyncProgressProvider sets the provider (block syncer or runner) of the progress returned by the status endpoint.
+// It must be called before the server is started
+func (s *Server) SetSyncProgressProvider(provider core.SyncProgressProvider) {
+	s.progressProvider = provider
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	point, err := s.db.GetLatestBlockPoint()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	blocks, err := s.db.GetLatestConfirmedBlocks(1)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	status := SyncStatus{
+		LatestBlockPoint: point,
+	}
+
+	if len(blocks) > 0 {
+		status.LatestConfirmedBlock = blocks[0]
+	}
+
+	if s.progressProvider != nil {
+		progress := s.progressProvider.GetSyncProgress()
+		status.Progress = &progress
+	}
+
+	s.writeJSON(w, http.StatusOK, status)
+}
+
+// SetMetricsHandler sets the handler (for example PrometheusMetrics) of the metrics endpoint.
+// It must be called before the server is started
+func (s *Server) SetMetricsHandler(handler http.Handler) {
+	s.metricsHandler = handler
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.metricsHandler == nil {
+		s.writeError(w, http.StatusNotFound, errors.New("metrics are not enabled"))
+
+		return
+	}
+
+	s.metricsHandler.ServeHTTP(w, r)
+}
+
+func (s *Server) getPagination(r *http.Request, pageParam string) (int, int, error) {
+	maxPageSize := s.config.MaxPageSize
+	if maxPageSize <= 0 {
+		maxPageSize = defaultMaxPageSize
+	}
+
+	defaultCount := s.config.DefaultPageSize
+	if defaultCount <= 0 {
+		defaultCount = min(defaultPageSize, maxPageSize)
+	}
+
+	count, err := getUint64Param(r, "count", uint64(defaultCount)) //nolint:gosec
+	if err != nil {
+		return 0, 0, err
+	} else if count == 0 || count > uint64(maxPageSize) { //nolint:gosec
+		return 0, 0, fmt.Errorf("count must be between 1 and %d", maxPageSize)
+	}
+
+	page := uint64(1)
+
+	if pageParam != "" {
+		page, err = getUint64Param(r, pageParam, 1)
+		if err != nil {
+			return 0, 0, err
+		} else if page == 0 || page > uint64(maxPageNumber) {
+			return 0, 0, fmt.Errorf("%s must be between 1 and %d", pageParam, maxPageNumber)
+		}
+	}
+
+	return int(page), int(count), nil //nolint:gosec
+}
+
+func (s *Server) writeError(w http.ResponseWriter, statusCode int, err error) {
+	if statusCode == http.StatusInternalServerError {
+		s.logger.Error("Api request failed", "err", err)
+	}
+
+	s.writeJSON(w, statusCode, errorResponse{Error: err.Error()})
+}
+
+func getUint64Param(r *http.Request, name string, defaultValue uint64) (uint64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+
+	return result, nil
+}
+
+func getBoolParam(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", name, value)
+	}
+
+	return result, nil
+}
+
+func getHashParam(value string) (core.Hash, error) {
+	value = strings.ToLower(strings.TrimPrefix(value, "0x"))
+	hash := core.NewHashFromHexString(value)
+	// NewHashFromHexString ignores invalid input so the result must be compared to the input
+	if hash.String() != value {
+		return core.Hash{}, fmt.Errorf("invalid hash: %s", value)
+	}
+
+	return hash, nil
+}
@@ -0,0 +1,206 @@
+package indexerapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	const address = "addr_test1"
+
+	txOutputs := []*core.TxInputOutput{
+		{Input: core.TxInput{Hash: core.Hash{3}}, Output: core.TxOutput{Address: address, Amount: 30}},
+		{Input: core.TxInput{Hash: core.Hash{1}, Index: 1}, Output: core.TxOutput{Address: address, Amount: 10}},
+		{Input: core.TxInput{Hash: core.Hash{2}}, Output: core.TxOutput{Address: address, Amount: 20, IsUsed: true}},
+	}
+	blocks := []*core.CardanoBlock{
+		{Slot: 10, Hash: core.Hash{10}, Number: 1},
+		{Slot: 20, Hash: core.Hash{20}, Number: 2},
+		{Slot: 30, Hash: core.Hash{30}, Number: 3},
+	}
+	tx := &core.Tx{BlockSlot: 20, BlockHash: core.Hash{20}, Hash: core.Hash{5}, Fee: 100}
+
+	get := func(t *testing.T, handler http.Handler, url string, statusCode int, response any) {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+		require.Equal(t, statusCode, recorder.Code, recorder.Body.String())
+		require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(response))
+	}
+
+	t.Run("utxos", func(t *testing.T) {
+		t.Parallel()
+
+		dbMock := &core.DatabaseMock{}
+		server := NewServer(&ServerConfig{PathPrefix: "/api/v1", MaxPageSize: 10}, dbMock, hclog.NewNullLogger())
+
+		dbMock.On("GetAllTxOutputs", address, true).Return([]*core.TxInputOutput{txOutputs[0], txOutputs[1]}, nil)
+		dbMock.On("GetAllTxOutputs", address, false).Return(slices.Clone(txOutputs), nil)
+
+		var response PageResponse[*core.TxInputOutput]
+
+		get(t, server, "/api/v1/utxos/"+address, http.StatusOK, &response)
+		require.Equal(t, PageResponse[*core.TxInputOutput]{
+			Items: []*core.TxInputOutput{txOutputs[1], txOutputs[0]}, Page: 1, Count: 2,
+		}, response)
+
+		response = PageResponse[*core.TxInputOutput]{}
+
+		get(t, server, "/api/v1/utxos/"+address+"?includeUsed=true&count=2&page=1", http.StatusOK, &response)
+		require.Equal(t, PageResponse[*core.TxInputOutput]{
+			Items: []*core.TxInputOutput{txOutputs[1], txOutputs[2]}, Page: 1, Count: 2, Next: "2",
+		}, response)
+
+		response = PageResponse[*core.TxInputOutput]{}
+
+		get(t, server, "/api/v1/utxos/"+address+"?includeUsed=true&count=2&page=2", http.StatusOK, &response)
+		require.Equal(t, PageResponse[*core.TxInputOutput]{
+			Items: []*core.TxInputOutput{txOutputs[0]}, Page: 2, Count: 1,
+		}, response)
+
+		var errResponse errorResponse
+
+		get(t, server, "/api/v1/utxos/"+address+"?count=11", http.StatusBadRequest, &errResponse)
+		require.Equal(t, "count must be between 1 and 10", errResponse.Error)
+
+		get(t, server, "/api/v1/utxos/"+address+"?page=0", http.StatusBadRequest, &errResponse)
+		get(t, server, "/api/v1/utxos/"+address+"?includeUsed=maybe", http.StatusBadRequest, &errResponse)
+	})
+
+	t.Run("tx", func(t *testing.T) {
+		t.Parallel()
+
+		dbMock := &core.DatabaseMock{}
+		server := NewServer(&ServerConfig{}, dbMock, hclog.NewNullLogger())
+
+		dbMock.On("GetTxByHash", tx.Hash).Return(tx, nil)
+		dbMock.On("GetTxByHash", core.Hash{6}).Return((*core.Tx)(nil), nil)
+		dbMock.On("GetConfirmedBlockTxs", tx.BlockSlot).Return([]*core.Tx{tx}, nil)
+		dbMock.On("GetConfirmedBlockTxs", uint64(21)).Return([]*core.Tx(nil), nil)
+
+		var (
+			response    core.Tx
+			errResponse errorResponse
+		)
+
+		get(t, server, "/txs/0x"+tx.Hash.String(), http.StatusOK, &response)
+		require.Equal(t, tx, &response)
+
+		get(t, server, "/txs/"+core.Hash{6}.String(), http.StatusNotFound, &errResponse)
+		get(t, server, "/txs/0102", http.StatusBadRequest, &errResponse)
+
+		var txsResponse PageResponse[*core.Tx]
+
+		get(t, server, "/blocks/20/txs", http.StatusOK, &txsResponse)
+		require.Equal(t, PageResponse[*core.Tx]{Items: []*core.Tx{tx}, Count: 1}, txsResponse)
+
+		txsResponse = PageResponse[*core.Tx]{}
+
+		get(t, server, "/blocks/21/txs", http.StatusOK, &txsResponse)
+		require.Equal(t, PageResponse[*core.Tx]{Items: []*core.Tx{}, Count: 0}, txsResponse)
+
+		get(t, server, "/blocks/slot/txs", http.StatusBadRequest, &errResponse)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		t.Parallel()
+
+		dbMock := &core.DatabaseMock{}
+		server := NewServer(&ServerConfig{}, dbMock, hclog.NewNullLogger())
+
+		dbMock.On("GetConfirmedBlocksFrom", uint64(0), 3).Return(blocks, nil)
+		dbMock.On("GetConfirmedBlocksFrom", uint64(15), 101).Return(blocks[1:], nil)
+		dbMock.On("GetLatestConfirmedBlocks", 2).Return([]*core.CardanoBlock{blocks[2], blocks[1]}, nil)
+
+		var response PageResponse[*core.CardanoBlock]
+
+		get(t, server, "/blocks?count=2", http.StatusOK, &response)
+		require.Equal(t, PageResponse[*core.CardanoBlock]{Items: blocks[:2], Count: 2, Next: "30"}, response)
+
+		response = PageResponse[*core.CardanoBlock]{}
+
+		get(t, server, "/blocks?from=15&to=29", http.StatusOK, &response)
+		require.Equal(t, PageResponse[*core.CardanoBlock]{Items: blocks[1:2], Count: 1}, response)
+
+		response = PageResponse[*core.CardanoBlock]{}
+
+		get(t, server, "/blocks/latest?count=2", http.StatusOK, &response)
+		require.Equal(t, PageResponse[*core.CardanoBlock]{
+			Items: []*core.CardanoBlock{blocks[2], blocks[1]}, Count: 2,
+		}, response)
+
+		var errResponse errorResponse
+
+		get(t, server, "/blocks?from=-1", http.StatusBadRequest, &errResponse)
+		require.Equal(t, "invalid from: -1", errResponse.Error)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		t.Parallel()
+
+		dbMock := &core.DatabaseMock{}
+		server := NewServer(&ServerConfig{}, dbMock, hclog.NewNullLogger())
+		point := &core.BlockPoint{BlockSlot: 35, BlockHash: core.Hash{35}}
+
+		dbMock.On("GetLatestBlockPoint").Return((*core.BlockPoint)(nil), error(nil)).Once()
+		dbMock.On("GetLatestBlockPoint").Return(point, error(nil)).Times(3)
+		dbMock.On("GetLatestBlockPoint").Return((*core.BlockPoint)(nil), errors.New("db error")).Once()
+		dbMock.On("GetLatestConfirmedBlocks", 1).Return([]*core.CardanoBlock{blocks[2]}, nil).Twice()
+
+		var (
+			errResponse   errorResponse
+			pointResponse core.BlockPoint
+			status        SyncStatus
+		)
+
+		get(t, server, "/latest-point", http.StatusNotFound, &errResponse)
+
+		get(t, server, "/latest-point", http.StatusOK, &pointResponse)
+		require.Equal(t, point, &pointResponse)
+
+		get(t, server, "/status", http.StatusOK, &status)
+		require.Equal(t, SyncStatus{LatestBlockPoint: point, LatestConfirmedBlock: blocks[2]}, status)
+
+		tracker := core.NewSyncProgressTracker(10)
+		tracker.SetTip(core.BlockPoint{BlockSlot: 40})
+		tracker.SetLatestPoint(*point)
+		server.SetSyncProgressProvider(tracker)
+
+		get(t, server, "/status", http.StatusOK, &status)
+		require.Equal(t, &core.SyncProgress{
+			Tip: core.BlockPoint{BlockSlot: 40}, LatestPoint: *point, SlotLag: 5, IsSynced: true,
+		}, status.Progress)
+
+		get(t, server, "/latest-point", http.StatusInternalServerError, &errResponse)
+		require.Equal(t, "db error", errResponse.Error)
+
+		dbMock.AssertExpectations(t)
+	})
+}
+
+func TestServer_StartClose(t *testing.T) {
+	t.Parallel()
+
+	dbMock := &core.DatabaseMock{}
+	dbMock.On("GetLatestBlockPoint").Return(&core.BlockPoint{BlockSlot: 1}, error(nil))
+
+	server := NewServer(&ServerConfig{ListenAddress: "127.0.0.1:0"}, dbMock, hclog.NewNullLogger())
+
+	require.NoError(t, server.Start())
+	require.NoError(t, server.Close())
+	require.NoError(t, server.Close())
+}
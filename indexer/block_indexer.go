@@ -3,9 +3,16 @@ package indexer
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	infracommon "github.com/Ethernal-Tech/cardano-infrastructure/common"
+	"github.com/Ethernal-Tech/cardano-infrastructure/wallet"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/hashicorp/go-hclog"
 )
 
@@ -14,17 +21,47 @@ const (
 	AddressCheckInputs  = 1 << (iota - 1) // 1 << 0 = 0x00...0001 = 1
 	AddressCheckOutputs                   // 1 << 1 = 0x00...0010 = 2
 	AddressCheckAll     = AddressCheckInputs | AddressCheckOutputs
+
+	catchUpSlotDistanceDefault = 1000
+	auxiliaryDataTag           = 259
 )
 
 type BlockIndexerConfig struct {
 	StartingBlockPoint *BlockPoint `json:"startingBlockPoint"`
 	// how many children blocks is needed for some block to be considered final
-	ConfirmationBlockCount  uint     `json:"confirmationBlockCount"`
-	AddressesOfInterest     []string `json:"addressesOfInterest"`
-	KeepAllTxOutputsInDB    bool     `json:"keepAllTxOutputsInDb"`
-	AddressCheck            int      `json:"addressCheck"`
-	SoftDeleteUtxo          bool     `json:"softDeleteUtxo"`
-	KeepAllTxsHashesInBlock bool     `json:"keepAllTxsHashesInBlock"`
+	ConfirmationBlockCount uint `json:"confirmationBlockCount"`
+	// AddressesOfInterest are used only until the set is changed through the block indexer
+	// (AddAddressesOfInterest, RemoveAddressesOfInterest, ...). Persisted set overrides them on every restart
+	AddressesOfInterest []string `json:"addressesOfInterest"`
+	// PaymentCredentialsOfInterest are hex encoded payment key hashes or script hashes.
+	// Every address with one of them as the payment part is of interest (regardless of the stake part)
+	PaymentCredentialsOfInterest []string `json:"paymentCredentialsOfInterest,omitempty"`
+	// StakeCredentialsOfInterest are hex encoded stake key hashes or script hashes.
+	// Every address with one of them as the stake part is of interest
+	StakeCredentialsOfInterest []string `json:"stakeCredentialsOfInterest,omitempty"`
+	// PolicyIDsOfInterest - every tx which mints, burns or carries (in inputs or outputs) a token
+	// with one of these hex encoded policy ids is of interest regardless of addresses
+	PolicyIDsOfInterest []string `json:"policyIdsOfInterest,omitempty"`
+	// AssetsOfInterest are full token names (policyID.hexEncodedName) of interest. Same rules as for policy ids apply
+	AssetsOfInterest []string `json:"assetsOfInterest,omitempty"`
+	// MetadataLabelsOfInterest - every tx with one of these top level labels in metadata is of interest
+	MetadataLabelsOfInterest []uint64 `json:"metadataLabelsOfInterest,omitempty"`
+	KeepAllTxOutputsInDB     bool     `json:"keepAllTxOutputsInDb"`
+	AddressCheck             int      `json:"addressCheck"`
+	SoftDeleteUtxo           bool     `json:"softDeleteUtxo"`
+	KeepAllTxsHashesInBlock  bool     `json:"keepAllTxsHashesInBlock"`
+	// UndoJournalDepth is the number of the latest confirmed blocks which can be reverted by the deep rollback.
+	// Rollback beyond the latest confirmed block is a fatal error if it is zero
+	UndoJournalDepth uint `json:"undoJournalDepth"`
+	// Retention configures pruning of the old data in the background (see Pruner). Nothing is pruned if it is nil.
+	// Database must implement Database interface, otherwise retention is ignored
+	Retention *RetentionConfig `json:"retention,omitempty"`
+	// ConfirmedBlocksBatchSize is the max number of confirmed blocks saved with a single database transaction
+	// while the indexer is catching up with the chain tip. Every block is saved separately if it is <= 1
+	ConfirmedBlocksBatchSize uint `json:"confirmedBlocksBatchSize"`
+	// CatchUpSlotDistance - indexer is catching up if the chain tip is further than this number of slots
+	// from the latest received block. The tip is known only if the block syncer reports it (see ChainTipHandler)
+	CatchUpSlotDistance uint64 `json:"catchUpSlotDistance"`
 }
 
 type BlockIndexer struct {
@@ -34,15 +71,56 @@ type BlockIndexer struct {
 	latestBlockPoint      *BlockPoint
 	unconfirmedBlocks     infracommon.CircularQueue[BlockHeader]
 	confirmedBlockHandler NewConfirmedBlockHandler
-	addressesOfInterest   map[string]bool
+	// optional handler for the confirmed blocks reverted by the deep rollback
+	confirmedBlocksRevertedHandler ConfirmedBlocksRevertedHandler
+	// optional handler for tentative blocks and their rollbacks
+	unconfirmedBlockHandler UnconfirmedBlockHandler
+	// txs of the unconfirmed blocks fetched for the unconfirmed block handler, reused when the block is confirmed
+	unconfirmedBlocksTxs map[Hash][]*Tx
+	// optional handler which indexes history of the newly added addresses
+	addressesBackfillHandler AddressesBackfillHandler
+	addressesOfInterest      map[string]bool
+	// hex encoded payment and stake credentials of interest
+	paymentCredentialsOfInterest map[string]bool
+	stakeCredentialsOfInterest   map[string]bool
+	// hex encoded policy ids and full token names of interest
+	policyIDsOfInterest      map[string]bool
+	assetsOfInterest         map[string]bool
+	metadataLabelsOfInterest map[uint64]bool
 
 	db BlockIndexerDB
+	// pruner deletes old data if the retention is configured. It is started with the first reset
+	pruner *Pruner
+
+	// confirmed blocks processed during the catch up but not yet saved in the database
+	confirmedBatch []*confirmedBlockData
+	// outputs added and removed by the confirmed batch (not yet visible in the database)
+	confirmedBatchOutputs map[TxInput]TxOutput
+	// confirmedBatchFailed is true if the batch is processed but saving failed, so it must be saved again
+	confirmedBatchFailed bool
+	chainTipSlot         atomic.Uint64
+	throughput           *throughputMeter
+	metrics              Metrics
 
 	mutex  sync.Mutex
 	logger hclog.Logger
 }
 
-var _ BlockSyncerHandler = (*BlockIndexer)(nil)
+type confirmedBlockData struct {
+	block             *CardanoBlock
+	txs               []*Tx
+	allTxsCount       int
+	latestBlockPoint  *BlockPoint
+	undoRecord        *UndoRecord
+	txOutputsToSave   []*TxInputOutput
+	txOutputsToRemove []TxInput
+}
+
+var (
+	_ BlockSyncerHandler = (*BlockIndexer)(nil)
+	_ ChainTipHandler    = (*BlockIndexer)(nil)
+	_ Closable           = (*BlockIndexer)(nil)
+)
 
 func NewBlockIndexer(
 	config *BlockIndexerConfig, confirmedBlockHandler NewConfirmedBlockHandler, db BlockIndexerDB, logger hclog.Logger,
@@ -56,17 +134,80 @@ func NewBlockIndexer(
 		addressesOfInterest[x] = true
 	}
 
+	paymentCredentialsOfInterest := make(map[string]bool, len(config.PaymentCredentialsOfInterest))
+	for _, x := range config.PaymentCredentialsOfInterest {
+		paymentCredentialsOfInterest[strings.ToLower(x)] = true
+	}
+
+	stakeCredentialsOfInterest := make(map[string]bool, len(config.StakeCredentialsOfInterest))
+	for _, x := range config.StakeCredentialsOfInterest {
+		stakeCredentialsOfInterest[strings.ToLower(x)] = true
+	}
+
+	policyIDsOfInterest := make(map[string]bool, len(config.PolicyIDsOfInterest))
+	for _, x := range config.PolicyIDsOfInterest {
+		policyIDsOfInterest[strings.ToLower(x)] = true
+	}
+
+	assetsOfInterest := make(map[string]bool, len(config.AssetsOfInterest))
+	for _, x := range config.AssetsOfInterest {
+		assetsOfInterest[strings.ToLower(x)] = true
+	}
+
+	metadataLabelsOfInterest := make(map[uint64]bool, len(config.MetadataLabelsOfInterest))
+	for _, x := range config.MetadataLabelsOfInterest {
+		metadataLabelsOfInterest[x] = true
+	}
+
+	var pruner *Pruner
+
+	if config.Retention != nil {
+		if database, ok := db.(Database); ok {
+			pruner = NewPruner(config.Retention, database, logger.Named("pruner"))
+		} else {
+			logger.Warn("Retention is ignored because the database does not support pruning")
+		}
+	}
+
 	return &BlockIndexer{
-		config:                config,
-		latestBlockPoint:      nil,
-		confirmedBlockHandler: confirmedBlockHandler,
-		unconfirmedBlocks:     infracommon.NewCircularQueue[BlockHeader](int(config.ConfirmationBlockCount)), //nolint
-		db:                    db,
-		addressesOfInterest:   addressesOfInterest,
-		logger:                logger,
+		config:                       config,
+		latestBlockPoint:             nil,
+		confirmedBlockHandler:        confirmedBlockHandler,
+		unconfirmedBlocks:            infracommon.NewCircularQueue[BlockHeader](int(config.ConfirmationBlockCount)), //nolint
+		db:                           db,
+		pruner:                       pruner,
+		addressesOfInterest:          addressesOfInterest,
+		paymentCredentialsOfInterest: paymentCredentialsOfInterest,
+		stakeCredentialsOfInterest:   stakeCredentialsOfInterest,
+		policyIDsOfInterest:          policyIDsOfInterest,
+		assetsOfInterest:             assetsOfInterest,
+		metadataLabelsOfInterest:     metadataLabelsOfInterest,
+		throughput:                   newThroughputMeter(throughputIntervalDefault),
+		metrics:                      NoopMetrics{},
+		logger:                       logger,
 	}
 }
 
+// Close stops the background pruner if it is started
+func (bi *BlockIndexer) Close() error {
+	if bi.pruner == nil {
+		return nil
+	}
+
+	return bi.pruner.Close()
+}
+
+// SetChainTip sets the chain tip reported by the block syncer
+func (bi *BlockIndexer) SetChainTip(tip BlockPoint) {
+	bi.chainTipSlot.Store(tip.BlockSlot)
+}
+
+// GetThroughputStats returns the confirmed blocks processing counters
+func (bi *BlockIndexer) GetThroughputStats() ThroughputStats {
+	return bi.throughput.get()
+}
+
+// AddNewAddressesOfInterest adds addresses of interest only in memory (see AddAddressesOfInterest)
 func (bi *BlockIndexer) AddNewAddressesOfInterest(addresses ...string) {
 	bi.mutex.Lock()
 	for _, address := range addresses {
@@ -75,10 +216,138 @@ func (bi *BlockIndexer) AddNewAddressesOfInterest(addresses ...string) {
 	bi.mutex.Unlock()
 }
 
+// AddAddressesOfInterest adds addresses of interest and persists the whole set in the database
+func (bi *BlockIndexer) AddAddressesOfInterest(addresses ...string) error {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	return bi.updateAddressesOfInterest(func(current map[string]bool) {
+		for _, address := range addresses {
+			current[address] = true
+		}
+	})
+}
+
+// AddAddressesOfInterestWithBackfill adds and persists addresses of interest
+// and then calls the backfill handler for the newly added ones, so their history starting from fromSlot is indexed.
+// If the backfill fails AddressesBackfillError with the addresses which are not backfilled is returned
+func (bi *BlockIndexer) AddAddressesOfInterestWithBackfill(fromSlot uint64, addresses ...string) error {
+	bi.mutex.Lock()
+
+	backfillHandler := bi.addressesBackfillHandler
+	if backfillHandler == nil {
+		bi.mutex.Unlock()
+
+		return errors.New("addresses backfill handler is not set")
+	}
+
+	var newAddresses []string
+
+	err := bi.updateAddressesOfInterest(func(current map[string]bool) {
+		for _, address := range addresses {
+			if !current[address] {
+				current[address] = true
+
+				newAddresses = append(newAddresses, address)
+			}
+		}
+	})
+
+	bi.mutex.Unlock()
+
+	if err != nil || len(newAddresses) == 0 {
+		return err
+	}
+
+	// backfill is executed without the lock so the live sync is not blocked
+	if err := backfillHandler(fromSlot, newAddresses); err != nil {
+		return &AddressesBackfillError{FromSlot: fromSlot, Addresses: newAddresses, Err: err}
+	}
+
+	return nil
+}
+
+// RemoveAddressesOfInterest removes addresses of interest and persists the whole set in the database.
+// Already indexed outputs of removed addresses are kept in the database.
+// ErrNoAddressesOfInterest is returned if all the address filters would be removed
+func (bi *BlockIndexer) RemoveAddressesOfInterest(addresses ...string) error {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	return bi.updateAddressesOfInterest(func(current map[string]bool) {
+		for _, address := range addresses {
+			delete(current, address)
+		}
+	})
+}
+
+// ReplaceAddressesOfInterest atomically replaces the set of addresses of interest and persists it in the database.
+// ErrNoAddressesOfInterest is returned if the new set is empty and there are no payment or stake credentials
+// of interest (policy, asset and metadata filters do not prevent switching to all the addresses)
+func (bi *BlockIndexer) ReplaceAddressesOfInterest(addresses []string) error {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	return bi.updateAddressesOfInterest(func(current map[string]bool) {
+		clear(current)
+
+		for _, address := range addresses {
+			current[address] = true
+		}
+	})
+}
+
+// GetAddressesOfInterest returns current addresses of interest
+func (bi *BlockIndexer) GetAddressesOfInterest() []string {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	return slices.Sorted(maps.Keys(bi.addressesOfInterest))
+}
+
+// SetAddressesBackfillHandler sets handler which indexes history of the addresses added with backfill
+func (bi *BlockIndexer) SetAddressesBackfillHandler(handler AddressesBackfillHandler) {
+	bi.mutex.Lock()
+	bi.addressesBackfillHandler = handler
+	bi.mutex.Unlock()
+}
+
+// SetUnconfirmedBlockHandler sets handler which is notified about every new unconfirmed block
+// and about unconfirmed blocks discarded by the roll backward.
+// Inputs of the unconfirmed block txs are resolved only from the outputs already saved in the database.
+// New blocks are not notified while the indexer is catching up with the chain tip (see CatchUpSlotDistance).
+// Handler errors are logged and do not stop the blocks processing
+func (bi *BlockIndexer) SetUnconfirmedBlockHandler(handler UnconfirmedBlockHandler) {
+	bi.mutex.Lock()
+	bi.unconfirmedBlockHandler = handler
+	bi.mutex.Unlock()
+}
+
+// SetConfirmedBlocksRevertedHandler sets handler which is notified before the confirmed blocks are reverted
+// by the deep rollback (see UndoJournalDepth). If the handler fails nothing is reverted
+// and the roll backward fails with the fatal error
+func (bi *BlockIndexer) SetConfirmedBlocksRevertedHandler(handler ConfirmedBlocksRevertedHandler) {
+	bi.mutex.Lock()
+	bi.confirmedBlocksRevertedHandler = handler
+	bi.mutex.Unlock()
+}
+
+// SetMetrics sets the receiver of the rolled blocks, confirmed blocks and database commit measurements
+func (bi *BlockIndexer) SetMetrics(metrics Metrics) {
+	bi.mutex.Lock()
+	bi.metrics = metrics
+	bi.mutex.Unlock()
+}
+
 func (bi *BlockIndexer) RollBackward(point BlockPoint) error {
 	bi.mutex.Lock()
 	defer bi.mutex.Unlock()
 
+	// confirmed blocks from the batch must be in the database before they can be reverted
+	if err := bi.saveConfirmedBatch(); err != nil {
+		return err
+	}
+
 	// linear is ok, there will be smaller number of unconfirmed blocks in memory
 	indx := bi.unconfirmedBlocks.Find(func(header BlockHeader) bool {
 		return header.Slot == point.BlockSlot && header.Hash == point.BlockHash
@@ -87,21 +356,51 @@ func (bi *BlockIndexer) RollBackward(point BlockPoint) error {
 		bi.logger.Info("Roll backward to unconfirmed block", "indx", indx,
 			"slot", point.BlockSlot, "hash", point.BlockHash)
 
+		rolledBackBlocks := bi.unconfirmedBlocks.ToList()[indx+1:]
+
 		bi.unconfirmedBlocks.SetCount(indx + 1)
+		bi.metrics.AddCounter(MetricBlocksRolledBackward, float64(len(rolledBackBlocks)))
+		bi.notifyRollback(point, rolledBackBlocks, nil)
 
 		return nil
 	}
 
 	if bi.latestBlockPoint.BlockSlot == point.BlockSlot && bi.latestBlockPoint.BlockHash == point.BlockHash {
+		rolledBackBlocks := bi.unconfirmedBlocks.ToList()
+
 		bi.unconfirmedBlocks.SetCount(0)
 
 		bi.logger.Info("Roll backward to confirmed block", "slot", point.BlockSlot, "hash", point.BlockHash)
 
 		// everything is ok -> we are reverting to the latest confirmed block
+		bi.metrics.AddCounter(MetricBlocksRolledBackward, float64(len(rolledBackBlocks)))
+		bi.notifyRollback(point, rolledBackBlocks, nil)
+
 		return nil
 	}
 
-	// we have confirmed a block that should NOT have been confirmed!
+	if bi.config.UndoJournalDepth > 0 {
+		revertedBlocks, err := bi.revertConfirmedBlocks(point)
+		if err != nil {
+			return errors.Join(ErrBlockIndexerFatal, fmt.Errorf("revert confirmed blocks failed: %w", err))
+		}
+
+		if len(revertedBlocks) > 0 {
+			rolledBackBlocks := bi.unconfirmedBlocks.ToList()
+
+			bi.unconfirmedBlocks.SetCount(0)
+
+			bi.logger.Info("Roll backward to reverted confirmed block",
+				"slot", point.BlockSlot, "hash", point.BlockHash, "reverted", len(revertedBlocks))
+
+			bi.metrics.AddCounter(MetricBlocksRolledBackward, float64(len(rolledBackBlocks)+len(revertedBlocks)))
+			bi.notifyRollback(point, rolledBackBlocks, revertedBlocks)
+
+			return nil
+		}
+	}
+
+	// we have confirmed a block that should NOT have been confirmed and it can not be reverted with undo journal!
 	// recovering from this error is difficult and requires manual database changes
 	return errors.Join(ErrBlockIndexerFatal,
 		fmt.Errorf("roll backward block not found. new = (%d, %s) vs latest = (%d, %s)",
@@ -112,6 +411,33 @@ func (bi *BlockIndexer) RollForward(blockHeader BlockHeader, txsRetriever BlockT
 	bi.mutex.Lock()
 	defer bi.mutex.Unlock()
 
+	// new block is resolved before the indexer state is changed, so the item can be retried if fetching fails
+	event, err := bi.prepareUnconfirmedBlockEvent(blockHeader, txsRetriever)
+	if err != nil {
+		return &processConfirmedBlockError{err: err}
+	}
+
+	err = bi.rollForward(blockHeader, txsRetriever)
+
+	// the same block will be rolled forward again after processConfirmedBlockError, so it is notified then
+	var processConfirmedBlockErr *processConfirmedBlockError
+	if !errors.As(err, &processConfirmedBlockErr) {
+		bi.notifyUnconfirmedBlock(event)
+	}
+
+	if err == nil {
+		bi.metrics.AddCounter(MetricBlocksRolledForward, 1)
+	}
+
+	return err
+}
+
+func (bi *BlockIndexer) rollForward(blockHeader BlockHeader, txsRetriever BlockTxsRetriever) error {
+	// the block has been already processed but saving of the confirmed batch failed -> try to save it again
+	if bi.confirmedBatchFailed {
+		return bi.saveConfirmedBatch()
+	}
+
 	if !bi.unconfirmedBlocks.IsFull() {
 		// If there are not enough children blocks to promote the first one to the confirmed state,
 		// a new block header is added, and the function returns
@@ -122,11 +448,15 @@ func (bi *BlockIndexer) RollForward(blockHeader BlockHeader, txsRetriever BlockT
 
 	firstBlockHeader := bi.unconfirmedBlocks.Peek()
 
-	txs, err := txsRetriever.GetBlockTransactions(firstBlockHeader)
+	txs, err := bi.getBlockTransactions(firstBlockHeader, txsRetriever)
 	if err != nil {
 		return &processConfirmedBlockError{err: err}
 	}
 
+	if bi.config.ConfirmedBlocksBatchSize > 1 {
+		return bi.addToConfirmedBatch(firstBlockHeader, txs, blockHeader)
+	}
+
 	confirmedBlock, confirmedTxs, latestBlockPoint, err := bi.processConfirmedBlock(firstBlockHeader, txs)
 	if err != nil {
 		return &processConfirmedBlockError{err: err}
@@ -137,10 +467,22 @@ func (bi *BlockIndexer) RollForward(blockHeader BlockHeader, txsRetriever BlockT
 
 	bi.unconfirmedBlocks.Pop()
 	_ = bi.unconfirmedBlocks.Push(blockHeader)
+	delete(bi.unconfirmedBlocksTxs, firstBlockHeader.Hash)
+
+	bi.recordThroughput(1, len(txs), len(confirmedTxs))
 
 	return bi.confirmedBlockHandler(confirmedBlock, confirmedTxs)
 }
 
+// getBlockTransactions returns txs already fetched for the unconfirmed block handler or fetches them
+func (bi *BlockIndexer) getBlockTransactions(blockHeader BlockHeader, txsRetriever BlockTxsRetriever) ([]*Tx, error) {
+	if txs, exists := bi.unconfirmedBlocksTxs[blockHeader.Hash]; exists {
+		return txs, nil
+	}
+
+	return txsRetriever.GetBlockTransactions(blockHeader)
+}
+
 func (bi *BlockIndexer) Reset() (BlockPoint, error) {
 	bi.mutex.Lock()
 	defer bi.mutex.Unlock()
@@ -161,74 +503,444 @@ func (bi *BlockIndexer) Reset() (BlockPoint, error) {
 		latestPoint = &BlockPoint{}
 	}
 
+	// addresses of interest persisted in the database take precedence over the ones from the configuration
+	addresses, err := bi.db.GetAddressesOfInterest()
+	if err != nil {
+		return BlockPoint{}, err
+	}
+
+	if addresses != nil {
+		configured := slices.Sorted(slices.Values(bi.config.AddressesOfInterest))
+		if !slices.Equal(slices.Sorted(slices.Values(addresses)), configured) {
+			bi.logger.Info("Persisted addresses of interest override the configured ones",
+				"persisted", len(addresses), "configured", len(bi.config.AddressesOfInterest))
+		}
+
+		bi.addressesOfInterest = make(map[string]bool, len(addresses))
+		for _, x := range addresses {
+			bi.addressesOfInterest[x] = true
+		}
+	}
+
 	bi.latestBlockPoint = latestPoint
 	bi.unconfirmedBlocks.SetCount(0) // clear all unconfirmed from the memory
+	bi.unconfirmedBlocksTxs = nil
+	// confirmed blocks which are not saved will be received again
+	bi.clearConfirmedBatch()
+
+	if bi.pruner != nil {
+		bi.pruner.Start()
+	}
 
 	return *latestPoint, nil
 }
 
+// updateAddressesOfInterest applies update on the copy of the current addresses of interest,
+// persists the result and switches to it only if it is successfully written to the database
+func (bi *BlockIndexer) updateAddressesOfInterest(update func(map[string]bool)) error {
+	addressesOfInterest := maps.Clone(bi.addressesOfInterest)
+
+	update(addressesOfInterest)
+
+	// without address filters every address is of interest (even if the other filters are set),
+	// so the update must not silently switch from the watched addresses to all the addresses
+	hasCredentialFilters := len(bi.paymentCredentialsOfInterest) > 0 || len(bi.stakeCredentialsOfInterest) > 0
+	if bi.hasAddressFilters() && len(addressesOfInterest) == 0 && !hasCredentialFilters {
+		return ErrNoAddressesOfInterest
+	}
+
+	err := bi.db.OpenTx().SetAddressesOfInterest(slices.Sorted(maps.Keys(addressesOfInterest))).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to persist addresses of interest: %w", err)
+	}
+
+	bi.addressesOfInterest = addressesOfInterest
+
+	return nil
+}
+
 func (bi *BlockIndexer) processConfirmedBlock(
 	confirmedBlockHeader BlockHeader, allTxs []*Tx,
 ) (*CardanoBlock, []*Tx, *BlockPoint, error) {
-	var (
-		txsHashes         []Hash
-		txOutputsToSave   []*TxInputOutput
-		txOutputsToRemove []TxInput
+	data, err := bi.prepareConfirmedBlock(confirmedBlockHeader, allTxs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	dbTx := bi.db.OpenTx() // open database tx
+
+	bi.writeConfirmedBlock(dbTx, data)
+
+	// execute all previously queued updates in a single atomic db operation
+	if err := bi.executeConfirmedBlocksTx(dbTx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return data.block, data.txs, data.latestBlockPoint, nil
+}
+
+// addToConfirmedBatch processes the confirmed block and adds it to the batch.
+// Batch is saved when it is full or when the indexer is not catching up with the chain tip anymore
+func (bi *BlockIndexer) addToConfirmedBatch(
+	confirmedBlockHeader BlockHeader, allTxs []*Tx, latestBlockHeader BlockHeader,
+) error {
+	data, err := bi.prepareConfirmedBlock(confirmedBlockHeader, allTxs)
+	if err != nil {
+		return &processConfirmedBlockError{err: err}
+	}
+
+	if bi.confirmedBatchOutputs == nil {
+		bi.confirmedBatchOutputs = map[TxInput]TxOutput{}
+	}
+
+	for _, txOutput := range data.txOutputsToSave {
+		bi.confirmedBatchOutputs[txOutput.Input] = txOutput.Output
+	}
+
+	// spent output can not be used again on the valid chain so its data is not needed anymore
+	for _, txInput := range data.txOutputsToRemove {
+		bi.confirmedBatchOutputs[txInput] = TxOutput{}
+	}
 
-		dbTx = bi.db.OpenTx() // open database tx
+	bi.confirmedBatch = append(bi.confirmedBatch, data)
+	// update latest block point in memory if we have confirmed block
+	bi.latestBlockPoint = data.latestBlockPoint
+
+	bi.unconfirmedBlocks.Pop()
+	_ = bi.unconfirmedBlocks.Push(latestBlockHeader)
+	delete(bi.unconfirmedBlocksTxs, confirmedBlockHeader.Hash)
+
+	if bi.isCatchingUp(latestBlockHeader.Slot) && uint(len(bi.confirmedBatch)) < bi.config.ConfirmedBlocksBatchSize {
+		return nil
+	}
+
+	return bi.saveConfirmedBatch()
+}
+
+// saveConfirmedBatch saves all the confirmed blocks from the batch in a single database transaction
+// and calls confirmed block handler for each of them
+func (bi *BlockIndexer) saveConfirmedBatch() error {
+	if len(bi.confirmedBatch) == 0 {
+		return nil
+	}
+
+	dbTx := bi.db.OpenTx()
+
+	for _, data := range bi.confirmedBatch {
+		bi.writeConfirmedBlock(dbTx, data)
+	}
+
+	if err := bi.executeConfirmedBlocksTx(dbTx); err != nil {
+		bi.confirmedBatchFailed = true
+
+		return &processConfirmedBlockError{err: err}
+	}
+
+	batch := bi.confirmedBatch
+	txsCount, txsOfInterestCount := 0, 0
+
+	for _, data := range batch {
+		txsCount += data.allTxsCount
+		txsOfInterestCount += len(data.txs)
+	}
+
+	bi.clearConfirmedBatch()
+	bi.recordThroughput(len(batch), txsCount, txsOfInterestCount)
+
+	for _, data := range batch {
+		if err := bi.confirmedBlockHandler(data.block, data.txs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bi *BlockIndexer) clearConfirmedBatch() {
+	bi.confirmedBatch = nil
+	bi.confirmedBatchOutputs = nil
+	bi.confirmedBatchFailed = false
+}
+
+// executeConfirmedBlocksTx executes the database transaction which saves confirmed blocks and measures its duration
+func (bi *BlockIndexer) executeConfirmedBlocksTx(dbTx DBTransactionWriter) error {
+	startTime := time.Now()
+
+	if err := dbTx.Execute(); err != nil {
+		return err
+	}
+
+	bi.metrics.ObserveDuration(MetricDBCommitDuration, time.Since(startTime))
+
+	return nil
+}
+
+func (bi *BlockIndexer) recordThroughput(blocks int, txs int, txsOfInterest int) {
+	bi.metrics.AddCounter(MetricConfirmedBlocks, float64(blocks))
+	bi.metrics.AddCounter(MetricTxsOfInterest, float64(txsOfInterest))
+
+	if !bi.throughput.add(blocks, txs) {
+		return
+	}
+
+	stats := bi.throughput.get()
+
+	bi.logger.Info("Confirmed blocks throughput",
+		"blocks/s", fmt.Sprintf("%.2f", stats.BlocksPerSecond), "txs/s", fmt.Sprintf("%.2f", stats.TxsPerSecond),
+		"blocks", stats.ConfirmedBlocks, "commits", stats.Commits, "latest_slot", bi.latestBlockPoint.BlockSlot,
+		"tip_slot", bi.chainTipSlot.Load())
+}
+
+func (bi *BlockIndexer) prepareConfirmedBlock(
+	confirmedBlockHeader BlockHeader, allTxs []*Tx,
+) (*confirmedBlockData, error) {
+	var (
+		txsHashes []Hash
+		data      = &confirmedBlockData{allTxsCount: len(allTxs)}
 	)
 	// populate each input with full output data (address, amount, etc.) based on its (hash, index)
 	if err := bi.populateOutputsForEachInput(allTxs); err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
 
 	// get all transactions of interest from block
-	relevantTxs := bi.filterTxsOfInterest(allTxs)
+	data.txs = bi.filterTxsOfInterest(allTxs)
 
 	if bi.config.KeepAllTxOutputsInDB {
-		txOutputsToSave = getTxOutputs(allTxs, nil)
-		txOutputsToRemove = getTxInputs(allTxs, nil)
+		data.txOutputsToSave = getTxOutputs(allTxs, nil)
+		data.txOutputsToRemove = getTxInputs(allTxs, nil)
 	} else {
-		txOutputsToSave = getTxOutputs(relevantTxs, bi.addressesOfInterest)
-		txOutputsToRemove = getTxInputs(relevantTxs, bi.addressesOfInterest)
+		data.txOutputsToSave = getTxOutputs(data.txs, bi.isAddressOfInterest)
+		data.txOutputsToRemove = getTxInputs(data.txs, bi.isAddressOfInterest)
 	}
 
-	// add all relevant transactions from the confirmed block to the db
-	dbTx.AddConfirmedTxs(relevantTxs)
-
 	if bi.config.KeepAllTxsHashesInBlock {
 		txsHashes = getTxHashes(allTxs)
 	} else {
-		txsHashes = getTxHashes(relevantTxs)
+		txsHashes = getTxHashes(data.txs)
 	}
 
-	confirmedBlock := confirmedBlockHeader.ToCardanoBlock(txsHashes)
-	latestBlockPoint := &BlockPoint{
+	data.block = confirmedBlockHeader.ToCardanoBlock(txsHashes)
+	data.latestBlockPoint = &BlockPoint{
 		BlockSlot: confirmedBlockHeader.Slot,
 		BlockHash: confirmedBlockHeader.Hash,
 	}
+
+	if bi.config.UndoJournalDepth > 0 {
+		data.undoRecord = bi.createUndoRecord(
+			data.latestBlockPoint, allTxs, data.txs, data.txOutputsToSave, data.txOutputsToRemove)
+	}
+
+	return data, nil
+}
+
+func (bi *BlockIndexer) writeConfirmedBlock(dbTx DBTransactionWriter, data *confirmedBlockData) {
+	// add all relevant transactions from the confirmed block to the db
+	dbTx.AddConfirmedTxs(data.txs)
 	// save confirmed block (without tx details) in db
-	dbTx.AddConfirmedBlock(confirmedBlock)
+	dbTx.AddConfirmedBlock(data.block)
+
+	if data.undoRecord != nil {
+		dbTx.AddUndoRecord(data.undoRecord, bi.config.UndoJournalDepth)
+	}
 	// update latest block point in db tx
-	dbTx.SetLatestBlockPoint(latestBlockPoint)
+	dbTx.SetLatestBlockPoint(data.latestBlockPoint)
 	// add all needed outputs, remove used ones in db tx
-	dbTx.AddTxOutputs(txOutputsToSave).RemoveTxOutputs(txOutputsToRemove, bi.config.SoftDeleteUtxo)
+	dbTx.AddTxOutputs(data.txOutputsToSave).RemoveTxOutputs(data.txOutputsToRemove, bi.config.SoftDeleteUtxo)
+}
+
+// isCatchingUp returns true if the chain tip is far ahead of the slot
+func (bi *BlockIndexer) isCatchingUp(slot uint64) bool {
+	catchUpSlotDistance := bi.config.CatchUpSlotDistance
+	if catchUpSlotDistance == 0 {
+		catchUpSlotDistance = catchUpSlotDistanceDefault
+	}
+
+	return bi.chainTipSlot.Load() > slot+catchUpSlotDistance
+}
+
+// prepareUnconfirmedBlockEvent fetches txs of the new block and creates the event for the unconfirmed block handler.
+// Fetched txs are kept so the block is not fetched again when it is confirmed.
+// Nothing is fetched (and the event is nil) while the indexer is catching up with the chain tip,
+// because the block is confirmed soon anyway and the bodies should be fetched in ranges
+func (bi *BlockIndexer) prepareUnconfirmedBlockEvent(
+	blockHeader BlockHeader, txsRetriever BlockTxsRetriever,
+) (*UnconfirmedBlockEvent, error) {
+	if bi.unconfirmedBlockHandler == nil || bi.isCatchingUp(blockHeader.Slot) {
+		return nil, nil
+	}
+
+	txs, err := bi.getBlockTransactions(blockHeader, txsRetriever)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bi.populateOutputsForEachInput(txs); err != nil {
+		return nil, err
+	}
+
+	if bi.unconfirmedBlocksTxs == nil {
+		bi.unconfirmedBlocksTxs = map[Hash][]*Tx{}
+	}
+
+	bi.unconfirmedBlocksTxs[blockHeader.Hash] = txs
+
+	relevantTxs := bi.filterTxsOfInterest(txs)
+
+	var txsHashes []Hash
+
+	if bi.config.KeepAllTxsHashesInBlock {
+		txsHashes = getTxHashes(txs)
+	} else {
+		txsHashes = getTxHashes(relevantTxs)
+	}
+
+	return &UnconfirmedBlockEvent{
+		Type:  UnconfirmedBlockEventNew,
+		Block: blockHeader.ToCardanoBlock(txsHashes),
+		Txs:   relevantTxs,
+	}, nil
+}
+
+// notifyUnconfirmedBlock calls the unconfirmed block handler. Handler error is only logged,
+// because the notification is optional and it must not stop the confirmed blocks processing
+func (bi *BlockIndexer) notifyUnconfirmedBlock(event *UnconfirmedBlockEvent) {
+	if event == nil {
+		return
+	}
+
+	if err := bi.unconfirmedBlockHandler(*event); err != nil {
+		bi.logger.Warn("Unconfirmed block handler failed", "slot", event.Block.Slot, "hash", event.Block.Hash, "err", err)
+	}
+}
+
+func (bi *BlockIndexer) notifyRollback(
+	point BlockPoint, rolledBackBlocks []BlockHeader, revertedConfirmedBlocks []BlockPoint,
+) {
+	for _, header := range rolledBackBlocks {
+		delete(bi.unconfirmedBlocksTxs, header.Hash)
+	}
+
+	if bi.unconfirmedBlockHandler == nil || (len(rolledBackBlocks) == 0 && len(revertedConfirmedBlocks) == 0) {
+		return
+	}
+
+	err := bi.unconfirmedBlockHandler(UnconfirmedBlockEvent{
+		Type:                    UnconfirmedBlockEventRollback,
+		RollbackPoint:           point,
+		RolledBackBlocks:        rolledBackBlocks,
+		RevertedConfirmedBlocks: revertedConfirmedBlocks,
+	})
+	if err != nil {
+		bi.logger.Warn("Unconfirmed block handler failed on rollback",
+			"slot", point.BlockSlot, "hash", point.BlockHash, "err", err)
+	}
+}
+
+// revertConfirmedBlocks reverts all confirmed blocks newer than the point using the undo journal.
+// Returns reverted blocks (ordered from the oldest) or nothing if the point can not be reached with the journal
+func (bi *BlockIndexer) revertConfirmedBlocks(point BlockPoint) ([]BlockPoint, error) {
+	records, err := bi.db.GetUndoRecords(point.BlockSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	// records are chained: each one points to the previous one and the oldest one must point to rollback point
+	for i, record := range records {
+		if record.PrevBlockPoint == nil {
+			return nil, nil
+		}
+
+		if i < len(records)-1 && *record.PrevBlockPoint != records[i+1].BlockPoint {
+			return nil, nil
+		}
+	}
+
+	if len(records) == 0 || *records[len(records)-1].PrevBlockPoint != point {
+		return nil, nil
+	}
+
+	revertedBlocks := make([]BlockPoint, len(records))
+
+	for i, record := range records {
+		revertedBlocks[len(records)-1-i] = record.BlockPoint
+	}
+
+	// consumers of the confirmed blocks must be able to undo processed txs before they are removed
+	if bi.confirmedBlocksRevertedHandler != nil {
+		if err := bi.confirmedBlocksRevertedHandler(point, revertedBlocks); err != nil {
+			return nil, fmt.Errorf("confirmed blocks reverted handler failed: %w", err)
+		}
+	}
+
+	dbTx := bi.db.OpenTx()
+
+	for _, record := range records {
+		dbTx.RevertUndoRecord(record)
+	}
 
-	// execute all previously queued updates in a single atomic db operation
 	if err := dbTx.Execute(); err != nil {
-		return nil, nil, nil, err
+		return nil, err
+	}
+
+	bi.latestBlockPoint = &BlockPoint{
+		BlockSlot: point.BlockSlot,
+		BlockHash: point.BlockHash,
 	}
 
-	return confirmedBlock, relevantTxs, latestBlockPoint, nil
+	return revertedBlocks, nil
+}
+
+func (bi *BlockIndexer) createUndoRecord(
+	blockPoint *BlockPoint, allTxs []*Tx, relevantTxs []*Tx,
+	txOutputsToSave []*TxInputOutput, txOutputsToRemove []TxInput,
+) *UndoRecord {
+	record := &UndoRecord{
+		BlockPoint:     *blockPoint,
+		PrevBlockPoint: bi.latestBlockPoint,
+	}
+
+	for _, tx := range relevantTxs {
+		record.TxIndexes = append(record.TxIndexes, tx.Indx)
+	}
+
+	for _, txOutput := range txOutputsToSave {
+		record.AddedTxOutputs = append(record.AddedTxOutputs, txOutput.Input)
+	}
+
+	// inputs are already populated with the outputs from the database
+	existingOutputs := map[TxInput]TxOutput{}
+
+	for _, tx := range allTxs {
+		for _, inp := range tx.Inputs {
+			if inp.Output.Address != "" {
+				existingOutputs[inp.Input] = inp.Output
+			}
+		}
+	}
+
+	for _, inp := range txOutputsToRemove {
+		if output, exists := existingOutputs[inp]; exists {
+			record.RemovedTxOutputs = append(record.RemovedTxOutputs, &TxInputOutput{
+				Input:  inp,
+				Output: output,
+			})
+		}
+	}
+
+	return record
 }
 
 func (bi *BlockIndexer) filterTxsOfInterest(txs []*Tx) (result []*Tx) {
-	if len(bi.addressesOfInterest) == 0 {
+	if !bi.hasFiltersOfInterest() {
 		return txs
 	}
 
+	hasAddressFilters := bi.hasAddressFilters()
+
 	for _, tx := range txs {
-		if bi.isTxInputOfInterest(tx) || bi.isTxOutputOfInterest(tx) {
+		if hasAddressFilters && (bi.isTxInputOfInterest(tx) || bi.isTxOutputOfInterest(tx)) ||
+			bi.isTxTokenOfInterest(tx) || bi.isTxMetadataOfInterest(tx) {
 			result = append(result, tx)
 		}
 	}
@@ -236,13 +948,113 @@ func (bi *BlockIndexer) filterTxsOfInterest(txs []*Tx) (result []*Tx) {
 	return result
 }
 
+// isTxTokenOfInterest returns true if the tx mints, burns or carries a token with policy id or name of interest
+func (bi *BlockIndexer) isTxTokenOfInterest(tx *Tx) bool {
+	if len(bi.policyIDsOfInterest) == 0 && len(bi.assetsOfInterest) == 0 {
+		return false
+	}
+
+	for _, token := range tx.Mint {
+		if bi.policyIDsOfInterest[token.PolicyID] || bi.assetsOfInterest[token.TokenName()] {
+			return true
+		}
+	}
+
+	isTokenOfInterest := func(tokens []TokenAmount) bool {
+		for _, token := range tokens {
+			if bi.policyIDsOfInterest[token.PolicyID] || bi.assetsOfInterest[token.TokenName()] {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, out := range tx.Outputs {
+		if isTokenOfInterest(out.Tokens) {
+			return true
+		}
+	}
+
+	for _, inp := range tx.Inputs {
+		if isTokenOfInterest(inp.Output.Tokens) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTxMetadataOfInterest returns true if the tx metadata contains a top level label of interest
+func (bi *BlockIndexer) isTxMetadataOfInterest(tx *Tx) bool {
+	if len(bi.metadataLabelsOfInterest) == 0 || len(tx.Metadata) == 0 {
+		return false
+	}
+
+	metadata, err := getTxMetadataLabels(tx.Metadata)
+	if err != nil {
+		bi.logger.Debug("Invalid tx metadata", "hash", tx.Hash, "err", err)
+
+		return false
+	}
+
+	for label := range metadata {
+		if bi.metadataLabelsOfInterest[label] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTxMetadataLabels returns top level metadata from auxiliary data which can be
+// shelley plain map, allegra/mary array [metadata, scripts] or alonzo+ tag 259 map with metadata under key 0
+func getTxMetadataLabels(auxData []byte) (map[uint64]cbor.RawMessage, error) {
+	var (
+		metadata map[uint64]cbor.RawMessage
+		rawTag   cbor.RawTag
+		array    []cbor.RawMessage
+	)
+
+	switch {
+	case cbor.Unmarshal(auxData, &rawTag) == nil:
+		if rawTag.Number != auxiliaryDataTag {
+			return nil, fmt.Errorf("invalid auxiliary data tag: %d", rawTag.Number)
+		}
+
+		var content map[uint64]cbor.RawMessage
+
+		if err := cbor.Unmarshal(rawTag.Content, &content); err != nil {
+			return nil, err
+		}
+
+		if content[0] == nil {
+			return nil, nil
+		}
+
+		auxData = content[0]
+	case cbor.Unmarshal(auxData, &array) == nil:
+		if len(array) == 0 {
+			return nil, errors.New("empty auxiliary data array")
+		}
+
+		auxData = array[0]
+	}
+
+	if err := cbor.Unmarshal(auxData, &metadata); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
 func (bi *BlockIndexer) isTxOutputOfInterest(tx *Tx) bool {
 	if bi.config.AddressCheck&AddressCheckOutputs == 0 {
 		return false
 	}
 
 	for _, out := range tx.Outputs {
-		if bi.addressesOfInterest[out.Address] {
+		if bi.isAddressOfInterest(out.Address) {
 			return true
 		}
 	}
@@ -256,7 +1068,7 @@ func (bi *BlockIndexer) isTxInputOfInterest(tx *Tx) bool {
 	}
 
 	for _, inp := range tx.Inputs {
-		if bi.addressesOfInterest[inp.Output.Address] {
+		if bi.isAddressOfInterest(inp.Output.Address) {
 			return true
 		}
 	}
@@ -264,12 +1076,50 @@ func (bi *BlockIndexer) isTxInputOfInterest(tx *Tx) bool {
 	return false
 }
 
+func (bi *BlockIndexer) hasFiltersOfInterest() bool {
+	return bi.hasAddressFilters() || len(bi.policyIDsOfInterest) > 0 ||
+		len(bi.assetsOfInterest) > 0 || len(bi.metadataLabelsOfInterest) > 0
+}
+
+func (bi *BlockIndexer) hasAddressFilters() bool {
+	return len(bi.addressesOfInterest) > 0 ||
+		len(bi.paymentCredentialsOfInterest) > 0 || len(bi.stakeCredentialsOfInterest) > 0
+}
+
+// isAddressOfInterest returns true if the address is of interest or its payment or stake credential is of interest.
+// Every address is of interest if there are no address filters
+func (bi *BlockIndexer) isAddressOfInterest(address string) bool {
+	if !bi.hasAddressFilters() || bi.addressesOfInterest[address] {
+		return true
+	}
+
+	if address == "" || (len(bi.paymentCredentialsOfInterest) == 0 && len(bi.stakeCredentialsOfInterest) == 0) {
+		return false
+	}
+
+	cardanoAddress, err := wallet.NewCardanoAddressFromString(address)
+	if err != nil {
+		return false // byron or invalid addresses can be matched only by the full address
+	}
+
+	addressInfo := cardanoAddress.GetInfo()
+
+	return (addressInfo.Payment != nil && bi.paymentCredentialsOfInterest[addressInfo.Payment.String()]) ||
+		(addressInfo.Stake != nil && bi.stakeCredentialsOfInterest[addressInfo.Stake.String()])
+}
+
 func (bi *BlockIndexer) populateOutputsForEachInput(txs []*Tx) (err error) {
 	for _, tx := range txs {
 		for _, inp := range tx.Inputs {
 			if inp.Output.Address != "" {
 				continue // output is already set
 			}
+
+			if output, exists := bi.confirmedBatchOutputs[inp.Input]; exists {
+				inp.Output = output
+
+				continue
+			}
 			// if there is no output for the input, zero address and amount are set
 			inp.Output, err = bi.db.GetTxOutput(inp.Input)
 			if err != nil {
@@ -16,6 +16,14 @@ type blockIndexerRunnerQueueItem struct {
 	Point        *BlockPoint
 }
 
+func (qi blockIndexerRunnerQueueItem) latestPoint() BlockPoint {
+	if qi.Point != nil {
+		return *qi.Point
+	}
+
+	return BlockPoint{BlockSlot: qi.BlockHeader.Slot, BlockHash: qi.BlockHeader.Hash}
+}
+
 func (qi blockIndexerRunnerQueueItem) String() string {
 	if qi.Point != nil {
 		return fmt.Sprintf("backward (%d, %s)", qi.Point.BlockSlot, qi.Point.BlockHash)
@@ -27,6 +35,8 @@ func (qi blockIndexerRunnerQueueItem) String() string {
 type BlockIndexerRunnerConfig struct {
 	QueueChannelSize int           `json:"queueChannelSize"`
 	RetryDelay       time.Duration `json:"retryDelay"`
+	// SyncedSlotLag is the max number of slots between the tip and the latest processed point while runner is synced
+	SyncedSlotLag uint64 `json:"syncedSlotLag"`
 }
 
 type BlockIndexerRunner struct {
@@ -39,12 +49,16 @@ type BlockIndexerRunner struct {
 	stopLoopCh         chan struct{}
 	loopFinishedCh     chan struct{}
 	queueCh            chan blockIndexerRunnerQueueItem
+	progress           *SyncProgressTracker
+	metrics            Metrics
 	logger             hclog.Logger
 }
 
 var (
-	_ BlockSyncerHandler = (*BlockIndexerRunner)(nil)
-	_ Service            = (*BlockIndexerRunner)(nil)
+	_ BlockSyncerHandler   = (*BlockIndexerRunner)(nil)
+	_ Service              = (*BlockIndexerRunner)(nil)
+	_ ChainTipHandler      = (*BlockIndexerRunner)(nil)
+	_ SyncProgressProvider = (*BlockIndexerRunner)(nil)
 )
 
 func NewBlockIndexerRunner(
@@ -58,6 +72,8 @@ func NewBlockIndexerRunner(
 		loopFinishedCh:     make(chan struct{}),
 		stopLoopCh:         make(chan struct{}),
 		queueCh:            make(chan blockIndexerRunnerQueueItem, config.QueueChannelSize),
+		progress:           NewSyncProgressTracker(config.SyncedSlotLag),
+		metrics:            NoopMetrics{},
 		logger:             logger,
 	}
 
@@ -66,11 +82,16 @@ func NewBlockIndexerRunner(
 	return runner
 }
 
+// Close stops the runner and closes the block syncer handler if it is closable (block indexer stops the pruner)
 func (br *BlockIndexerRunner) Close() error {
 	if atomic.CompareAndSwapUint32(&br.isClosed, 0, 1) {
 		br.logger.Info("Closing block indexer runner")
 
 		close(br.closeCh)
+
+		if closable, ok := br.blockSyncerHandler.(Closable); ok {
+			return closable.Close()
+		}
 	}
 
 	return nil
@@ -88,6 +109,8 @@ func (br *BlockIndexerRunner) RollBackward(point BlockPoint) error {
 	case <-br.closeCh:
 	}
 
+	br.metrics.SetGauge(MetricRunnerQueueDepth, float64(len(queueCh)))
+
 	return nil
 }
 
@@ -103,9 +126,35 @@ func (br *BlockIndexerRunner) RollForward(blockHeader BlockHeader, txsRetriever
 	case <-br.closeCh:
 	}
 
+	br.metrics.SetGauge(MetricRunnerQueueDepth, float64(len(queueCh)))
+
 	return nil
 }
 
+// SetChainTip passes the tip to the handler immediately (not through the queue)
+func (br *BlockIndexerRunner) SetChainTip(tip BlockPoint) {
+	br.progress.SetTip(tip)
+
+	if tipHandler, ok := br.blockSyncerHandler.(ChainTipHandler); ok {
+		tipHandler.SetChainTip(tip)
+	}
+}
+
+// SetMetrics sets the receiver of the queue depth and retries measurements. It must be called before Reset
+func (br *BlockIndexerRunner) SetMetrics(metrics Metrics) {
+	br.metrics = metrics
+}
+
+// GetSyncProgress returns the tip and the latest point processed by the handler (not just queued)
+func (br *BlockIndexerRunner) GetSyncProgress() SyncProgress {
+	return br.progress.GetSyncProgress()
+}
+
+// SetSyncStateHandler sets handler which is notified when the runner becomes synced or falls behind the tip
+func (br *BlockIndexerRunner) SetSyncStateHandler(handler SyncStateHandler) {
+	br.progress.SetSyncStateHandler(handler)
+}
+
 func (br *BlockIndexerRunner) Reset() (BlockPoint, error) {
 	// stop main runner loop if started
 	close(br.stopLoopCh)
@@ -120,6 +169,8 @@ func (br *BlockIndexerRunner) Reset() (BlockPoint, error) {
 	if err != nil {
 		return bp, err
 	}
+
+	br.progress.SetLatestPoint(bp)
 	// create channels again
 	br.lock.Lock()
 	br.queueCh = make(chan blockIndexerRunnerQueueItem, br.config.QueueChannelSize)
@@ -159,6 +210,8 @@ func (br *BlockIndexerRunner) runMainLoop() {
 			case <-stopLoopCh:
 				return
 			case item := <-queueCh:
+				br.metrics.SetGauge(MetricRunnerQueueDepth, float64(len(queueCh)))
+
 				if br.execute(item, stopLoopCh) {
 					return
 				}
@@ -185,6 +238,8 @@ func (br *BlockIndexerRunner) execute(
 		}
 
 		if err == nil {
+			br.progress.SetLatestPoint(item.latestPoint())
+
 			return false // item processed successfully
 		}
 
@@ -201,6 +256,8 @@ func (br *BlockIndexerRunner) execute(
 			return false
 		}
 
+		br.metrics.AddCounter(MetricRunnerRetries, 1)
+
 		select {
 		case <-br.closeCh:
 			return true
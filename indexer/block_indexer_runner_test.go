@@ -145,16 +145,75 @@ func TestBlockIndexerRunner_Execute(t *testing.T) {
 			return nil
 		}
 	}
+	metrics := NewMetricsMock()
 	runner := NewBlockIndexerRunner(handlerMock, &BlockIndexerRunnerConfig{}, hclog.NewNullLogger())
+	runner.SetMetrics(metrics)
 
 	t.Run("should break loop on stop and return true if processConfirmedBlockErr", func(t *testing.T) {
 		stopLoopCh := make(chan struct{})
 		close(stopLoopCh)
 
 		require.True(t, runner.execute(blockIndexerRunnerQueueItem{BlockHeader: &BlockHeader{Slot: 1}}, stopLoopCh))
+		require.Positive(t, metrics.GetCounter(MetricRunnerRetries))
 	})
 
 	t.Run("should break loop and return false if normal error", func(t *testing.T) {
 		require.False(t, runner.execute(blockIndexerRunnerQueueItem{BlockHeader: &BlockHeader{Slot: 2}}, nil))
 	})
 }
+
+func TestBlockIndexerRunner_SyncProgress(t *testing.T) {
+	var (
+		tip         BlockPoint
+		processedCh = make(chan struct{}, 3)
+		syncedCh    = make(chan SyncProgress, 1)
+	)
+
+	handlerMock := NewBlockSyncerHandlerMock(1000, "ff")
+	handlerMock.RollForwardFn = func(_ BlockHeader, _ BlockTxsRetriever) error {
+		processedCh <- struct{}{}
+
+		return nil
+	}
+	runner := NewBlockIndexerRunner(&blockSyncerChainTipHandlerMock{
+		BlockSyncerHandlerMock: handlerMock,
+		tip:                    &tip,
+	}, &BlockIndexerRunnerConfig{QueueChannelSize: 2, SyncedSlotLag: 10}, hclog.NewNullLogger())
+
+	defer runner.Close()
+
+	runner.SetSyncStateHandler(func(progress SyncProgress) {
+		syncedCh <- progress
+	})
+
+	bp, err := runner.Reset()
+	require.NoError(t, err)
+	require.Equal(t, bp, runner.GetSyncProgress().LatestPoint)
+
+	runner.SetChainTip(BlockPoint{BlockSlot: 1025})
+
+	// tip is passed to the handler immediately
+	require.Equal(t, BlockPoint{BlockSlot: 1025}, tip)
+	require.Equal(t, uint64(25), runner.GetSyncProgress().SlotLag)
+
+	require.NoError(t, runner.RollForward(BlockHeader{Slot: 1020, Hash: Hash{2}}, nil))
+
+	select {
+	case progress := <-syncedCh:
+		require.True(t, progress.IsSynced)
+		require.Equal(t, BlockPoint{BlockSlot: 1020, BlockHash: Hash{2}}, progress.LatestPoint)
+	case <-time.After(time.Second * 2):
+		t.Fatalf("timeout")
+	}
+
+	<-processedCh
+}
+
+type blockSyncerChainTipHandlerMock struct {
+	*BlockSyncerHandlerMock
+	tip *BlockPoint
+}
+
+func (m *blockSyncerChainTipHandlerMock) SetChainTip(tip BlockPoint) {
+	*m.tip = tip
+}
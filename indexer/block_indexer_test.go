@@ -1,9 +1,14 @@
 package indexer
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/Ethernal-Tech/cardano-infrastructure/wallet"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -462,6 +467,7 @@ func TestBlockIndexer_RollBackward_RollbackToUnconfirmed(t *testing.T) {
 	blockIndexer := NewBlockIndexer(config, nil, dbMock, hclog.NewNullLogger())
 
 	dbMock.On("GetLatestBlockPoint").Return((*BlockPoint)(nil), error(nil)).Once()
+	dbMock.On("GetAddressesOfInterest").Return([]string(nil), error(nil)).Once()
 
 	sp, err := blockIndexer.Reset()
 	require.NoError(t, err)
@@ -537,6 +543,7 @@ func TestBlockIndexer_RollBackwardFunc_Error(t *testing.T) {
 	}
 
 	dbMock.On("GetLatestBlockPoint").Return((*BlockPoint)(nil), error(nil)).Once()
+	dbMock.On("GetAddressesOfInterest").Return([]string(nil), error(nil)).Once()
 
 	sp, err := blockIndexer.Reset()
 	require.NoError(t, err)
@@ -689,3 +696,665 @@ func TestBlockIndexer_RollForward(t *testing.T) {
 		dbMock.AssertExpectations(t)
 	}
 }
+
+func TestBlockIndexer_UnconfirmedBlockHandler(t *testing.T) {
+	t.Parallel()
+
+	inputTxHash := Hash{1, 2, 3}
+	blockHeaders := []BlockHeader{
+		{Slot: 1, Hash: Hash{1}},
+		{Slot: 2, Hash: Hash{2}},
+		{Slot: 3, Hash: Hash{3}},
+	}
+	getTxsMock := &BlockTxsRetrieverMock{
+		RetrieveFn: func(blockHeader BlockHeader) ([]*Tx, error) {
+			switch blockHeader.Slot {
+			case 1:
+				return []*Tx{
+					{Hash: Hash{0, 1}, Outputs: []*TxOutput{{Address: addresses[0], Amount: 50}}},
+					{Hash: Hash{0, 2}, Outputs: []*TxOutput{{Address: addresses[2], Amount: 50}}},
+				}, nil
+			case 2:
+				return []*Tx{
+					{
+						Hash:    Hash{0, 3},
+						Inputs:  []*TxInputOutput{{Input: TxInput{Hash: inputTxHash}}},
+						Outputs: []*TxOutput{{Address: addresses[2], Amount: 20}},
+					},
+				}, nil
+			default:
+				return nil, nil
+			}
+		},
+	}
+	config := &BlockIndexerConfig{
+		ConfirmationBlockCount: 5,
+		AddressCheck:           AddressCheckAll,
+		AddressesOfInterest:    []string{addresses[0]},
+	}
+	dbMock := &DatabaseMock{
+		Writter: &DBTransactionWriterMock{},
+	}
+	events := []UnconfirmedBlockEvent(nil)
+
+	dbMock.On("GetLatestBlockPoint").Return((*BlockPoint)(nil), error(nil)).Once()
+	dbMock.On("GetAddressesOfInterest").Return([]string(nil), error(nil)).Once()
+	dbMock.On("GetTxOutput", TxInput{Hash: inputTxHash}).
+		Return(TxOutput{Address: addresses[0], Amount: 70}, error(nil)).Once()
+
+	blockIndexer := NewBlockIndexer(config, nil, dbMock, hclog.NewNullLogger())
+	blockIndexer.SetUnconfirmedBlockHandler(func(e UnconfirmedBlockEvent) error {
+		events = append(events, e)
+
+		return nil
+	})
+
+	_, err := blockIndexer.Reset()
+	require.NoError(t, err)
+
+	for _, h := range blockHeaders {
+		require.NoError(t, blockIndexer.RollForward(h, getTxsMock))
+	}
+
+	require.Len(t, events, 3)
+
+	for i, e := range events {
+		require.Equal(t, UnconfirmedBlockEventNew, e.Type)
+		require.Equal(t, blockHeaders[i].Hash, e.Block.Hash)
+	}
+
+	require.Len(t, events[0].Txs, 1)
+	require.Equal(t, Hash{0, 1}, events[0].Txs[0].Hash)
+	require.Equal(t, []Hash{{0, 1}}, events[0].Block.Txs)
+	// input is resolved from the database
+	require.Len(t, events[1].Txs, 1)
+	require.Equal(t, addresses[0], events[1].Txs[0].Inputs[0].Output.Address)
+	require.Empty(t, events[2].Txs)
+
+	require.NoError(t, blockIndexer.RollBackward(BlockPoint{BlockSlot: 1, BlockHash: Hash{1}}))
+	require.NoError(t, blockIndexer.RollBackward(BlockPoint{BlockSlot: 1, BlockHash: Hash{1}}))
+	require.NoError(t, blockIndexer.RollBackward(BlockPoint{}))
+
+	require.Len(t, events, 5)
+	require.Equal(t, UnconfirmedBlockEvent{
+		Type:             UnconfirmedBlockEventRollback,
+		RollbackPoint:    BlockPoint{BlockSlot: 1, BlockHash: Hash{1}},
+		RolledBackBlocks: blockHeaders[1:],
+	}, events[3])
+	require.Equal(t, UnconfirmedBlockEvent{
+		Type:             UnconfirmedBlockEventRollback,
+		RolledBackBlocks: blockHeaders[:1],
+	}, events[4])
+
+	dbMock.AssertExpectations(t)
+}
+
+func TestBlockIndexer_UnconfirmedBlockHandler_Error(t *testing.T) {
+	t.Parallel()
+
+	config := &BlockIndexerConfig{
+		ConfirmationBlockCount: 1,
+		AddressCheck:           AddressCheckAll,
+	}
+	fetchedBlocks := map[Hash]int{}
+	retrieveErr := error(nil)
+	getTxsMock := &BlockTxsRetrieverMock{
+		RetrieveFn: func(blockHeader BlockHeader) ([]*Tx, error) {
+			if retrieveErr != nil {
+				return nil, retrieveErr
+			}
+
+			fetchedBlocks[blockHeader.Hash]++
+
+			return []*Tx{}, nil
+		},
+	}
+	dbMock := &DatabaseMock{
+		Writter: &DBTransactionWriterMock{},
+	}
+	blockIndexer := NewBlockIndexer(config, func(*CardanoBlock, []*Tx) error {
+		return nil
+	}, dbMock, hclog.NewNullLogger())
+	blockIndexer.latestBlockPoint = &BlockPoint{}
+
+	dbMock.On("OpenTx")
+	dbMock.Writter.On("AddConfirmedTxs", mock.Anything)
+	dbMock.Writter.On("AddConfirmedBlock", mock.Anything)
+	dbMock.Writter.On("SetLatestBlockPoint", mock.Anything)
+	dbMock.Writter.On("AddTxOutputs", mock.Anything)
+	dbMock.Writter.On("RemoveTxOutputs", mock.Anything, false)
+	dbMock.Writter.On("Execute").Return(error(nil))
+
+	// without handler txs are not retrieved for unconfirmed blocks
+	require.NoError(t, blockIndexer.RollForward(BlockHeader{Slot: 1, Hash: Hash{1}}, getTxsMock))
+	require.Empty(t, fetchedBlocks)
+
+	handlerCalls := 0
+
+	blockIndexer.SetUnconfirmedBlockHandler(func(e UnconfirmedBlockEvent) error {
+		handlerCalls++
+
+		return errors.New("handler error")
+	})
+
+	// failed fetch does not change the state, so the runner can retry the same block
+	retrieveErr = errors.New("retrieve error")
+
+	var processErr *processConfirmedBlockError
+
+	err := blockIndexer.RollForward(BlockHeader{Slot: 2, Hash: Hash{2}}, getTxsMock)
+	require.ErrorContains(t, err, "retrieve error")
+	require.ErrorAs(t, err, &processErr)
+	require.Equal(t, []BlockHeader{{Slot: 1, Hash: Hash{1}}}, blockIndexer.unconfirmedBlocks.ToList())
+	require.Equal(t, 0, handlerCalls)
+
+	// handler error is only logged
+	retrieveErr = nil
+
+	require.NoError(t, blockIndexer.RollForward(BlockHeader{Slot: 2, Hash: Hash{2}}, getTxsMock))
+	require.NoError(t, blockIndexer.RollForward(BlockHeader{Slot: 3, Hash: Hash{3}}, getTxsMock))
+	require.Equal(t, 2, handlerCalls)
+	require.Equal(t, &BlockPoint{BlockSlot: 2, BlockHash: Hash{2}}, blockIndexer.latestBlockPoint)
+	// block fetched for the handler is not fetched again when it is confirmed
+	require.Equal(t, map[Hash]int{{1}: 1, {2}: 1, {3}: 1}, fetchedBlocks)
+	require.NoError(t, blockIndexer.RollBackward(BlockPoint{BlockSlot: 2, BlockHash: Hash{2}}))
+	require.Equal(t, 3, handlerCalls)
+
+	// new blocks are not fetched for the handler while catching up
+	blockIndexer.SetChainTip(BlockPoint{BlockSlot: 100_000})
+
+	require.NoError(t, blockIndexer.RollForward(BlockHeader{Slot: 4, Hash: Hash{4}}, getTxsMock))
+	require.Equal(t, 3, handlerCalls)
+	require.NotContains(t, fetchedBlocks, Hash{4})
+}
+
+func TestBlockIndexer_ProcessConfirmedBlock_UndoRecord(t *testing.T) {
+	t.Parallel()
+
+	blockHeader := BlockHeader{Slot: 200, Hash: Hash{100, 200, 100}}
+	prevBlockPoint := &BlockPoint{BlockSlot: 100, BlockHash: Hash{1}}
+	existingInput := TxInput{Hash: Hash{1, 2}, Index: 1}
+	existingOutput := TxOutput{Address: addresses[0], Amount: 300}
+	config := &BlockIndexerConfig{
+		AddressCheck:        AddressCheckAll,
+		AddressesOfInterest: []string{addresses[0]},
+		UndoJournalDepth:    10,
+	}
+	dbMock := &DatabaseMock{
+		Writter: &DBTransactionWriterMock{},
+	}
+	allTransactions := []*Tx{
+		{
+			BlockSlot: blockHeader.Slot,
+			Indx:      3,
+			Hash:      Hash{3},
+			Inputs: []*TxInputOutput{
+				{Input: existingInput},
+				{Input: TxInput{Hash: Hash{1, 2}, Index: 2}},
+			},
+			Outputs: []*TxOutput{
+				{Address: addresses[0], Amount: 100},
+			},
+		},
+	}
+	expectedRecord := &UndoRecord{
+		BlockPoint:     BlockPoint{BlockSlot: blockHeader.Slot, BlockHash: blockHeader.Hash},
+		PrevBlockPoint: prevBlockPoint,
+		TxIndexes:      []uint32{3},
+		AddedTxOutputs: []TxInput{{Hash: Hash{3}, Index: 0}},
+		RemovedTxOutputs: []*TxInputOutput{
+			{Input: existingInput, Output: existingOutput},
+		},
+	}
+
+	dbMock.On("OpenTx").Once()
+	dbMock.On("GetTxOutput", existingInput).Return(existingOutput, error(nil)).Once()
+	dbMock.On("GetTxOutput", mock.Anything).Return(TxOutput{}, error(nil)).Once()
+	dbMock.Writter.On("Execute").Return(error(nil)).Once()
+	dbMock.Writter.On("AddConfirmedTxs", mock.Anything).Once()
+	dbMock.Writter.On("SetLatestBlockPoint", mock.Anything).Once()
+	dbMock.Writter.On("RemoveTxOutputs", mock.Anything, false).Once()
+	dbMock.Writter.On("AddTxOutputs", mock.Anything).Once()
+	dbMock.Writter.On("AddConfirmedBlock", mock.Anything).Once()
+	dbMock.Writter.On("AddUndoRecord", expectedRecord, uint(10)).Once()
+
+	blockIndexer := NewBlockIndexer(config, nil, dbMock, hclog.NewNullLogger())
+	blockIndexer.latestBlockPoint = prevBlockPoint
+
+	_, _, _, err := blockIndexer.processConfirmedBlock(blockHeader, allTransactions)
+	require.NoError(t, err)
+
+	dbMock.AssertExpectations(t)
+	dbMock.Writter.AssertExpectations(t)
+}
+
+func TestBlockIndexer_RollBackward_RevertConfirmed(t *testing.T) {
+	t.Parallel()
+
+	points := []BlockPoint{
+		{BlockSlot: 5, BlockHash: Hash{0, 1}},
+		{BlockSlot: 6, BlockHash: Hash{0, 2}},
+		{BlockSlot: 7, BlockHash: Hash{0, 3}},
+	}
+	records := []*UndoRecord{
+		{BlockPoint: points[2], PrevBlockPoint: &points[1]},
+		{BlockPoint: points[1], PrevBlockPoint: &points[0]},
+	}
+	config := &BlockIndexerConfig{
+		ConfirmationBlockCount: 5,
+		AddressCheck:           AddressCheckAll,
+		UndoJournalDepth:       5,
+	}
+
+	t.Run("reverted", func(t *testing.T) {
+		dbMock := &DatabaseMock{
+			Writter: &DBTransactionWriterMock{},
+		}
+		events := []UnconfirmedBlockEvent(nil)
+		revertedBlocks := []BlockPoint(nil)
+		metrics := NewMetricsMock()
+		blockIndexer := NewBlockIndexer(config, nil, dbMock, hclog.NewNullLogger())
+		blockIndexer.latestBlockPoint = &points[2]
+		blockIndexer.SetMetrics(metrics)
+		blockIndexer.SetUnconfirmedBlockHandler(func(e UnconfirmedBlockEvent) error {
+			events = append(events, e)
+
+			return nil
+		})
+		blockIndexer.SetConfirmedBlocksRevertedHandler(func(point BlockPoint, blocks []BlockPoint) error {
+			require.Equal(t, points[0], point)
+
+			revertedBlocks = blocks
+
+			return nil
+		})
+
+		require.NoError(t, blockIndexer.unconfirmedBlocks.Push(BlockHeader{Slot: 8, Hash: Hash{0, 4}}))
+
+		dbMock.On("GetUndoRecords", uint64(5)).Return(records, error(nil)).Once()
+		dbMock.On("OpenTx").Once()
+		dbMock.Writter.On("RevertUndoRecord", records[0]).Once()
+		dbMock.Writter.On("RevertUndoRecord", records[1]).Once()
+		dbMock.Writter.On("Execute").Return(error(nil)).Once()
+
+		require.NoError(t, blockIndexer.RollBackward(points[0]))
+		require.Equal(t, points[0], *blockIndexer.latestBlockPoint)
+		require.Equal(t, 0, blockIndexer.unconfirmedBlocks.Len())
+		require.Equal(t, []UnconfirmedBlockEvent{
+			{
+				Type:                    UnconfirmedBlockEventRollback,
+				RollbackPoint:           points[0],
+				RolledBackBlocks:        []BlockHeader{{Slot: 8, Hash: Hash{0, 4}}},
+				RevertedConfirmedBlocks: []BlockPoint{points[1], points[2]},
+			},
+		}, events)
+		require.Equal(t, []BlockPoint{points[1], points[2]}, revertedBlocks)
+		// one unconfirmed and two reverted confirmed blocks
+		require.Equal(t, float64(3), metrics.GetCounter(MetricBlocksRolledBackward))
+
+		dbMock.AssertExpectations(t)
+		dbMock.Writter.AssertExpectations(t)
+	})
+
+	t.Run("reverted handler failed", func(t *testing.T) {
+		dbMock := &DatabaseMock{}
+		blockIndexer := NewBlockIndexer(config, nil, dbMock, hclog.NewNullLogger())
+		blockIndexer.latestBlockPoint = &points[2]
+		blockIndexer.SetConfirmedBlocksRevertedHandler(func(BlockPoint, []BlockPoint) error {
+			return errors.New("can not undo")
+		})
+
+		dbMock.On("GetUndoRecords", uint64(5)).Return(records, error(nil)).Once()
+
+		err := blockIndexer.RollBackward(points[0])
+		require.ErrorIs(t, err, ErrBlockIndexerFatal)
+		require.ErrorContains(t, err, "can not undo")
+		require.Equal(t, points[2], *blockIndexer.latestBlockPoint)
+
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		dbMock := &DatabaseMock{}
+		blockIndexer := NewBlockIndexer(config, nil, dbMock, hclog.NewNullLogger())
+		blockIndexer.latestBlockPoint = &points[2]
+
+		dbMock.On("GetUndoRecords", uint64(4)).Return(records, error(nil)).Once()
+
+		err := blockIndexer.RollBackward(BlockPoint{BlockSlot: 4})
+		require.ErrorIs(t, err, ErrBlockIndexerFatal)
+		require.Equal(t, points[2], *blockIndexer.latestBlockPoint)
+
+		dbMock.AssertExpectations(t)
+	})
+}
+
+func TestBlockIndexer_IsAddressOfInterest_Credentials(t *testing.T) {
+	t.Parallel()
+
+	wallet1, err := wallet.GenerateWallet(true)
+	require.NoError(t, err)
+
+	wallet2, err := wallet.GenerateWallet(true)
+	require.NoError(t, err)
+
+	paymentKeyHash, err := wallet.GetKeyHash(wallet1.VerificationKey)
+	require.NoError(t, err)
+
+	stakeKeyHash, err := wallet.GetKeyHash(wallet2.StakeVerificationKey)
+	require.NoError(t, err)
+
+	baseAddr1, err := wallet.NewBaseAddress(wallet.TestNetNetwork, wallet1.VerificationKey, wallet1.StakeVerificationKey)
+	require.NoError(t, err)
+
+	enterpriseAddr1, err := wallet.NewEnterpriseAddress(wallet.MainNetNetwork, wallet1.VerificationKey)
+	require.NoError(t, err)
+
+	baseAddr2, err := wallet.NewBaseAddress(wallet.TestNetNetwork, wallet2.VerificationKey, wallet2.StakeVerificationKey)
+	require.NoError(t, err)
+
+	rewardAddr2, err := wallet.NewRewardAddress(wallet.TestNetNetwork, wallet2.StakeVerificationKey)
+	require.NoError(t, err)
+
+	enterpriseAddr2, err := wallet.NewEnterpriseAddress(wallet.TestNetNetwork, wallet2.VerificationKey)
+	require.NoError(t, err)
+
+	t.Run("no filters", func(t *testing.T) {
+		blockIndexer := NewBlockIndexer(&BlockIndexerConfig{
+			AddressCheck: AddressCheckAll,
+		}, nil, &DatabaseMock{}, hclog.NewNullLogger())
+
+		require.True(t, blockIndexer.isAddressOfInterest(baseAddr1.String()))
+		require.True(t, blockIndexer.isAddressOfInterest(addresses[0]))
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		blockIndexer := NewBlockIndexer(&BlockIndexerConfig{
+			AddressCheck:                 AddressCheckAll,
+			AddressesOfInterest:          []string{addresses[1]},
+			PaymentCredentialsOfInterest: []string{strings.ToUpper(paymentKeyHash)},
+			StakeCredentialsOfInterest:   []string{stakeKeyHash},
+		}, nil, &DatabaseMock{}, hclog.NewNullLogger())
+
+		require.True(t, blockIndexer.isAddressOfInterest(addresses[1]))
+		require.True(t, blockIndexer.isAddressOfInterest(baseAddr1.String()))
+		require.True(t, blockIndexer.isAddressOfInterest(enterpriseAddr1.String()))
+		require.True(t, blockIndexer.isAddressOfInterest(baseAddr2.String()))
+		require.True(t, blockIndexer.isAddressOfInterest(rewardAddr2.String()))
+		require.False(t, blockIndexer.isAddressOfInterest(enterpriseAddr2.String()))
+		require.False(t, blockIndexer.isAddressOfInterest(addresses[0]))
+		require.False(t, blockIndexer.isAddressOfInterest("invalid"))
+		require.False(t, blockIndexer.isAddressOfInterest(""))
+
+		txs := blockIndexer.filterTxsOfInterest([]*Tx{
+			{Hash: Hash{1}, Outputs: []*TxOutput{{Address: enterpriseAddr2.String()}}},
+			{Hash: Hash{2}, Outputs: []*TxOutput{{Address: rewardAddr2.String()}}},
+			{Hash: Hash{3}, Inputs: []*TxInputOutput{{Output: TxOutput{Address: enterpriseAddr1.String()}}}},
+		})
+
+		require.Len(t, txs, 2)
+		require.Equal(t, Hash{2}, txs[0].Hash)
+		require.Equal(t, Hash{3}, txs[1].Hash)
+	})
+}
+
+func TestBlockIndexer_FilterTxsOfInterest_TokensAndMetadata(t *testing.T) {
+	t.Parallel()
+
+	const policyID = "29f2fe1da0b8d7b1c6e0a1e2f3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8"
+
+	bridgingMetadata, err := cbor.Marshal(map[uint64]any{1: map[string]any{"t": "bridge"}})
+	require.NoError(t, err)
+
+	otherMetadata, err := cbor.Marshal(map[uint64]any{674: "msg"})
+	require.NoError(t, err)
+
+	maryMetadata, err := cbor.Marshal([]any{map[uint64]any{1: "msg"}, []any{}})
+	require.NoError(t, err)
+
+	metadataJSON, err := json.Marshal(map[uint64]any{1: map[string]any{"t": "bridge"}})
+	require.NoError(t, err)
+
+	builder := wallet.NewTxBuilderNative()
+	defer builder.Dispose()
+
+	builder.SetProtocolParameters([]byte(`{"utxoCostPerByte":4310}`)).SetMetaData(metadataJSON)
+	builder.SetFee(200_000).SetTimeToLive(1000).AddInputs(
+		wallet.NewTxInput("e99a5bde15aa05f24fcc04b7eabc1520d3397283b1ee720de9fe2653abbb0c9f", 0),
+	).AddOutputs(wallet.TxOutput{Addr: addresses[1], Amount: 1_000_000})
+
+	txRaw, _, err := builder.Build()
+	require.NoError(t, err)
+
+	var txParts []cbor.RawMessage
+
+	require.NoError(t, cbor.Unmarshal(txRaw, &txParts))
+	require.Len(t, txParts, 4)
+
+	// auxiliary data built by the native builder is tag 259 map with the metadata under key 0
+	var auxDataTag cbor.RawTag
+
+	require.NoError(t, cbor.Unmarshal(txParts[3], &auxDataTag))
+	require.Equal(t, uint64(auxiliaryDataTag), auxDataTag.Number)
+
+	nativeMetadata := []byte(txParts[3])
+
+	blockIndexer := NewBlockIndexer(&BlockIndexerConfig{
+		AddressCheck:             AddressCheckOutputs,
+		AddressesOfInterest:      []string{addresses[0]},
+		PolicyIDsOfInterest:      []string{strings.ToUpper(policyID)},
+		AssetsOfInterest:         []string{"ab.526f75746533"},
+		MetadataLabelsOfInterest: []uint64{1},
+	}, nil, &DatabaseMock{}, hclog.NewNullLogger())
+
+	txs := blockIndexer.filterTxsOfInterest([]*Tx{
+		{Hash: Hash{1}, Outputs: []*TxOutput{{Address: addresses[1]}}},
+		{Hash: Hash{2}, Outputs: []*TxOutput{{Address: addresses[0]}}},
+		{Hash: Hash{3}, Mint: []MintedToken{{PolicyID: policyID, Name: "WADA", Amount: -10}}},
+		{Hash: Hash{4}, Outputs: []*TxOutput{
+			{Address: addresses[1], Tokens: []TokenAmount{{PolicyID: "ab", Name: "Route3", Amount: 1}}},
+		}},
+		{Hash: Hash{5}, Outputs: []*TxOutput{
+			{Address: addresses[1], Tokens: []TokenAmount{{PolicyID: "ab", Name: "Route4", Amount: 1}}},
+		}},
+		{Hash: Hash{6}, Inputs: []*TxInputOutput{
+			{Output: TxOutput{Address: addresses[2], Tokens: []TokenAmount{{PolicyID: policyID, Amount: 1}}}},
+		}},
+		{Hash: Hash{7}, Metadata: bridgingMetadata},
+		{Hash: Hash{8}, Metadata: otherMetadata},
+		{Hash: Hash{9}, Metadata: []byte("invalid")},
+		{Hash: Hash{10}, Metadata: nativeMetadata},
+		{Hash: Hash{11}, Metadata: maryMetadata},
+	})
+
+	hashes := make([]Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash
+	}
+
+	require.Equal(t, []Hash{{2}, {3}, {4}, {6}, {7}, {10}, {11}}, hashes)
+
+	t.Run("without address filters all outputs are of interest", func(t *testing.T) {
+		blockIndexer := NewBlockIndexer(&BlockIndexerConfig{
+			AddressCheck:             AddressCheckAll,
+			MetadataLabelsOfInterest: []uint64{1},
+		}, nil, &DatabaseMock{}, hclog.NewNullLogger())
+
+		txs := blockIndexer.filterTxsOfInterest([]*Tx{
+			{Hash: Hash{1}, Outputs: []*TxOutput{{Address: addresses[1]}}},
+			{Hash: Hash{2}, Outputs: []*TxOutput{{Address: addresses[1]}}, Metadata: bridgingMetadata},
+		})
+
+		require.Len(t, txs, 1)
+		require.Equal(t, Hash{2}, txs[0].Hash)
+		require.Len(t, getTxOutputs(txs, blockIndexer.isAddressOfInterest), 1)
+	})
+}
+
+func TestBlockIndexer_AddressesOfInterest(t *testing.T) {
+	t.Parallel()
+
+	addresses := []string{"addr_test1", "addr_test2", "addr_test3", "addr_test4"}
+	dbMock := &DatabaseMock{
+		Writter: &DBTransactionWriterMock{},
+	}
+	config := &BlockIndexerConfig{
+		AddressCheck:        AddressCheckAll,
+		AddressesOfInterest: []string{addresses[0]},
+	}
+	blockIndexer := NewBlockIndexer(config, nil, dbMock, hclog.NewNullLogger())
+
+	dbMock.On("OpenTx")
+
+	t.Run("add remove replace", func(t *testing.T) {
+		dbMock.Writter.On("SetAddressesOfInterest", []string{addresses[0], addresses[1], addresses[2]}).Once()
+		dbMock.Writter.On("Execute").Return(error(nil)).Times(3)
+		dbMock.Writter.On("SetAddressesOfInterest", []string{addresses[0], addresses[2]}).Once()
+		dbMock.Writter.On("SetAddressesOfInterest", []string{addresses[3]}).Once()
+
+		require.NoError(t, blockIndexer.AddAddressesOfInterest(addresses[1], addresses[2]))
+		require.NoError(t, blockIndexer.RemoveAddressesOfInterest(addresses[1]))
+		require.Equal(t, []string{addresses[0], addresses[2]}, blockIndexer.GetAddressesOfInterest())
+		require.NoError(t, blockIndexer.ReplaceAddressesOfInterest([]string{addresses[3]}))
+		require.Equal(t, []string{addresses[3]}, blockIndexer.GetAddressesOfInterest())
+
+		dbMock.Writter.On("SetAddressesOfInterest", []string{addresses[0], addresses[3]}).Once()
+		dbMock.Writter.On("Execute").Return(errors.New("db error")).Once()
+
+		// in memory set is not changed if it could not be persisted
+		require.ErrorContains(t, blockIndexer.AddAddressesOfInterest(addresses[0]), "db error")
+		require.Equal(t, []string{addresses[3]}, blockIndexer.GetAddressesOfInterest())
+
+		// removing all the addresses would make every address of interest
+		require.ErrorIs(t, blockIndexer.RemoveAddressesOfInterest(addresses[3]), ErrNoAddressesOfInterest)
+		require.ErrorIs(t, blockIndexer.ReplaceAddressesOfInterest(nil), ErrNoAddressesOfInterest)
+		require.Equal(t, []string{addresses[3]}, blockIndexer.GetAddressesOfInterest())
+		require.False(t, blockIndexer.isAddressOfInterest(addresses[0]))
+		dbMock.Writter.AssertExpectations(t)
+	})
+
+	t.Run("other filters do not allow removing all the addresses", func(t *testing.T) {
+		blockIndexer := NewBlockIndexer(&BlockIndexerConfig{
+			AddressCheck:             AddressCheckAll,
+			AddressesOfInterest:      []string{addresses[0]},
+			PolicyIDsOfInterest:      []string{"ab"},
+			MetadataLabelsOfInterest: []uint64{1},
+		}, nil, dbMock, hclog.NewNullLogger())
+
+		require.ErrorIs(t, blockIndexer.RemoveAddressesOfInterest(addresses[0]), ErrNoAddressesOfInterest)
+		require.ErrorIs(t, blockIndexer.ReplaceAddressesOfInterest(nil), ErrNoAddressesOfInterest)
+		require.False(t, blockIndexer.isAddressOfInterest(addresses[1]))
+	})
+
+	t.Run("reset loads persisted addresses", func(t *testing.T) {
+		dbMock.On("GetLatestBlockPoint").Return((*BlockPoint)(nil), error(nil)).Once()
+		dbMock.On("GetAddressesOfInterest").Return([]string{addresses[1]}, error(nil)).Once()
+
+		_, err := blockIndexer.Reset()
+		require.NoError(t, err)
+		require.Equal(t, []string{addresses[1]}, blockIndexer.GetAddressesOfInterest())
+	})
+
+	t.Run("backfill", func(t *testing.T) {
+		require.Error(t, blockIndexer.AddAddressesOfInterestWithBackfill(10, addresses[2]))
+
+		var (
+			backfillSlot      uint64
+			backfillAddresses []string
+		)
+
+		blockIndexer.SetAddressesBackfillHandler(func(fromSlot uint64, addrs []string) error {
+			backfillSlot, backfillAddresses = fromSlot, addrs
+
+			return nil
+		})
+
+		dbMock.Writter.On("SetAddressesOfInterest", []string{addresses[1], addresses[2]}).Once()
+		dbMock.Writter.On("Execute").Return(error(nil)).Once()
+
+		require.NoError(t, blockIndexer.AddAddressesOfInterestWithBackfill(10, addresses[1], addresses[2]))
+		require.Equal(t, uint64(10), backfillSlot)
+		require.Equal(t, []string{addresses[2]}, backfillAddresses)
+
+		blockIndexer.SetAddressesBackfillHandler(func(fromSlot uint64, addrs []string) error {
+			return errors.New("backfill error")
+		})
+
+		dbMock.Writter.On("SetAddressesOfInterest", []string{addresses[0], addresses[1], addresses[2]}).Once()
+		dbMock.Writter.On("Execute").Return(error(nil)).Once()
+
+		var backfillErr *AddressesBackfillError
+
+		err := blockIndexer.AddAddressesOfInterestWithBackfill(5, addresses[0])
+		require.ErrorContains(t, err, "backfill error")
+		require.ErrorAs(t, err, &backfillErr)
+		require.Equal(t, uint64(5), backfillErr.FromSlot)
+		require.Equal(t, []string{addresses[0]}, backfillErr.Addresses)
+		dbMock.Writter.AssertExpectations(t)
+	})
+}
+
+func TestBlockIndexer_ConfirmedBlocksBatch_SaveRetry(t *testing.T) {
+	t.Parallel()
+
+	confirmedBlocks := []*CardanoBlock(nil)
+	dbMock := &DatabaseMock{
+		Writter: &DBTransactionWriterMock{},
+	}
+	txsRetriever := &BlockTxsRetrieverMock{
+		RetrieveFn: func(_ BlockHeader) ([]*Tx, error) {
+			return []*Tx{}, nil
+		},
+	}
+	blockIndexer := NewBlockIndexer(&BlockIndexerConfig{
+		ConfirmationBlockCount:   1,
+		AddressCheck:             AddressCheckOutputs,
+		ConfirmedBlocksBatchSize: 2,
+	}, func(cb *CardanoBlock, _ []*Tx) error {
+		confirmedBlocks = append(confirmedBlocks, cb)
+
+		return nil
+	}, dbMock, hclog.NewNullLogger())
+
+	metrics := NewMetricsMock()
+
+	blockIndexer.latestBlockPoint = &BlockPoint{}
+	blockIndexer.SetChainTip(BlockPoint{BlockSlot: 100_000})
+	blockIndexer.SetMetrics(metrics)
+
+	dbMock.On("OpenTx")
+	dbMock.Writter.On("AddConfirmedTxs", mock.Anything)
+	dbMock.Writter.On("AddConfirmedBlock", mock.Anything)
+	dbMock.Writter.On("SetLatestBlockPoint", mock.Anything)
+	dbMock.Writter.On("AddTxOutputs", mock.Anything)
+	dbMock.Writter.On("RemoveTxOutputs", mock.Anything, false)
+	dbMock.Writter.On("Execute").Return(errors.New("db error")).Once()
+
+	headers := []BlockHeader{{Slot: 1, Hash: Hash{1}}, {Slot: 2, Hash: Hash{2}}, {Slot: 3, Hash: Hash{3}}}
+
+	require.NoError(t, blockIndexer.RollForward(headers[0], txsRetriever))
+	require.NoError(t, blockIndexer.RollForward(headers[1], txsRetriever))
+	dbMock.Writter.AssertNotCalled(t, "Execute")
+
+	var processErr *processConfirmedBlockError
+
+	require.ErrorAs(t, blockIndexer.RollForward(headers[2], txsRetriever), &processErr)
+	require.Empty(t, confirmedBlocks)
+
+	dbMock.Writter.On("Execute").Return(error(nil)).Once()
+
+	// runner retries the same block -> only the batch is saved again
+	require.NoError(t, blockIndexer.RollForward(headers[2], txsRetriever))
+
+	require.Len(t, confirmedBlocks, 2)
+	require.Equal(t, uint64(1), confirmedBlocks[0].Slot)
+	require.Equal(t, uint64(2), confirmedBlocks[1].Slot)
+	require.Equal(t, &BlockPoint{BlockSlot: 2, BlockHash: Hash{2}}, blockIndexer.latestBlockPoint)
+	require.Equal(t, []BlockHeader{headers[2]}, blockIndexer.unconfirmedBlocks.ToList())
+	require.Empty(t, blockIndexer.confirmedBatch)
+	dbMock.Writter.AssertNumberOfCalls(t, "Execute", 2)
+	dbMock.Writter.AssertNumberOfCalls(t, "AddConfirmedBlock", 4)
+	require.Equal(t, float64(3), metrics.GetCounter(MetricBlocksRolledForward))
+	require.Equal(t, float64(2), metrics.GetCounter(MetricConfirmedBlocks))
+	require.Equal(t, 1, metrics.GetObservations(MetricDBCommitDuration))
+}
@@ -0,0 +1,260 @@
+package indexer
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const UtxoSnapshotVersion = 1
+
+// UtxoSnapshot is the set of unspent outputs at the given block point
+type UtxoSnapshot struct {
+	Version    int              `json:"version"`
+	BlockPoint BlockPoint       `json:"blockPoint"`
+	TxOutputs  []*TxInputOutput `json:"utxos"`
+}
+
+// ReadUtxoSnapshot reads json or cbor encoded snapshot
+func ReadUtxoSnapshot(r io.Reader) (*UtxoSnapshot, error) {
+	var snapshot UtxoSnapshot
+
+	reader := bufio.NewReader(r)
+
+	firstByte, err := peekFirstNonSpaceByte(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read utxo snapshot: %w", err)
+	}
+
+	if firstByte == '{' {
+		err = json.NewDecoder(reader).Decode(&snapshot)
+	} else {
+		err = cbor.NewDecoder(reader).Decode(&snapshot)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode utxo snapshot: %w", err)
+	}
+
+	if snapshot.Version != UtxoSnapshotVersion {
+		return nil, fmt.Errorf("unsupported utxo snapshot version: %d", snapshot.Version)
+	}
+
+	return &snapshot, nil
+}
+
+// WriteUtxoSnapshot writes json (or cbor) encoded snapshot
+func WriteUtxoSnapshot(w io.Writer, snapshot *UtxoSnapshot, asCBOR bool) error {
+	if asCBOR {
+		return cbor.NewEncoder(w).Encode(snapshot)
+	}
+
+	return json.NewEncoder(w).Encode(snapshot)
+}
+
+// cardanoCliUtxo is a single output of the `cardano-cli query utxo --output-json` command
+type cardanoCliUtxo struct {
+	Address         string                     `json:"address"`
+	DatumHash       string                     `json:"datumhash"`
+	InlineDatumRaw  string                     `json:"inlineDatumRaw"`
+	Value           map[string]json.RawMessage `json:"value"`
+	ReferenceScript *struct {
+		Script struct {
+			CborHex string `json:"cborHex"`
+			Type    string `json:"type"`
+		} `json:"script"`
+	} `json:"referenceScript"`
+}
+
+// script reference types as defined in cddl (native script and plutus scripts by version)
+var cardanoCliScriptRefTypes = map[string]uint64{
+	"SimpleScript":   0,
+	"PlutusScriptV1": 1,
+	"PlutusScriptV2": 2,
+	"PlutusScriptV3": 3,
+}
+
+// NewUtxoSnapshotFromCardanoCli creates snapshot from the ledger state utxo dump
+// (`cardano-cli query utxo --whole-utxo --output-json`) taken at the given block point.
+// Slot of every output is set to the block point slot because the dump does not contain it
+func NewUtxoSnapshotFromCardanoCli(r io.Reader, blockPoint BlockPoint) (*UtxoSnapshot, error) {
+	var utxos map[string]cardanoCliUtxo
+
+	if err := json.NewDecoder(r).Decode(&utxos); err != nil {
+		return nil, fmt.Errorf("failed to decode utxo dump: %w", err)
+	}
+
+	snapshot := &UtxoSnapshot{
+		Version:    UtxoSnapshotVersion,
+		BlockPoint: blockPoint,
+		TxOutputs:  make([]*TxInputOutput, 0, len(utxos)),
+	}
+
+	for key, utxo := range utxos {
+		txInOut, err := utxo.toTxInputOutput(key, blockPoint.BlockSlot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid utxo %s: %w", key, err)
+		}
+
+		snapshot.TxOutputs = append(snapshot.TxOutputs, txInOut)
+	}
+
+	SortTxInputOutputs(snapshot.TxOutputs)
+
+	return snapshot, nil
+}
+
+func (u *cardanoCliUtxo) toTxInputOutput(key string, slot uint64) (*TxInputOutput, error) {
+	hashStr, indexStr, found := strings.Cut(key, "#")
+	if !found {
+		return nil, errors.New("invalid key")
+	}
+
+	hash, err := hex.DecodeString(hashStr)
+	if err != nil || len(hash) != HashSize {
+		return nil, errors.New("invalid tx hash")
+	}
+
+	index, err := strconv.ParseUint(indexStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index: %w", err)
+	}
+
+	output := TxOutput{
+		Address: u.Address,
+		Slot:    slot,
+	}
+
+	for policyID, value := range u.Value {
+		if policyID == "lovelace" {
+			if err := json.Unmarshal(value, &output.Amount); err != nil {
+				return nil, fmt.Errorf("invalid amount: %w", err)
+			}
+
+			continue
+		}
+
+		var assets map[string]uint64
+
+		if err := json.Unmarshal(value, &assets); err != nil {
+			return nil, fmt.Errorf("invalid assets: %w", err)
+		}
+
+		for name, amount := range assets {
+			nameBytes, err := hex.DecodeString(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid asset name: %w", err)
+			}
+
+			output.Tokens = append(output.Tokens, TokenAmount{
+				PolicyID: policyID,
+				Name:     string(nameBytes),
+				Amount:   amount,
+			})
+		}
+	}
+
+	if u.DatumHash != "" {
+		datumHash, err := hex.DecodeString(u.DatumHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid datum hash: %w", err)
+		}
+
+		output.DatumHash = NewHashFromBytes(datumHash)
+	}
+
+	if u.InlineDatumRaw != "" {
+		if output.Datum, err = hex.DecodeString(u.InlineDatumRaw); err != nil {
+			return nil, fmt.Errorf("invalid inline datum: %w", err)
+		}
+	}
+
+	if u.ReferenceScript != nil {
+		if output.ScriptRef, err = u.getScriptRef(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TxInputOutput{
+		Input: TxInput{
+			Hash:  Hash(hash),
+			Index: uint32(index), //nolint:gosec
+		},
+		Output: output,
+	}, nil
+}
+
+func (u *cardanoCliUtxo) getScriptRef() ([]byte, error) {
+	scriptType, exists := cardanoCliScriptRefTypes[u.ReferenceScript.Script.Type]
+	if !exists {
+		return nil, fmt.Errorf("unsupported reference script type: %s", u.ReferenceScript.Script.Type)
+	}
+
+	script, err := hex.DecodeString(u.ReferenceScript.Script.CborHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference script: %w", err)
+	}
+
+	return cbor.Marshal([]any{scriptType, cbor.RawMessage(script)})
+}
+
+// Bootstrap seeds the empty database with the snapshot outputs and sets the latest block point to the snapshot point
+// in a single database transaction, so syncing continues right after the snapshot point.
+// Only outputs of interest are saved unless all outputs should be kept. It must be called before syncing starts
+func (bi *BlockIndexer) Bootstrap(snapshot *UtxoSnapshot) error {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	latestPoint, err := bi.db.GetLatestBlockPoint()
+	if err != nil {
+		return err
+	}
+
+	if latestPoint != nil {
+		return fmt.Errorf("database is already initialized with the latest block point: %s", latestPoint)
+	}
+
+	txOutputs := snapshot.TxOutputs
+
+	if !bi.config.KeepAllTxOutputsInDB {
+		txOutputs = make([]*TxInputOutput, 0, len(snapshot.TxOutputs))
+
+		for _, txOutput := range snapshot.TxOutputs {
+			if bi.isAddressOfInterest(txOutput.Output.Address) {
+				txOutputs = append(txOutputs, txOutput)
+			}
+		}
+	}
+
+	if err := bi.db.OpenTx().
+		AddTxOutputs(txOutputs).
+		SetLatestBlockPoint(&snapshot.BlockPoint).
+		Execute(); err != nil {
+		return fmt.Errorf("failed to bootstrap database: %w", err)
+	}
+
+	bi.logger.Info("Database has been bootstrapped from the utxo snapshot",
+		"point", snapshot.BlockPoint, "outputs", len(txOutputs))
+
+	return nil
+}
+
+func peekFirstNonSpaceByte(reader *bufio.Reader) (byte, error) {
+	for i := 1; ; i++ {
+		data, err := reader.Peek(i)
+		if err != nil {
+			return 0, err
+		}
+
+		if b := data[i-1]; strings.IndexByte(" \t\r\n", b) < 0 {
+			return b, nil
+		}
+	}
+}
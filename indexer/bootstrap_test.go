@@ -0,0 +1,137 @@
+package indexer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUtxoSnapshot_ReadWrite(t *testing.T) {
+	t.Parallel()
+
+	snapshot := &UtxoSnapshot{
+		Version:    UtxoSnapshotVersion,
+		BlockPoint: BlockPoint{BlockSlot: 100, BlockHash: Hash{1}},
+		TxOutputs: []*TxInputOutput{
+			{
+				Input: TxInput{Hash: Hash{2}, Index: 1},
+				Output: TxOutput{
+					Address: addresses[0],
+					Slot:    90,
+					Amount:  1_000_000,
+					Datum:   []byte{0x18, 0x2a},
+					Tokens:  []TokenAmount{{PolicyID: "29f2fe", Name: "Route3", Amount: 10}},
+				},
+			},
+		},
+	}
+
+	for _, asCBOR := range []bool{false, true} {
+		var buffer bytes.Buffer
+
+		require.NoError(t, WriteUtxoSnapshot(&buffer, snapshot, asCBOR))
+
+		result, err := ReadUtxoSnapshot(&buffer)
+		require.NoError(t, err)
+		require.Equal(t, snapshot, result)
+	}
+
+	_, err := ReadUtxoSnapshot(strings.NewReader(`  {"version": 2}`))
+	require.ErrorContains(t, err, "unsupported utxo snapshot version")
+
+	_, err = ReadUtxoSnapshot(strings.NewReader(""))
+	require.Error(t, err)
+}
+
+func TestNewUtxoSnapshotFromCardanoCli(t *testing.T) {
+	t.Parallel()
+
+	const dump = `{
+		"0100000000000000000000000000000000000000000000000000000000000000#1": {
+			"address": "addr_test1",
+			"datum": null,
+			"inlineDatumRaw": "182a",
+			"referenceScript": {
+				"script": {"cborHex": "4e4d01000033222220051200120011", "type": "PlutusScriptV2"}
+			},
+			"value": {"lovelace": 2000000, "29f2fe": {"526f75746533": 10}}
+		},
+		"0200000000000000000000000000000000000000000000000000000000000000#0": {
+			"address": "addr_test2",
+			"datumhash": "0300000000000000000000000000000000000000000000000000000000000000",
+			"value": {"lovelace": 1000000}
+		}
+	}`
+
+	blockPoint := BlockPoint{BlockSlot: 100, BlockHash: Hash{9}}
+
+	snapshot, err := NewUtxoSnapshotFromCardanoCli(strings.NewReader(dump), blockPoint)
+	require.NoError(t, err)
+	require.Equal(t, UtxoSnapshotVersion, snapshot.Version)
+	require.Equal(t, blockPoint, snapshot.BlockPoint)
+	require.Equal(t, []*TxInputOutput{
+		{
+			Input: TxInput{Hash: Hash{1}, Index: 1},
+			Output: TxOutput{
+				Address: "addr_test1",
+				Slot:    100,
+				Amount:  2_000_000,
+				Datum:   []byte{0x18, 0x2a},
+				Tokens:  []TokenAmount{{PolicyID: "29f2fe", Name: "Route3", Amount: 10}},
+				ScriptRef: append([]byte{0x82, 0x02}, []byte{0x4e, 0x4d, 0x01, 0x00, 0x00, 0x33, 0x22, 0x22, 0x20,
+					0x05, 0x12, 0x00, 0x12, 0x00, 0x11}...),
+			},
+		},
+		{
+			Input: TxInput{Hash: Hash{2}, Index: 0},
+			Output: TxOutput{
+				Address:   "addr_test2",
+				Slot:      100,
+				Amount:    1_000_000,
+				DatumHash: Hash{3},
+			},
+		},
+	}, snapshot.TxOutputs)
+
+	_, err = NewUtxoSnapshotFromCardanoCli(strings.NewReader(`{"01#0": {"value": {}}}`), blockPoint)
+	require.ErrorContains(t, err, "invalid tx hash")
+}
+
+func TestBlockIndexer_Bootstrap(t *testing.T) {
+	t.Parallel()
+
+	snapshot := &UtxoSnapshot{
+		Version:    UtxoSnapshotVersion,
+		BlockPoint: BlockPoint{BlockSlot: 100, BlockHash: Hash{1}},
+		TxOutputs: []*TxInputOutput{
+			{Input: TxInput{Hash: Hash{2}}, Output: TxOutput{Address: addresses[0], Amount: 10}},
+			{Input: TxInput{Hash: Hash{3}}, Output: TxOutput{Address: addresses[1], Amount: 20}},
+		},
+	}
+
+	dbMock := &DatabaseMock{
+		Writter: &DBTransactionWriterMock{},
+	}
+	blockIndexer := NewBlockIndexer(&BlockIndexerConfig{
+		AddressCheck:        AddressCheckAll,
+		AddressesOfInterest: []string{addresses[1]},
+	}, nil, dbMock, hclog.NewNullLogger())
+
+	dbMock.On("GetLatestBlockPoint").Return((*BlockPoint)(nil), error(nil)).Once()
+	dbMock.On("OpenTx").Once()
+	dbMock.Writter.On("AddTxOutputs", snapshot.TxOutputs[1:]).Once()
+	dbMock.Writter.On("SetLatestBlockPoint", &snapshot.BlockPoint).Once()
+	dbMock.Writter.On("Execute").Return(error(nil)).Once()
+
+	require.NoError(t, blockIndexer.Bootstrap(snapshot))
+
+	dbMock.On("GetLatestBlockPoint").Return(&snapshot.BlockPoint, error(nil)).Once()
+
+	require.ErrorContains(t, blockIndexer.Bootstrap(snapshot), "already initialized")
+
+	dbMock.AssertExpectations(t)
+	dbMock.Writter.AssertExpectations(t)
+}
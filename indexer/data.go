@@ -11,6 +11,9 @@ import (
 
 var (
 	ErrBlockIndexerFatal = errors.New("block indexer fatal error")
+	// ErrNoAddressesOfInterest is returned if the update would remove all the address filters,
+	// because without them every address is of interest
+	ErrNoAddressesOfInterest = errors.New("all addresses of interest can not be removed")
 )
 
 const HashSize = 32
@@ -39,6 +42,7 @@ type Tx struct {
 	Outputs   []*TxOutput      `json:"out"`
 	Fee       uint64           `json:"fee"`
 	Valid     bool             `json:"valid"`
+	Mint      []MintedToken    `json:"mint,omitempty"`
 }
 
 type TxInput struct {
@@ -52,6 +56,13 @@ type TokenAmount struct {
 	Amount   uint64 `json:"amnt"`
 }
 
+// MintedToken is token minted (positive amount) or burned (negative amount) by the tx
+type MintedToken struct {
+	PolicyID string `json:"polid"`
+	Name     string `json:"name"`
+	Amount   int64  `json:"amnt"`
+}
+
 type TxOutput struct {
 	Address   string        `json:"addr"`
 	Slot      uint64        `json:"slot"`
@@ -60,6 +71,8 @@ type TxOutput struct {
 	DatumHash Hash          `json:"datumHash,omitempty"`
 	IsUsed    bool          `json:"used"`
 	Tokens    []TokenAmount `json:"assets,omitempty"`
+	// ScriptRef is cbor of the reference script ([type, script]) attached to the output
+	ScriptRef []byte `json:"scriptRef,omitempty"`
 }
 
 type TxInputOutput struct {
@@ -75,6 +88,17 @@ type CardanoBlock struct {
 	Txs    []Hash `json:"txs"`
 }
 
+// UndoRecord contains changes made to the database by a single confirmed block, so the block can be reverted
+type UndoRecord struct {
+	BlockPoint     BlockPoint  `json:"block"`
+	PrevBlockPoint *BlockPoint `json:"prev"`
+	// TxIndexes are indexes of the confirmed txs saved for the block
+	TxIndexes      []uint32  `json:"txs,omitempty"`
+	AddedTxOutputs []TxInput `json:"added,omitempty"`
+	// RemovedTxOutputs are outputs (with the values before the block) removed or soft deleted by the block
+	RemovedTxOutputs []*TxInputOutput `json:"removed,omitempty"`
+}
+
 type TxInfo struct {
 	Hash     string      `json:"hash"`
 	MetaData []byte      `json:"md"`
@@ -97,6 +121,22 @@ func (e *processConfirmedBlockError) Unwrap() error {
 	return e.err
 }
 
+// AddressesBackfillError is returned if the addresses of interest are persisted but their backfill failed.
+// Addresses stay of interest, so the backfill of them should be retried
+type AddressesBackfillError struct {
+	FromSlot  uint64
+	Addresses []string
+	Err       error
+}
+
+func (e *AddressesBackfillError) Error() string {
+	return fmt.Sprintf("backfill of %d addresses from slot %d failed: %v", len(e.Addresses), e.FromSlot, e.Err)
+}
+
+func (e *AddressesBackfillError) Unwrap() error {
+	return e.Err
+}
+
 func (h Hash) String() string {
 	return hex.EncodeToString(h[:])
 }
@@ -179,6 +219,18 @@ func (tx *Tx) String() string {
 	sb.WriteString("\noutputs = ")
 	sb.WriteString(sbOut.String())
 
+	if len(tx.Mint) > 0 {
+		sb.WriteString("\nmint = ")
+
+		for i, x := range tx.Mint {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+
+			sb.WriteString(x.String())
+		}
+	}
+
 	return sb.String()
 }
 
@@ -243,6 +295,14 @@ func (tt *TokenAmount) String() string {
 	return fmt.Sprintf("%d %s.%s", tt.Amount, tt.PolicyID, hex.EncodeToString([]byte(tt.Name)))
 }
 
+func (mt *MintedToken) TokenName() string {
+	return fmt.Sprintf("%s.%s", mt.PolicyID, hex.EncodeToString([]byte(mt.Name)))
+}
+
+func (mt *MintedToken) String() string {
+	return fmt.Sprintf("%d %s.%s", mt.Amount, mt.PolicyID, hex.EncodeToString([]byte(mt.Name)))
+}
+
 func (header BlockHeader) ToCardanoBlock(txs []Hash) *CardanoBlock {
 	return &CardanoBlock{
 		Slot:   header.Slot,
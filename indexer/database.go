@@ -1,5 +1,7 @@
 package indexer
 
+import "io"
+
 type DBTransactionWriter interface {
 	SetLatestBlockPoint(point *BlockPoint) DBTransactionWriter
 	AddTxOutputs(txOutputs []*TxInputOutput) DBTransactionWriter
@@ -7,6 +9,12 @@ type DBTransactionWriter interface {
 	AddConfirmedTxs(txs []*Tx) DBTransactionWriter
 	RemoveTxOutputs(txInputs []TxInput, softDelete bool) DBTransactionWriter
 	DeleteAllTxOutputsPhysically() DBTransactionWriter
+	// AddUndoRecord adds record to the undo journal and removes the oldest records so at most maxDepth are kept
+	AddUndoRecord(record *UndoRecord, maxDepth uint) DBTransactionWriter
+	// RevertUndoRecord reverts all the changes of the block and removes its record from the undo journal
+	RevertUndoRecord(record *UndoRecord) DBTransactionWriter
+	// SetAddressesOfInterest persists the whole set of addresses of interest
+	SetAddressesOfInterest(addresses []string) DBTransactionWriter
 	Execute() error
 }
 
@@ -17,6 +25,10 @@ type TxOutputRetriever interface {
 type BlockIndexerDB interface {
 	TxOutputRetriever
 	GetLatestBlockPoint() (*BlockPoint, error)
+	// GetAddressesOfInterest returns persisted addresses of interest or nil if they have never been persisted
+	GetAddressesOfInterest() ([]string, error)
+	// GetUndoRecords returns undo records of the blocks with slot greater than the given one (newest first)
+	GetUndoRecords(afterSlot uint64) ([]*UndoRecord, error)
 	OpenTx() DBTransactionWriter
 }
 
@@ -30,4 +42,20 @@ type Database interface {
 	GetLatestConfirmedBlocks(maxCnt int) ([]*CardanoBlock, error)
 	GetConfirmedBlocksFrom(slotNumber uint64, maxCnt int) ([]*CardanoBlock, error)
 	GetAllTxOutputs(address string, onlyNotUsed bool) ([]*TxInputOutput, error)
+	// GetTxByHash returns confirmed tx (processed or not) with the given hash or nil if it does not exist
+	GetTxByHash(hash Hash) (*Tx, error)
+	// GetConfirmedBlockTxs returns all the saved txs of the confirmed block ordered by the index in the block
+	GetConfirmedBlockTxs(blockSlot uint64) ([]*Tx, error)
+
+	// prune methods delete at most maxCnt entries with slot lower than the given one and return number of deleted
+	PruneConfirmedBlocks(slotNumber uint64, maxCnt int) (int, error)
+	PruneProcessedTxs(slotNumber uint64, maxCnt int) (int, error)
+	// PruneUsedTxOutputs deletes soft deleted outputs created (not spent) before the slot, because the spend slot
+	// is not stored. It can delete less than maxCnt even if there are more of them (bbolt visits at most maxCnt outputs)
+	PruneUsedTxOutputs(slotNumber uint64, maxCnt int) (int, error)
+
+	// Export streams the whole database state in the backend agnostic format (see ExportWriter)
+	Export(w io.Writer) error
+	// Import restores the state exported by any backend into the empty database
+	Import(r io.Reader) error
 }
@@ -1,25 +1,41 @@
 package indexerbbolt
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sync"
 
 	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/fxamacker/cbor/v2"
 	"go.etcd.io/bbolt"
 )
 
 type BBoltDatabase struct {
 	db *bbolt.DB
+	// pruneOutputsKey is the tx outputs key from which the next used outputs prune batch continues the scan
+	pruneOutputsKey  []byte
+	pruneOutputsLock sync.Mutex
 }
 
 var (
-	txOutputsBucket        = []byte("TXOuts")
-	latestBlockPointBucket = []byte("LatestBlockPoint")
-	processedTxsBucket     = []byte("ProcessedTxs")
-	unprocessedTxsBucket   = []byte("UnprocessedTxs")
-	confirmedBlocks        = []byte("confirmedBlocks")
+	txOutputsBucket = []byte("TXOuts")
+	// secondary index: (address, tx input) => empty value
+	txOutputsByAddressBucket = []byte("TXOutsByAddress")
+	latestBlockPointBucket   = []byte("LatestBlockPoint")
+	processedTxsBucket       = []byte("ProcessedTxs")
+	unprocessedTxsBucket     = []byte("UnprocessedTxs")
+	confirmedBlocks          = []byte("confirmedBlocks")
+	undoJournalBucket        = []byte("UndoJournal")
+	// secondary index: tx hash => key of the tx in the processed or unprocessed txs bucket
+	txsByHashBucket = []byte("TxsByHash")
 
 	defaultKey = []byte("default")
+	// addresses of interest are kept in the latest block point bucket
+	addressesOfInterestKey = []byte("addressesOfInterest")
 )
 
 var _ core.Database = (*BBoltDatabase)(nil)
@@ -32,8 +48,18 @@ func (bd *BBoltDatabase) Init(filePath string) error {
 
 	bd.db = db
 
-	return db.Update(func(tx *bbolt.Tx) error {
+	err = db.Update(func(tx *bbolt.Tx) error {
+		// index buckets do not exist in databases created before the indexes were introduced
+		var newIndexes [][]byte
+
+		for _, bn := range [][]byte{txOutputsByAddressBucket, txsByHashBucket} {
+			if tx.Bucket(bn) == nil {
+				newIndexes = append(newIndexes, bn)
+			}
+		}
+
 		for _, bn := range [][]byte{
+			txOutputsByAddressBucket, undoJournalBucket, txsByHashBucket,
 			txOutputsBucket, latestBlockPointBucket, processedTxsBucket, unprocessedTxsBucket, confirmedBlocks,
 		} {
 			_, err := tx.CreateBucketIfNotExists(bn)
@@ -42,8 +68,24 @@ func (bd *BBoltDatabase) Init(filePath string) error {
 			}
 		}
 
+		// indexes are filled later in batches, the markers are persisted so the interrupted migration is resumed
+		for _, migration := range indexMigrations {
+			if !slices.ContainsFunc(newIndexes, func(bn []byte) bool { return bytes.Equal(bn, migration.index) }) {
+				continue
+			}
+
+			if err := tx.Bucket(latestBlockPointBucket).Put(migration.markerKey(), []byte{0}); err != nil {
+				return fmt.Errorf("could not start %s index migration: %w", string(migration.index), err)
+			}
+		}
+
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	return runIndexMigrations(db)
 }
 
 func (bd *BBoltDatabase) Close() error {
@@ -66,10 +108,30 @@ func (bd *BBoltDatabase) GetLatestBlockPoint() (*core.BlockPoint, error) {
 	return result, nil
 }
 
+func (bd *BBoltDatabase) GetAddressesOfInterest() ([]string, error) {
+	var result []string
+
+	if err := bd.db.View(func(tx *bbolt.Tx) error {
+		if data := tx.Bucket(latestBlockPointBucket).Get(addressesOfInterestKey); data != nil {
+			result = []string{}
+
+			return json.Unmarshal(data, &result)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (bd *BBoltDatabase) GetTxOutput(txInput core.TxInput) (result core.TxOutput, err error) {
 	err = bd.db.View(func(tx *bbolt.Tx) error {
 		if data := tx.Bucket(txOutputsBucket).Get(txInput.Key()); len(data) > 0 {
-			return json.Unmarshal(data, &result)
+			result, err = unmarshalTxOutput(data)
+
+			return err
 		}
 
 		return nil
@@ -85,7 +147,7 @@ func (bd *BBoltDatabase) MarkConfirmedTxsProcessed(txs []*core.Tx) error {
 				return fmt.Errorf("could not remove from unprocessed blocks: %w", err)
 			}
 
-			bytes, err := json.Marshal(cardTx)
+			bytes, err := marshalTx(cardTx)
 			if err != nil {
 				return fmt.Errorf("could not marshal block: %w", err)
 			}
@@ -106,9 +168,8 @@ func (bd *BBoltDatabase) GetUnprocessedConfirmedTxs(maxCnt int) ([]*core.Tx, err
 		cursor := tx.Bucket(unprocessedTxsBucket).Cursor()
 
 		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
-			var cardTx *core.Tx
-
-			if err := json.Unmarshal(v, &cardTx); err != nil {
+			cardTx, err := unmarshalTx(v)
+			if err != nil {
 				return err
 			}
 
@@ -134,9 +195,8 @@ func (bd *BBoltDatabase) GetLatestConfirmedBlocks(maxCnt int) ([]*core.CardanoBl
 		cursor := tx.Bucket(confirmedBlocks).Cursor()
 
 		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
-			var block *core.CardanoBlock
-
-			if err := json.Unmarshal(v, &block); err != nil {
+			block, err := unmarshalCardanoBlock(v)
+			if err != nil {
 				return err
 			}
 
@@ -162,9 +222,8 @@ func (bd *BBoltDatabase) GetConfirmedBlocksFrom(slotNumber uint64, maxCnt int) (
 		cursor := tx.Bucket(confirmedBlocks).Cursor()
 
 		for k, v := cursor.Seek(core.SlotNumberToKey(slotNumber)); k != nil; k, v = cursor.Next() {
-			var block *core.CardanoBlock
-
-			if err := json.Unmarshal(v, &block); err != nil {
+			block, err := unmarshalCardanoBlock(v)
+			if err != nil {
 				return err
 			}
 
@@ -187,23 +246,33 @@ func (bd *BBoltDatabase) GetAllTxOutputs(address string, onlyNotUsed bool) ([]*c
 	var result []*core.TxInputOutput
 
 	err := bd.db.View(func(tx *bbolt.Tx) error {
-		cursor := tx.Bucket(txOutputsBucket).Cursor()
+		outputsBucket := tx.Bucket(txOutputsBucket)
+		prefix := addressIndexPrefix(address)
+		cursor := tx.Bucket(txOutputsByAddressBucket).Cursor()
 
-		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
 			var item core.TxInputOutput
 
-			if err := json.Unmarshal(v, &item.Output); err != nil {
+			if err := item.Input.Set(k[len(prefix):]); err != nil {
 				return err
 			}
 
-			if item.Output.Address != address || (onlyNotUsed && item.Output.IsUsed) {
+			data := outputsBucket.Get(item.Input.Key())
+			if len(data) == 0 {
 				continue
 			}
 
-			if err := item.Input.Set(k); err != nil {
+			output, err := unmarshalTxOutput(data)
+			if err != nil {
 				return err
 			}
 
+			item.Output = output
+
+			if onlyNotUsed && item.Output.IsUsed {
+				continue
+			}
+
 			result = append(result, &item)
 		}
 
@@ -216,8 +285,274 @@ func (bd *BBoltDatabase) GetAllTxOutputs(address string, onlyNotUsed bool) ([]*c
 	return core.SortTxInputOutputs(result), nil
 }
 
+func (bd *BBoltDatabase) GetTxByHash(hash core.Hash) (*core.Tx, error) {
+	var result *core.Tx
+
+	err := bd.db.View(func(tx *bbolt.Tx) (err error) {
+		key := tx.Bucket(txsByHashBucket).Get(hash[:])
+		if key == nil {
+			return nil
+		}
+
+		for _, bn := range [][]byte{unprocessedTxsBucket, processedTxsBucket} {
+			if data := tx.Bucket(bn).Get(key); len(data) > 0 {
+				result, err = unmarshalTx(data)
+
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (bd *BBoltDatabase) GetConfirmedBlockTxs(blockSlot uint64) ([]*core.Tx, error) {
+	var result []*core.Tx
+
+	err := bd.db.View(func(tx *bbolt.Tx) error {
+		prefix := core.SlotNumberToKey(blockSlot)
+
+		for _, bn := range [][]byte{unprocessedTxsBucket, processedTxsBucket} {
+			cursor := tx.Bucket(bn).Cursor()
+
+			for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+				cardTx, err := unmarshalTx(v)
+				if err != nil {
+					return err
+				}
+
+				result = append(result, cardTx)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// block txs can be partially processed so txs from both buckets must be ordered
+	slices.SortFunc(result, func(a, b *core.Tx) int {
+		return cmp.Compare(a.Indx, b.Indx)
+	})
+
+	return result, nil
+}
+
+func (bd *BBoltDatabase) GetUndoRecords(afterSlot uint64) ([]*core.UndoRecord, error) {
+	var result []*core.UndoRecord
+
+	err := bd.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(undoJournalBucket).Cursor()
+		startKey := core.SlotNumberToKey(afterSlot)
+
+		for k, v := cursor.Last(); k != nil && bytes.Compare(k, startKey) > 0; k, v = cursor.Prev() {
+			var record *core.UndoRecord
+
+			if err := cbor.Unmarshal(v, &record); err != nil {
+				return err
+			}
+
+			result = append(result, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (bd *BBoltDatabase) PruneConfirmedBlocks(slotNumber uint64, maxCnt int) (int, error) {
+	return bd.pruneBySlotKey(confirmedBlocks, slotNumber, maxCnt, nil)
+}
+
+func (bd *BBoltDatabase) PruneProcessedTxs(slotNumber uint64, maxCnt int) (int, error) {
+	return bd.pruneBySlotKey(processedTxsBucket, slotNumber, maxCnt, deleteTxHashIndex)
+}
+
+func (bd *BBoltDatabase) PruneUsedTxOutputs(slotNumber uint64, maxCnt int) (int, error) {
+	var (
+		itemsToDelete []*core.TxInputOutput
+		nextKey       []byte
+	)
+
+	bd.pruneOutputsLock.Lock()
+	defer bd.pruneOutputsLock.Unlock()
+
+	// used outputs are found in the read transaction so writers are not blocked during the bucket scan.
+	// At most maxCnt outputs are visited, so there is no full scan if there is nothing to prune.
+	// The scan continues where the previous batch stopped and starts from the beginning after reaching the end
+	err := bd.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(txOutputsBucket).Cursor()
+		visited := 0
+
+		k, v := cursor.First()
+		if bd.pruneOutputsKey != nil {
+			k, v = cursor.Seek(bd.pruneOutputsKey)
+		}
+
+		for ; k != nil && (maxCnt <= 0 || visited < maxCnt); k, v = cursor.Next() {
+			visited++
+
+			output, err := unmarshalTxOutput(v)
+			if err != nil {
+				return err
+			}
+
+			if !output.IsUsed || output.Slot >= slotNumber {
+				continue
+			}
+
+			item := &core.TxInputOutput{Output: output}
+			if err := item.Input.Set(k); err != nil {
+				return err
+			}
+
+			itemsToDelete = append(itemsToDelete, item)
+		}
+
+		nextKey = bytes.Clone(k)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(itemsToDelete) == 0 {
+		bd.pruneOutputsKey = nextKey
+
+		return 0, nil
+	}
+
+	err = bd.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(txOutputsBucket)
+		indexBucket := tx.Bucket(txOutputsByAddressBucket)
+
+		for _, item := range itemsToDelete {
+			if err := bucket.Delete(item.Input.Key()); err != nil {
+				return err
+			}
+
+			if err := indexBucket.Delete(addressIndexKey(item.Output.Address, item.Input)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	bd.pruneOutputsKey = nextKey
+
+	return len(itemsToDelete), nil
+}
+
+// pruneBySlotKey deletes entries from the bucket which keys start with the slot number lower than the given one.
+// onDelete (if set) is called for each deleted entry so the secondary indexes can be updated
+func (bd *BBoltDatabase) pruneBySlotKey(
+	bucketName []byte, slotNumber uint64, maxCnt int, onDelete func(tx *bbolt.Tx, value []byte) error,
+) (cnt int, err error) {
+	endKey := core.SlotNumberToKey(slotNumber)
+
+	err = bd.db.Update(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(bucketName).Cursor()
+
+		for k, v := cursor.First(); k != nil && bytes.Compare(k, endKey) < 0; k, v = cursor.First() {
+			if maxCnt > 0 && cnt == maxCnt {
+				break
+			}
+
+			if onDelete != nil {
+				if err := onDelete(tx, v); err != nil {
+					return err
+				}
+			}
+
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+
+			cnt++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return cnt, nil
+}
+
 func (bd *BBoltDatabase) OpenTx() core.DBTransactionWriter {
 	return &BBoltTransactionWriter{
 		db: bd.db,
 	}
 }
+
+// addressIndexPrefix returns length prefixed address so one address can not be a prefix of another one
+func addressIndexPrefix(address string) []byte {
+	prefix := make([]byte, 2+len(address))
+
+	binary.BigEndian.PutUint16(prefix, uint16(len(address))) //nolint:gosec
+	copy(prefix[2:], address)
+
+	return prefix
+}
+
+func addressIndexKey(address string, txInput core.TxInput) []byte {
+	return append(addressIndexPrefix(address), txInput.Key()...)
+}
+
+// indexTxOutputRecord adds the tx output record to the address index
+func indexTxOutputRecord(tx *bbolt.Tx, k, v []byte) error {
+	var txInput core.TxInput
+
+	if err := txInput.Set(k); err != nil {
+		return err
+	}
+
+	txOutput, err := unmarshalTxOutput(v)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(txOutputsByAddressBucket).Put(addressIndexKey(txOutput.Address, txInput), []byte{})
+}
+
+// indexTxRecord adds the processed or unprocessed tx record to the tx hash index
+func indexTxRecord(tx *bbolt.Tx, k, v []byte) error {
+	cardTx, err := unmarshalTx(v)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(txsByHashBucket).Put(cardTx.Hash[:], bytes.Clone(k))
+}
+
+// deleteTxHashIndex deletes tx hash index entry of the tx, if the entry still points to that tx
+func deleteTxHashIndex(tx *bbolt.Tx, txData []byte) error {
+	cardTx, err := unmarshalTx(txData)
+	if err != nil {
+		return err
+	}
+
+	indexBucket := tx.Bucket(txsByHashBucket)
+
+	if key := indexBucket.Get(cardTx.Hash[:]); !bytes.Equal(key, cardTx.Key()) {
+		return nil
+	}
+
+	return indexBucket.Delete(cardTx.Hash[:])
+}
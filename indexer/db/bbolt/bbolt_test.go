@@ -6,6 +6,7 @@ import (
 
 	indexer "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
 	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
 )
 
 func TestDatabase(t *testing.T) {
@@ -94,6 +95,31 @@ func TestDatabase(t *testing.T) {
 		require.EqualValues(t, blockPoint2, blockPoint)
 	})
 
+	t.Run("GetAddressesOfInterest", func(t *testing.T) {
+		t.Cleanup(dbCleanup)
+
+		db := &BBoltDatabase{}
+		err := db.Init(filePath)
+		require.NoError(t, err)
+
+		addresses, err := db.GetAddressesOfInterest()
+		require.NoError(t, err)
+		require.Nil(t, addresses)
+
+		require.NoError(t, db.OpenTx().SetAddressesOfInterest([]string{"addr1", "addr2"}).Execute())
+
+		addresses, err = db.GetAddressesOfInterest()
+		require.NoError(t, err)
+		require.Equal(t, []string{"addr1", "addr2"}, addresses)
+
+		require.NoError(t, db.OpenTx().SetAddressesOfInterest(nil).Execute())
+
+		addresses, err = db.GetAddressesOfInterest()
+		require.NoError(t, err)
+		require.NotNil(t, addresses)
+		require.Empty(t, addresses)
+	})
+
 	t.Run("GetTxOutputNil", func(t *testing.T) {
 		t.Cleanup(dbCleanup)
 
@@ -409,6 +435,324 @@ func TestDatabase(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, []*indexer.TxInputOutput{good1, good2, good3, good4}, result)
 	})
+
+	t.Run("GetAllTxOutputsAddressIndex", func(t *testing.T) {
+		t.Cleanup(dbCleanup)
+
+		txOutputs := []*indexer.TxInputOutput{
+			{
+				Input:  indexer.TxInput{Hash: indexer.Hash{1}, Index: 0},
+				Output: indexer.TxOutput{Address: "addr", Amount: 100},
+			},
+			{
+				Input:  indexer.TxInput{Hash: indexer.Hash{2}, Index: 1},
+				Output: indexer.TxOutput{Address: "addr", Amount: 200},
+			},
+			{
+				// address with the same prefix
+				Input:  indexer.TxInput{Hash: indexer.Hash{3}, Index: 0},
+				Output: indexer.TxOutput{Address: "addr2", Amount: 300},
+			},
+		}
+		db := &BBoltDatabase{}
+
+		require.NoError(t, db.Init(filePath))
+		require.NoError(t, db.OpenTx().AddTxOutputs(txOutputs).Execute())
+
+		// remove index bucket to simulate database created before the address index
+		require.NoError(t, db.db.Update(func(tx *bbolt.Tx) error {
+			return tx.DeleteBucket(txOutputsByAddressBucket)
+		}))
+		require.NoError(t, db.Close())
+		require.NoError(t, db.Init(filePath))
+
+		result, err := db.GetAllTxOutputs("addr", false)
+		require.NoError(t, err)
+		require.Equal(t, txOutputs[:2], result)
+
+		require.NoError(t, db.OpenTx().RemoveTxOutputs([]indexer.TxInput{txOutputs[0].Input}, false).Execute())
+
+		result, err = db.GetAllTxOutputs("addr", false)
+		require.NoError(t, err)
+		require.Equal(t, txOutputs[1:2], result)
+
+		require.NoError(t, db.db.View(func(tx *bbolt.Tx) error {
+			require.Equal(t, 2, tx.Bucket(txOutputsByAddressBucket).Stats().KeyN)
+
+			return nil
+		}))
+
+		result, err = db.GetAllTxOutputs("addr2", false)
+		require.NoError(t, err)
+		require.Equal(t, txOutputs[2:], result)
+
+		// interrupted migration is resumed from the key persisted in the marker
+		migration := indexMigrations[0]
+
+		require.NoError(t, db.db.Update(func(tx *bbolt.Tx) error {
+			if err := tx.DeleteBucket(txOutputsByAddressBucket); err != nil {
+				return err
+			}
+
+			if _, err := tx.CreateBucket(txOutputsByAddressBucket); err != nil {
+				return err
+			}
+
+			return tx.Bucket(latestBlockPointBucket).Put(
+				migration.markerKey(), append([]byte{0}, txOutputs[2].Input.Key()...))
+		}))
+		require.NoError(t, db.Close())
+		require.NoError(t, db.Init(filePath))
+
+		// outputs before the marker key were indexed by the interrupted run (here deleted with the bucket)
+		result, err = db.GetAllTxOutputs("addr", false)
+		require.NoError(t, err)
+		require.Empty(t, result)
+
+		result, err = db.GetAllTxOutputs("addr2", false)
+		require.NoError(t, err)
+		require.Equal(t, txOutputs[2:], result)
+
+		require.NoError(t, db.db.View(func(tx *bbolt.Tx) error {
+			require.Nil(t, tx.Bucket(latestBlockPointBucket).Get(migration.markerKey()))
+
+			return nil
+		}))
+
+		require.NoError(t, db.OpenTx().DeleteAllTxOutputsPhysically().Execute())
+
+		result, err = db.GetAllTxOutputs("addr2", false)
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("GetTxByHash", func(t *testing.T) {
+		t.Cleanup(dbCleanup)
+
+		txs := []*indexer.Tx{
+			{BlockSlot: 1, Indx: 1, Hash: indexer.Hash{2}},
+			{BlockSlot: 1, Indx: 0, Hash: indexer.Hash{1}},
+			{BlockSlot: 3, Indx: 0, Hash: indexer.Hash{3}},
+		}
+		db := &BBoltDatabase{}
+
+		require.NoError(t, db.Init(filePath))
+		require.NoError(t, db.OpenTx().AddConfirmedTxs(txs).Execute())
+		require.NoError(t, db.MarkConfirmedTxsProcessed(txs[:1]))
+
+		// remove index bucket to simulate database created before the tx hash index
+		require.NoError(t, db.db.Update(func(tx *bbolt.Tx) error {
+			return tx.DeleteBucket(txsByHashBucket)
+		}))
+		require.NoError(t, db.Close())
+		require.NoError(t, db.Init(filePath))
+
+		for _, cardTx := range txs {
+			result, err := db.GetTxByHash(cardTx.Hash)
+			require.NoError(t, err)
+			require.Equal(t, cardTx, result)
+		}
+
+		result, err := db.GetTxByHash(indexer.Hash{4})
+		require.NoError(t, err)
+		require.Nil(t, result)
+
+		blockTxs, err := db.GetConfirmedBlockTxs(1)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.Tx{txs[1], txs[0]}, blockTxs)
+
+		blockTxs, err = db.GetConfirmedBlockTxs(2)
+		require.NoError(t, err)
+		require.Empty(t, blockTxs)
+
+		cnt, err := db.PruneProcessedTxs(2, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+
+		result, err = db.GetTxByHash(txs[0].Hash)
+		require.NoError(t, err)
+		require.Nil(t, result)
+
+		require.NoError(t, db.OpenTx().RevertUndoRecord(&indexer.UndoRecord{
+			BlockPoint: indexer.BlockPoint{BlockSlot: 3}, TxIndexes: []uint32{0},
+		}).Execute())
+
+		result, err = db.GetTxByHash(txs[2].Hash)
+		require.NoError(t, err)
+		require.Nil(t, result)
+
+		require.NoError(t, db.db.View(func(tx *bbolt.Tx) error {
+			require.Equal(t, 1, tx.Bucket(txsByHashBucket).Stats().KeyN)
+
+			return nil
+		}))
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		t.Cleanup(dbCleanup)
+
+		db := &BBoltDatabase{}
+		require.NoError(t, db.Init(filePath))
+
+		txs := []*indexer.Tx{{BlockSlot: 1, Indx: 0}, {BlockSlot: 1, Indx: 1}, {BlockSlot: 5}, {BlockSlot: 6}}
+		txOutputs := []*indexer.TxInputOutput{
+			{Input: indexer.TxInput{Hash: indexer.Hash{1}}, Output: indexer.TxOutput{Address: "a", Slot: 1}},
+			{Input: indexer.TxInput{Hash: indexer.Hash{2}}, Output: indexer.TxOutput{Address: "a", Slot: 2}},
+			{Input: indexer.TxInput{Hash: indexer.Hash{3}}, Output: indexer.TxOutput{Address: "a", Slot: 8}},
+		}
+		dbTx := db.OpenTx().AddConfirmedTxs(txs).AddTxOutputs(txOutputs)
+
+		for _, slot := range []uint64{1, 2, 5, 6} {
+			dbTx.AddConfirmedBlock(&indexer.CardanoBlock{Slot: slot})
+		}
+
+		require.NoError(t, dbTx.Execute())
+		require.NoError(t, db.MarkConfirmedTxsProcessed(txs[:3]))
+		require.NoError(t, db.OpenTx().RemoveTxOutputs([]indexer.TxInput{
+			txOutputs[0].Input, txOutputs[2].Input,
+		}, true).Execute())
+
+		cnt, err := db.PruneConfirmedBlocks(5, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+
+		cnt, err = db.PruneConfirmedBlocks(5, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+
+		blocks, err := db.GetConfirmedBlocksFrom(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.CardanoBlock{{Slot: 5}, {Slot: 6}}, blocks)
+
+		cnt, err = db.PruneProcessedTxs(6, 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, cnt)
+
+		unprocessedTxs, err := db.GetUnprocessedConfirmedTxs(0)
+		require.NoError(t, err)
+		require.Equal(t, txs[3:], unprocessedTxs)
+
+		// only used outputs are pruned
+		cnt, err = db.PruneUsedTxOutputs(5, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+		require.Equal(t, txOutputs[1].Input.Key(), db.pruneOutputsKey)
+
+		outputs, err := db.GetAllTxOutputs("a", false)
+		require.NoError(t, err)
+		require.Len(t, outputs, 2)
+		require.Equal(t, txOutputs[1], outputs[0])
+
+		// at most maxCnt outputs are visited -> not used output is skipped and nothing is pruned
+		cnt, err = db.PruneUsedTxOutputs(9, 1)
+		require.NoError(t, err)
+		require.Equal(t, 0, cnt)
+		require.Equal(t, txOutputs[2].Input.Key(), db.pruneOutputsKey)
+
+		// next batch continues the scan after the last visited key and the scan restarts after reaching the end.
+		// Output is pruned by its creation slot (8) regardless of when it was spent
+		cnt, err = db.PruneUsedTxOutputs(9, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+		require.Nil(t, db.pruneOutputsKey)
+
+		outputs, err = db.GetAllTxOutputs("a", false)
+		require.NoError(t, err)
+		require.Equal(t, txOutputs[1:2], outputs)
+	})
+
+	t.Run("UndoJournal", func(t *testing.T) {
+		t.Cleanup(dbCleanup)
+
+		db := &BBoltDatabase{}
+		require.NoError(t, db.Init(filePath))
+
+		outputA := &indexer.TxInputOutput{
+			Input: indexer.TxInput{Hash: indexer.Hash{1}}, Output: indexer.TxOutput{Address: "a", Amount: 1, Slot: 10},
+		}
+		outputB := &indexer.TxInputOutput{
+			Input: indexer.TxInput{Hash: indexer.Hash{2}}, Output: indexer.TxOutput{Address: "a", Amount: 2, Slot: 20},
+		}
+		points := []*indexer.BlockPoint{
+			{BlockSlot: 10, BlockHash: indexer.Hash{10}},
+			{BlockSlot: 20, BlockHash: indexer.Hash{20}},
+			{BlockSlot: 30, BlockHash: indexer.Hash{30}},
+		}
+		records := []*indexer.UndoRecord{
+			{
+				BlockPoint:     *points[0],
+				PrevBlockPoint: &indexer.BlockPoint{},
+				TxIndexes:      []uint32{0},
+				AddedTxOutputs: []indexer.TxInput{outputA.Input},
+			},
+			{
+				BlockPoint:       *points[1],
+				PrevBlockPoint:   points[0],
+				TxIndexes:        []uint32{0},
+				AddedTxOutputs:   []indexer.TxInput{outputB.Input},
+				RemovedTxOutputs: []*indexer.TxInputOutput{outputA},
+			},
+			{
+				BlockPoint:     *points[2],
+				PrevBlockPoint: points[1],
+			},
+		}
+
+		require.NoError(t, db.OpenTx().
+			AddTxOutputs([]*indexer.TxInputOutput{outputA}).
+			AddConfirmedTxs([]*indexer.Tx{{BlockSlot: 10}}).
+			AddConfirmedBlock(&indexer.CardanoBlock{Slot: 10}).
+			SetLatestBlockPoint(points[0]).
+			AddUndoRecord(records[0], 2).
+			Execute())
+		require.NoError(t, db.OpenTx().
+			RemoveTxOutputs([]indexer.TxInput{outputA.Input}, true).
+			AddTxOutputs([]*indexer.TxInputOutput{outputB}).
+			AddConfirmedTxs([]*indexer.Tx{{BlockSlot: 20}}).
+			AddConfirmedBlock(&indexer.CardanoBlock{Slot: 20}).
+			SetLatestBlockPoint(points[1]).
+			AddUndoRecord(records[1], 2).
+			Execute())
+		require.NoError(t, db.MarkConfirmedTxsProcessed([]*indexer.Tx{{BlockSlot: 20}}))
+		require.NoError(t, db.OpenTx().
+			AddConfirmedBlock(&indexer.CardanoBlock{Slot: 30}).
+			SetLatestBlockPoint(points[2]).
+			AddUndoRecord(records[2], 2).
+			Execute())
+
+		// the oldest record is removed because of the depth
+		undoRecords, err := db.GetUndoRecords(0)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.UndoRecord{records[2], records[1]}, undoRecords)
+
+		undoRecords, err = db.GetUndoRecords(20)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.UndoRecord{records[2]}, undoRecords)
+
+		require.NoError(t, db.OpenTx().RevertUndoRecord(records[2]).RevertUndoRecord(records[1]).Execute())
+
+		latestPoint, err := db.GetLatestBlockPoint()
+		require.NoError(t, err)
+		require.Equal(t, points[0], latestPoint)
+
+		outputs, err := db.GetAllTxOutputs("a", false)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.TxInputOutput{outputA}, outputs)
+
+		blocks, err := db.GetLatestConfirmedBlocks(0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		require.Equal(t, uint64(10), blocks[0].Slot)
+
+		txs, err := db.GetUnprocessedConfirmedTxs(0)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		require.Equal(t, uint64(10), txs[0].BlockSlot)
+
+		undoRecords, err = db.GetUndoRecords(0)
+		require.NoError(t, err)
+		require.Empty(t, undoRecords)
+	})
 }
 
 func removeDirOrFilePathIfExists(dirOrFilePath string) (err error) {
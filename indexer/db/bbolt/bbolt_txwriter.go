@@ -1,10 +1,12 @@
 package indexerbbolt
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
 	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/fxamacker/cbor/v2"
 
 	"go.etcd.io/bbolt"
 )
@@ -35,6 +37,27 @@ func (tw *BBoltTransactionWriter) SetLatestBlockPoint(point *core.BlockPoint) co
 	return tw
 }
 
+func (tw *BBoltTransactionWriter) SetAddressesOfInterest(addresses []string) core.DBTransactionWriter {
+	tw.operations = append(tw.operations, func(tx *bbolt.Tx) error {
+		if addresses == nil {
+			addresses = []string{}
+		}
+
+		bytes, err := json.Marshal(addresses)
+		if err != nil {
+			return fmt.Errorf("could not marshal addresses of interest: %w", err)
+		}
+
+		if err = tx.Bucket(latestBlockPointBucket).Put(addressesOfInterestKey, bytes); err != nil {
+			return fmt.Errorf("addresses of interest write error: %w", err)
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
 func (tw *BBoltTransactionWriter) AddTxOutputs(txOutputs []*core.TxInputOutput) core.DBTransactionWriter {
 	if len(txOutputs) == 0 {
 		return tw
@@ -42,9 +65,10 @@ func (tw *BBoltTransactionWriter) AddTxOutputs(txOutputs []*core.TxInputOutput)
 
 	tw.operations = append(tw.operations, func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket(txOutputsBucket)
+		indexBucket := tx.Bucket(txOutputsByAddressBucket)
 
 		for _, inpOut := range txOutputs {
-			bytes, err := json.Marshal(inpOut.Output)
+			bytes, err := marshalTxOutput(inpOut.Output)
 			if err != nil {
 				return fmt.Errorf("could not marshal tx output: %w", err)
 			}
@@ -52,6 +76,10 @@ func (tw *BBoltTransactionWriter) AddTxOutputs(txOutputs []*core.TxInputOutput)
 			if err = bucket.Put(inpOut.Input.Key(), bytes); err != nil {
 				return fmt.Errorf("tx output write error: %w", err)
 			}
+
+			if err = indexBucket.Put(addressIndexKey(inpOut.Output.Address, inpOut.Input), []byte{}); err != nil {
+				return fmt.Errorf("tx output address index write error: %w", err)
+			}
 		}
 
 		return nil
@@ -62,7 +90,7 @@ func (tw *BBoltTransactionWriter) AddTxOutputs(txOutputs []*core.TxInputOutput)
 
 func (tw *BBoltTransactionWriter) AddConfirmedBlock(block *core.CardanoBlock) core.DBTransactionWriter {
 	tw.operations = append(tw.operations, func(tx *bbolt.Tx) error {
-		bytes, err := json.Marshal(block)
+		bytes, err := marshalCardanoBlock(block)
 		if err != nil {
 			return fmt.Errorf("could not marshal confirmed block: %w", err)
 		}
@@ -84,7 +112,7 @@ func (tw *BBoltTransactionWriter) AddConfirmedTxs(txs []*core.Tx) core.DBTransac
 
 	tw.operations = append(tw.operations, func(tx *bbolt.Tx) error {
 		for _, cardTx := range txs {
-			bytes, err := json.Marshal(cardTx)
+			bytes, err := marshalTx(cardTx)
 			if err != nil {
 				return fmt.Errorf("could not marshal confirmed tx: %w", err)
 			}
@@ -92,6 +120,10 @@ func (tw *BBoltTransactionWriter) AddConfirmedTxs(txs []*core.Tx) core.DBTransac
 			if err = tx.Bucket(unprocessedTxsBucket).Put(cardTx.Key(), bytes); err != nil {
 				return fmt.Errorf("confirmed tx write error: %w", err)
 			}
+
+			if err = tx.Bucket(txsByHashBucket).Put(cardTx.Hash[:], cardTx.Key()); err != nil {
+				return fmt.Errorf("confirmed tx hash index write error: %w", err)
+			}
 		}
 
 		return nil
@@ -107,24 +139,33 @@ func (tw *BBoltTransactionWriter) RemoveTxOutputs(txInputs []core.TxInput, softD
 
 	tw.operations = append(tw.operations, func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket(txOutputsBucket)
+		indexBucket := tx.Bucket(txOutputsByAddressBucket)
 
 		for _, inp := range txInputs {
 			key := inp.Key()
 
+			data := bucket.Get(key)
+			if len(data) == 0 {
+				continue
+			}
+
+			result, err := unmarshalTxOutput(data)
+			if err != nil {
+				return fmt.Errorf("unmarshal utxo error: %w", err)
+			}
+
 			if !softDelete {
 				if err := bucket.Delete(key); err != nil {
 					return fmt.Errorf("delete utxo error: %w", err)
 				}
-			} else if data := bucket.Get(key); len(data) > 0 {
-				var result core.TxOutput
 
-				if err := json.Unmarshal(data, &result); err != nil {
-					return fmt.Errorf("soft delete unmarshal utxo error: %w", err)
+				if err := indexBucket.Delete(addressIndexKey(result.Address, inp)); err != nil {
+					return fmt.Errorf("delete utxo address index error: %w", err)
 				}
-
+			} else {
 				result.IsUsed = true
 
-				bytes, err := json.Marshal(result)
+				bytes, err := marshalTxOutput(result)
 				if err != nil {
 					return fmt.Errorf("soft delete marshal utxo error: %w", err)
 				}
@@ -143,18 +184,104 @@ func (tw *BBoltTransactionWriter) RemoveTxOutputs(txInputs []core.TxInput, softD
 
 func (tw *BBoltTransactionWriter) DeleteAllTxOutputsPhysically() core.DBTransactionWriter {
 	tw.operations = append(tw.operations, func(tx *bbolt.Tx) error {
-		if err := tx.DeleteBucket(txOutputsBucket); err != nil {
-			return err
+		for _, bn := range [][]byte{txOutputsBucket, txOutputsByAddressBucket} {
+			if err := tx.DeleteBucket(bn); err != nil {
+				return err
+			}
+
+			if _, err := tx.CreateBucket(bn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
+func (tw *BBoltTransactionWriter) AddUndoRecord(record *core.UndoRecord, maxDepth uint) core.DBTransactionWriter {
+	tw.operations = append(tw.operations, func(tx *bbolt.Tx) error {
+		data, err := cbor.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("could not marshal undo record: %w", err)
+		}
+
+		bucket := tx.Bucket(undoJournalBucket)
+
+		if err := bucket.Put(core.SlotNumberToKey(record.BlockPoint.BlockSlot), data); err != nil {
+			return fmt.Errorf("undo record write error: %w", err)
 		}
 
-		_, err := tx.CreateBucket(txOutputsBucket)
+		// skip the latest maxDepth records and remove all the older ones
+		cursor := bucket.Cursor()
+		k, _ := cursor.Last()
+
+		for i := uint(0); i < maxDepth && k != nil; i++ {
+			k, _ = cursor.Prev()
+		}
+
+		var keysToDelete [][]byte
+
+		for ; k != nil; k, _ = cursor.Prev() {
+			keysToDelete = append(keysToDelete, bytes.Clone(k))
+		}
 
-		return err
+		for _, key := range keysToDelete {
+			if err := bucket.Delete(key); err != nil {
+				return fmt.Errorf("undo record delete error: %w", err)
+			}
+		}
+
+		return nil
 	})
 
 	return tw
 }
 
+func (tw *BBoltTransactionWriter) RevertUndoRecord(record *core.UndoRecord) core.DBTransactionWriter {
+	tw.RemoveTxOutputs(record.AddedTxOutputs, false)
+	tw.AddTxOutputs(record.RemovedTxOutputs)
+
+	tw.operations = append(tw.operations, func(tx *bbolt.Tx) error {
+		blockKey := core.SlotNumberToKey(record.BlockPoint.BlockSlot)
+
+		if err := tx.Bucket(confirmedBlocks).Delete(blockKey); err != nil {
+			return fmt.Errorf("confirmed block delete error: %w", err)
+		}
+
+		for _, indx := range record.TxIndexes {
+			txKey := (&core.Tx{BlockSlot: record.BlockPoint.BlockSlot, Indx: indx}).Key()
+
+			for _, bn := range [][]byte{unprocessedTxsBucket, processedTxsBucket} {
+				bucket := tx.Bucket(bn)
+
+				if data := bucket.Get(txKey); len(data) > 0 {
+					if err := deleteTxHashIndex(tx, data); err != nil {
+						return fmt.Errorf("confirmed tx hash index delete error: %w", err)
+					}
+				}
+
+				if err := bucket.Delete(txKey); err != nil {
+					return fmt.Errorf("confirmed tx delete error: %w", err)
+				}
+			}
+		}
+
+		if err := tx.Bucket(undoJournalBucket).Delete(blockKey); err != nil {
+			return fmt.Errorf("undo record delete error: %w", err)
+		}
+
+		return nil
+	})
+
+	if record.PrevBlockPoint != nil {
+		tw.SetLatestBlockPoint(record.PrevBlockPoint)
+	}
+
+	return tw
+}
+
 func (tw *BBoltTransactionWriter) Execute() error {
 	defer func() {
 		tw.operations = nil
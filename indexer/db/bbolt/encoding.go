@@ -0,0 +1,363 @@
+package indexerbbolt
+
+import (
+	"encoding/json"
+	"fmt"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// recordVersionCBOR is the first byte of cbor encoded records.
+// Old records are json objects so they always start with '{' and can be distinguished from the new ones
+const recordVersionCBOR byte = 2
+
+// recordVersionCBORWithoutMint is the version of cbor records written before mint was added to the tx record
+const recordVersionCBORWithoutMint byte = 1
+
+// legacyRecord is implemented by the records whose layout differs between the cbor record versions
+type legacyRecord interface {
+	unmarshalLegacyCBOR(version byte, data []byte) error
+}
+
+type tokenAmountRecord struct {
+	_        struct{} `cbor:",toarray"`
+	PolicyID string
+	Name     string
+	Amount   uint64
+}
+
+type txOutputRecord struct {
+	_         struct{} `cbor:",toarray"`
+	Address   string
+	Slot      uint64
+	Amount    uint64
+	Datum     []byte
+	DatumHash core.Hash
+	IsUsed    bool
+	Tokens    []tokenAmountRecord
+	ScriptRef []byte
+}
+
+type txInputOutputRecord struct {
+	_      struct{} `cbor:",toarray"`
+	Hash   core.Hash
+	Index  uint32
+	Output txOutputRecord
+}
+
+type mintedTokenRecord struct {
+	_        struct{} `cbor:",toarray"`
+	PolicyID string
+	Name     string
+	Amount   int64
+}
+
+type txRecord struct {
+	_         struct{} `cbor:",toarray"`
+	BlockSlot uint64
+	BlockHash core.Hash
+	Indx      uint32
+	Hash      core.Hash
+	Metadata  []byte
+	Inputs    []txInputOutputRecord
+	Outputs   []*txOutputRecord
+	Fee       uint64
+	Valid     bool
+	Mint      []mintedTokenRecord
+}
+
+// txRecordWithoutMint is the layout of tx records with recordVersionCBORWithoutMint version
+type txRecordWithoutMint struct {
+	_         struct{} `cbor:",toarray"`
+	BlockSlot uint64
+	BlockHash core.Hash
+	Indx      uint32
+	Hash      core.Hash
+	Metadata  []byte
+	Inputs    []txInputOutputRecord
+	Outputs   []*txOutputRecord
+	Fee       uint64
+	Valid     bool
+}
+
+func (r *txRecord) unmarshalLegacyCBOR(version byte, data []byte) error {
+	if version != recordVersionCBORWithoutMint {
+		return fmt.Errorf("unknown tx record version %d", version)
+	}
+
+	var record txRecordWithoutMint
+
+	if err := cbor.Unmarshal(data, &record); err != nil {
+		return err
+	}
+
+	*r = txRecord{
+		BlockSlot: record.BlockSlot,
+		BlockHash: record.BlockHash,
+		Indx:      record.Indx,
+		Hash:      record.Hash,
+		Metadata:  record.Metadata,
+		Inputs:    record.Inputs,
+		Outputs:   record.Outputs,
+		Fee:       record.Fee,
+		Valid:     record.Valid,
+	}
+
+	return nil
+}
+
+type cardanoBlockRecord struct {
+	_      struct{} `cbor:",toarray"`
+	Slot   uint64
+	Hash   core.Hash
+	Number uint64
+	EraID  uint8
+	Txs    []core.Hash
+}
+
+func marshalTxOutput(output core.TxOutput) ([]byte, error) {
+	return marshalRecord(newTxOutputRecord(&output))
+}
+
+func unmarshalTxOutput(data []byte) (result core.TxOutput, err error) {
+	err = unmarshalRecord(data, &result, func(record *txOutputRecord) core.TxOutput {
+		return *record.toTxOutput()
+	})
+
+	return result, err
+}
+
+func marshalTx(tx *core.Tx) ([]byte, error) {
+	var (
+		inputs  []txInputOutputRecord
+		outputs []*txOutputRecord
+		mint    []mintedTokenRecord
+	)
+
+	// nil slices are kept as nil ones (the same as json encoding does)
+	if tx.Inputs != nil {
+		inputs = make([]txInputOutputRecord, len(tx.Inputs))
+	}
+
+	if tx.Outputs != nil {
+		outputs = make([]*txOutputRecord, len(tx.Outputs))
+	}
+
+	if tx.Mint != nil {
+		mint = make([]mintedTokenRecord, len(tx.Mint))
+	}
+
+	for i, inp := range tx.Inputs {
+		inputs[i] = txInputOutputRecord{
+			Hash:   inp.Input.Hash,
+			Index:  inp.Input.Index,
+			Output: *newTxOutputRecord(&inp.Output),
+		}
+	}
+
+	for i, out := range tx.Outputs {
+		outputs[i] = newTxOutputRecord(out)
+	}
+
+	for i, token := range tx.Mint {
+		mint[i] = mintedTokenRecord{
+			PolicyID: token.PolicyID,
+			Name:     token.Name,
+			Amount:   token.Amount,
+		}
+	}
+
+	return marshalRecord(&txRecord{
+		BlockSlot: tx.BlockSlot,
+		BlockHash: tx.BlockHash,
+		Indx:      tx.Indx,
+		Hash:      tx.Hash,
+		Metadata:  tx.Metadata,
+		Inputs:    inputs,
+		Outputs:   outputs,
+		Fee:       tx.Fee,
+		Valid:     tx.Valid,
+		Mint:      mint,
+	})
+}
+
+func unmarshalTx(data []byte) (result *core.Tx, err error) {
+	err = unmarshalRecord(data, &result, func(record *txRecord) *core.Tx {
+		var (
+			inputs  []*core.TxInputOutput
+			outputs []*core.TxOutput
+			mint    []core.MintedToken
+		)
+
+		if record.Inputs != nil {
+			inputs = make([]*core.TxInputOutput, len(record.Inputs))
+		}
+
+		if record.Outputs != nil {
+			outputs = make([]*core.TxOutput, len(record.Outputs))
+		}
+
+		if record.Mint != nil {
+			mint = make([]core.MintedToken, len(record.Mint))
+		}
+
+		for i, inp := range record.Inputs {
+			inputs[i] = &core.TxInputOutput{
+				Input: core.TxInput{
+					Hash:  inp.Hash,
+					Index: inp.Index,
+				},
+				Output: *inp.Output.toTxOutput(),
+			}
+		}
+
+		for i, out := range record.Outputs {
+			outputs[i] = out.toTxOutput()
+		}
+
+		for i, token := range record.Mint {
+			mint[i] = core.MintedToken{
+				PolicyID: token.PolicyID,
+				Name:     token.Name,
+				Amount:   token.Amount,
+			}
+		}
+
+		return &core.Tx{
+			BlockSlot: record.BlockSlot,
+			BlockHash: record.BlockHash,
+			Indx:      record.Indx,
+			Hash:      record.Hash,
+			Metadata:  record.Metadata,
+			Inputs:    inputs,
+			Outputs:   outputs,
+			Fee:       record.Fee,
+			Valid:     record.Valid,
+			Mint:      mint,
+		}
+	})
+
+	return result, err
+}
+
+func marshalCardanoBlock(block *core.CardanoBlock) ([]byte, error) {
+	return marshalRecord(&cardanoBlockRecord{
+		Slot:   block.Slot,
+		Hash:   block.Hash,
+		Number: block.Number,
+		EraID:  block.EraID,
+		Txs:    block.Txs,
+	})
+}
+
+func unmarshalCardanoBlock(data []byte) (result *core.CardanoBlock, err error) {
+	err = unmarshalRecord(data, &result, func(record *cardanoBlockRecord) *core.CardanoBlock {
+		return &core.CardanoBlock{
+			Slot:   record.Slot,
+			Hash:   record.Hash,
+			Number: record.Number,
+			EraID:  record.EraID,
+			Txs:    record.Txs,
+		}
+	})
+
+	return result, err
+}
+
+func newTxOutputRecord(output *core.TxOutput) *txOutputRecord {
+	var tokens []tokenAmountRecord
+
+	if output.Tokens != nil {
+		tokens = make([]tokenAmountRecord, len(output.Tokens))
+		for i, token := range output.Tokens {
+			tokens[i] = tokenAmountRecord{
+				PolicyID: token.PolicyID,
+				Name:     token.Name,
+				Amount:   token.Amount,
+			}
+		}
+	}
+
+	return &txOutputRecord{
+		Address:   output.Address,
+		Slot:      output.Slot,
+		Amount:    output.Amount,
+		Datum:     output.Datum,
+		DatumHash: output.DatumHash,
+		IsUsed:    output.IsUsed,
+		Tokens:    tokens,
+		ScriptRef: output.ScriptRef,
+	}
+}
+
+func (r *txOutputRecord) toTxOutput() *core.TxOutput {
+	var tokens []core.TokenAmount
+
+	if r.Tokens != nil {
+		tokens = make([]core.TokenAmount, len(r.Tokens))
+		for i, token := range r.Tokens {
+			tokens[i] = core.TokenAmount{
+				PolicyID: token.PolicyID,
+				Name:     token.Name,
+				Amount:   token.Amount,
+			}
+		}
+	}
+
+	return &core.TxOutput{
+		Address:   r.Address,
+		Slot:      r.Slot,
+		Amount:    r.Amount,
+		Datum:     r.Datum,
+		DatumHash: r.DatumHash,
+		IsUsed:    r.IsUsed,
+		Tokens:    tokens,
+		ScriptRef: r.ScriptRef,
+	}
+}
+
+func marshalRecord(record any) ([]byte, error) {
+	bytes, err := cbor.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{recordVersionCBOR}, bytes...), nil
+}
+
+// unmarshalRecord decodes cbor record of any known version or falls back to json
+// for the records written by older versions
+func unmarshalRecord[R any, T any](data []byte, result *T, fromRecord func(*R) T) error {
+	var (
+		record R
+		err    error
+	)
+
+	switch {
+	case len(data) == 0 || isJSONRecord(data):
+		return json.Unmarshal(data, result)
+	case data[0] == recordVersionCBOR:
+		err = cbor.Unmarshal(data[1:], &record)
+	default:
+		// records whose layout did not change between the versions are decoded the same way
+		if legacy, ok := any(&record).(legacyRecord); ok {
+			err = legacy.unmarshalLegacyCBOR(data[0], data[1:])
+		} else {
+			err = cbor.Unmarshal(data[1:], &record)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid record: %w", err)
+	}
+
+	*result = fromRecord(&record)
+
+	return nil
+}
+
+// isJSONRecord returns true if the record was written by older versions and should be migrated
+func isJSONRecord(data []byte) bool {
+	return len(data) > 0 && data[0] != recordVersionCBOR && data[0] != recordVersionCBORWithoutMint
+}
@@ -0,0 +1,219 @@
+package indexerbbolt
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	indexer "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestRecordEncoding(t *testing.T) {
+	t.Parallel()
+
+	output := indexer.TxOutput{
+		Address:   "addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u",
+		Slot:      100,
+		Amount:    2_000_000,
+		Datum:     []byte{0x18, 0x2a},
+		DatumHash: indexer.Hash{1, 2, 3},
+		IsUsed:    true,
+		Tokens:    []indexer.TokenAmount{{PolicyID: "29f2fe", Name: "Route3", Amount: 10}},
+		ScriptRef: []byte{0x82, 0x00, 0x80},
+	}
+	tx := &indexer.Tx{
+		BlockSlot: 100,
+		BlockHash: indexer.Hash{4},
+		Indx:      2,
+		Hash:      indexer.Hash{5},
+		Metadata:  []byte{0xa0},
+		Inputs: []*indexer.TxInputOutput{
+			{Input: indexer.TxInput{Hash: indexer.Hash{6}, Index: 3}, Output: output},
+		},
+		Outputs: []*indexer.TxOutput{&output, {Address: "addr2", Amount: 1}},
+		Fee:     200_000,
+		Valid:   true,
+		Mint:    []indexer.MintedToken{{PolicyID: "29f2fe", Name: "Route3", Amount: -5}},
+	}
+	block := &indexer.CardanoBlock{
+		Slot:   100,
+		Hash:   indexer.Hash{4},
+		Number: 7,
+		EraID:  6,
+		Txs:    []indexer.Hash{{5}},
+	}
+
+	t.Run("cbor", func(t *testing.T) {
+		outputBytes, err := marshalTxOutput(output)
+		require.NoError(t, err)
+		require.Equal(t, recordVersionCBOR, outputBytes[0])
+
+		outputJSONBytes, err := json.Marshal(output)
+		require.NoError(t, err)
+		require.Less(t, len(outputBytes), len(outputJSONBytes))
+
+		decodedOutput, err := unmarshalTxOutput(outputBytes)
+		require.NoError(t, err)
+		require.Equal(t, output, decodedOutput)
+
+		txBytes, err := marshalTx(tx)
+		require.NoError(t, err)
+
+		decodedTx, err := unmarshalTx(txBytes)
+		require.NoError(t, err)
+		require.Equal(t, tx, decodedTx)
+
+		blockBytes, err := marshalCardanoBlock(block)
+		require.NoError(t, err)
+
+		decodedBlock, err := unmarshalCardanoBlock(blockBytes)
+		require.NoError(t, err)
+		require.Equal(t, block, decodedBlock)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		outputBytes, err := json.Marshal(output)
+		require.NoError(t, err)
+		require.True(t, isJSONRecord(outputBytes))
+
+		decodedOutput, err := unmarshalTxOutput(outputBytes)
+		require.NoError(t, err)
+		require.Equal(t, output, decodedOutput)
+
+		txBytes, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		decodedTx, err := unmarshalTx(txBytes)
+		require.NoError(t, err)
+		require.Equal(t, tx, decodedTx)
+
+		blockBytes, err := json.Marshal(block)
+		require.NoError(t, err)
+
+		decodedBlock, err := unmarshalCardanoBlock(blockBytes)
+		require.NoError(t, err)
+		require.Equal(t, block, decodedBlock)
+	})
+
+	t.Run("cbor without mint", func(t *testing.T) {
+		txWithoutMint := *tx
+		txWithoutMint.Mint = nil
+
+		legacyBytes, err := cbor.Marshal(&txRecordWithoutMint{
+			BlockSlot: tx.BlockSlot,
+			BlockHash: tx.BlockHash,
+			Indx:      tx.Indx,
+			Hash:      tx.Hash,
+			Metadata:  tx.Metadata,
+			Inputs:    []txInputOutputRecord{{Hash: indexer.Hash{6}, Index: 3, Output: *newTxOutputRecord(&output)}},
+			Outputs:   []*txOutputRecord{newTxOutputRecord(tx.Outputs[0]), newTxOutputRecord(tx.Outputs[1])},
+			Fee:       tx.Fee,
+			Valid:     tx.Valid,
+		})
+		require.NoError(t, err)
+
+		// records written before mint was introduced have the older version and do not have the last field
+		decodedTx, err := unmarshalTx(append([]byte{recordVersionCBORWithoutMint}, legacyBytes...))
+		require.NoError(t, err)
+		require.Equal(t, &txWithoutMint, decodedTx)
+		require.False(t, isJSONRecord(append([]byte{recordVersionCBORWithoutMint}, legacyBytes...)))
+
+		// the latest version must have all the fields
+		_, err = unmarshalTx(append([]byte{recordVersionCBOR}, legacyBytes...))
+		require.Error(t, err)
+
+		// other records have the same layout in both versions
+		outputBytes, err := marshalTxOutput(output)
+		require.NoError(t, err)
+
+		outputBytes[0] = recordVersionCBORWithoutMint
+
+		decodedOutput, err := unmarshalTxOutput(outputBytes)
+		require.NoError(t, err)
+		require.Equal(t, output, decodedOutput)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := unmarshalTxOutput([]byte{recordVersionCBOR, 0xff})
+		require.Error(t, err)
+
+		_, err = unmarshalTx([]byte("{"))
+		require.Error(t, err)
+	})
+}
+
+func TestMigrateRecordsToCBOR(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "temp_test.db")
+	txInOut := &indexer.TxInputOutput{
+		Input:  indexer.TxInput{Hash: indexer.Hash{1}, Index: 2},
+		Output: indexer.TxOutput{Address: "addr", Amount: 100},
+	}
+	tx := &indexer.Tx{BlockSlot: 10, Indx: 1, Hash: indexer.Hash{1}, Outputs: []*indexer.TxOutput{&txInOut.Output}}
+	block := &indexer.CardanoBlock{Slot: 10, Hash: indexer.Hash{2}, Txs: []indexer.Hash{{1}}}
+	legacyTx := &indexer.Tx{BlockSlot: 12, Hash: indexer.Hash{3}}
+
+	db := &BBoltDatabase{}
+	require.NoError(t, db.Init(filePath))
+
+	// write records the same way as older versions did
+	require.NoError(t, db.db.Update(func(bboltTx *bbolt.Tx) error {
+		outputBytes, err := json.Marshal(txInOut.Output)
+		require.NoError(t, err)
+
+		txBytes, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		blockBytes, err := json.Marshal(block)
+		require.NoError(t, err)
+
+		require.NoError(t, bboltTx.Bucket(txOutputsBucket).Put(txInOut.Input.Key(), outputBytes))
+		require.NoError(t, bboltTx.Bucket(unprocessedTxsBucket).Put(tx.Key(), txBytes))
+		require.NoError(t, bboltTx.Bucket(confirmedBlocks).Put(block.Key(), blockBytes))
+
+		legacyTxBytes, err := cbor.Marshal(&txRecordWithoutMint{BlockSlot: legacyTx.BlockSlot, Hash: legacyTx.Hash})
+		require.NoError(t, err)
+
+		require.NoError(t, bboltTx.Bucket(unprocessedTxsBucket).Put(
+			legacyTx.Key(), append([]byte{recordVersionCBORWithoutMint}, legacyTxBytes...)))
+
+		return nil
+	}))
+	// new record must be kept as it is
+	require.NoError(t, db.OpenTx().AddConfirmedTxs([]*indexer.Tx{{BlockSlot: 11}}).Execute())
+	require.NoError(t, db.Close())
+
+	require.NoError(t, MigrateRecordsToCBOR(filePath))
+
+	require.NoError(t, db.Init(filePath))
+
+	defer db.Close() //nolint:errcheck
+
+	require.NoError(t, db.db.View(func(bboltTx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{txOutputsBucket, unprocessedTxsBucket, confirmedBlocks} {
+			require.NoError(t, bboltTx.Bucket(bucket).ForEach(func(k, v []byte) error {
+				require.Equal(t, recordVersionCBOR, v[0])
+
+				return nil
+			}))
+		}
+
+		return nil
+	}))
+
+	output, err := db.GetTxOutput(txInOut.Input)
+	require.NoError(t, err)
+	require.Equal(t, txInOut.Output, output)
+
+	txs, err := db.GetUnprocessedConfirmedTxs(0)
+	require.NoError(t, err)
+	require.Equal(t, []*indexer.Tx{tx, {BlockSlot: 11}, legacyTx}, txs)
+
+	blocks, err := db.GetLatestConfirmedBlocks(0)
+	require.NoError(t, err)
+	require.Equal(t, []*indexer.CardanoBlock{block}, blocks)
+}
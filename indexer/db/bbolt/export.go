@@ -0,0 +1,118 @@
+package indexerbbolt
+
+import (
+	"encoding/json"
+	"io"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/fxamacker/cbor/v2"
+	"go.etcd.io/bbolt"
+)
+
+// Export streams the whole database state from a single read transaction, so the export is consistent
+func (bd *BBoltDatabase) Export(w io.Writer) error {
+	writer, err := core.NewExportWriter(w)
+	if err != nil {
+		return err
+	}
+
+	if err := bd.db.View(func(tx *bbolt.Tx) error {
+		if err := tx.Bucket(txOutputsBucket).ForEach(func(k, v []byte) error {
+			var txInput core.TxInput
+
+			if err := txInput.Set(k); err != nil {
+				return err
+			}
+
+			txOutput, err := unmarshalTxOutput(v)
+			if err != nil {
+				return err
+			}
+
+			return writer.Write(&core.ExportEntry{
+				Type:     core.ExportEntryTxOutput,
+				TxOutput: &core.TxInputOutput{Input: txInput, Output: txOutput},
+			})
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(confirmedBlocks).ForEach(func(_, v []byte) error {
+			block, err := unmarshalCardanoBlock(v)
+			if err != nil {
+				return err
+			}
+
+			return writer.Write(&core.ExportEntry{Type: core.ExportEntryConfirmedBlock, Block: block})
+		}); err != nil {
+			return err
+		}
+
+		for _, x := range []struct {
+			entryType core.ExportEntryType
+			bucket    []byte
+		}{
+			{entryType: core.ExportEntryUnprocessedTx, bucket: unprocessedTxsBucket},
+			{entryType: core.ExportEntryProcessedTx, bucket: processedTxsBucket},
+		} {
+			if err := tx.Bucket(x.bucket).ForEach(func(_, v []byte) error {
+				cardTx, err := unmarshalTx(v)
+				if err != nil {
+					return err
+				}
+
+				return writer.Write(&core.ExportEntry{Type: x.entryType, Tx: cardTx})
+			}); err != nil {
+				return err
+			}
+		}
+
+		// undo records are ordered from the oldest one
+		if err := tx.Bucket(undoJournalBucket).ForEach(func(_, v []byte) error {
+			var record *core.UndoRecord
+
+			if err := cbor.Unmarshal(v, &record); err != nil {
+				return err
+			}
+
+			return writer.Write(&core.ExportEntry{Type: core.ExportEntryUndoRecord, UndoRecord: record})
+		}); err != nil {
+			return err
+		}
+
+		if data := tx.Bucket(latestBlockPointBucket).Get(addressesOfInterestKey); data != nil {
+			addresses := []string{}
+
+			if err := json.Unmarshal(data, &addresses); err != nil {
+				return err
+			}
+
+			if err := writer.Write(&core.ExportEntry{
+				Type: core.ExportEntryAddressesOfInterest, Addresses: addresses,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if data := tx.Bucket(latestBlockPointBucket).Get(defaultKey); len(data) > 0 {
+			var blockPoint *core.BlockPoint
+
+			if err := json.Unmarshal(data, &blockPoint); err != nil {
+				return err
+			}
+
+			return writer.Write(&core.ExportEntry{Type: core.ExportEntryLatestBlockPoint, BlockPoint: blockPoint})
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// Import imports the export stream into the empty database
+func (bd *BBoltDatabase) Import(r io.Reader) error {
+	return core.ImportDatabase(bd, r)
+}
@@ -0,0 +1,207 @@
+package indexerbbolt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+// migrationBatchSize is the maximum number of records visited in a single bbolt transaction
+const migrationBatchSize = 10_000
+
+type recordConverter func(data []byte) ([]byte, error)
+
+// MigrateRecordsToCBOR rewrites all json and older cbor records (tx outputs, txs and blocks) of the database
+// into the latest cbor ones.
+// It is an offline migration and should not be executed while the indexer is using the database.
+// Reading of the json records is still supported so the migration is needed only to reduce the database size
+func MigrateRecordsToCBOR(filePath string) (err error) {
+	db := &BBoltDatabase{}
+	if err := db.Init(filePath); err != nil {
+		return err
+	}
+
+	defer func() {
+		err = errors.Join(err, db.Close())
+	}()
+
+	txConverter := func(data []byte) ([]byte, error) {
+		tx, err := unmarshalTx(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return marshalTx(tx)
+	}
+
+	for _, migration := range []struct {
+		bucket    []byte
+		converter recordConverter
+	}{
+		{
+			bucket: txOutputsBucket,
+			converter: func(data []byte) ([]byte, error) {
+				output, err := unmarshalTxOutput(data)
+				if err != nil {
+					return nil, err
+				}
+
+				return marshalTxOutput(output)
+			},
+		},
+		{bucket: processedTxsBucket, converter: txConverter},
+		{bucket: unprocessedTxsBucket, converter: txConverter},
+		{
+			bucket: confirmedBlocks,
+			converter: func(data []byte) ([]byte, error) {
+				block, err := unmarshalCardanoBlock(data)
+				if err != nil {
+					return nil, err
+				}
+
+				return marshalCardanoBlock(block)
+			},
+		},
+	} {
+		if err := migrateBucketRecords(db.db, migration.bucket, migration.converter); err != nil {
+			return fmt.Errorf("could not migrate bucket %s: %w", string(migration.bucket), err)
+		}
+	}
+
+	return nil
+}
+
+func migrateBucketRecords(db *bbolt.DB, bucketName []byte, converter recordConverter) error {
+	var startKey []byte
+
+	for {
+		var nextKey []byte
+
+		err := db.Update(func(tx *bbolt.Tx) error {
+			var (
+				bucket = tx.Bucket(bucketName)
+				cursor = bucket.Cursor()
+				keys   [][]byte
+				values [][]byte
+				cnt    = 0
+			)
+
+			k, v := cursor.First()
+			if startKey != nil {
+				k, v = cursor.Seek(startKey)
+			}
+
+			for ; k != nil && cnt < migrationBatchSize; k, v = cursor.Next() {
+				cnt++
+
+				// json and older cbor records are rewritten with the latest cbor version
+				if len(v) == 0 || v[0] == recordVersionCBOR {
+					continue
+				}
+
+				value, err := converter(v)
+				if err != nil {
+					return fmt.Errorf("could not convert record %x: %w", k, err)
+				}
+
+				keys = append(keys, bytes.Clone(k))
+				values = append(values, value)
+			}
+
+			// bucket must not be modified while iterating with the cursor
+			nextKey = bytes.Clone(k)
+
+			for i, key := range keys {
+				if err := bucket.Put(key, values[i]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if nextKey == nil {
+			return nil
+		}
+
+		startKey = nextKey
+	}
+}
+
+// indexMigration fills the secondary index from all the records of the source bucket
+type indexMigration struct {
+	index       []byte
+	source      []byte
+	indexRecord func(tx *bbolt.Tx, k, v []byte) error
+}
+
+var indexMigrations = []indexMigration{
+	{index: txOutputsByAddressBucket, source: txOutputsBucket, indexRecord: indexTxOutputRecord},
+	{index: txsByHashBucket, source: unprocessedTxsBucket, indexRecord: indexTxRecord},
+	{index: txsByHashBucket, source: processedTxsBucket, indexRecord: indexTxRecord},
+}
+
+// markerKey is the key of the pending migration marker in the latest block point bucket.
+// Marker value is a flag byte followed by the source key from which the migration continues
+func (m indexMigration) markerKey() []byte {
+	return []byte("indexMigration:" + string(m.index) + ":" + string(m.source))
+}
+
+// runIndexMigrations executes all the pending index migrations.
+// Each batch is a separate bbolt transaction which also moves the marker forward
+func runIndexMigrations(db *bbolt.DB) error {
+	for _, migration := range indexMigrations {
+		for {
+			isDone := false
+
+			err := db.Update(func(tx *bbolt.Tx) error {
+				markerBucket := tx.Bucket(latestBlockPointBucket)
+
+				marker := markerBucket.Get(migration.markerKey())
+				if marker == nil {
+					isDone = true
+
+					return nil
+				}
+
+				cursor := tx.Bucket(migration.source).Cursor()
+				cnt := 0
+
+				k, v := cursor.First()
+				if len(marker) > 1 {
+					k, v = cursor.Seek(marker[1:])
+				}
+
+				for ; k != nil && cnt < migrationBatchSize; k, v = cursor.Next() {
+					cnt++
+
+					if err := migration.indexRecord(tx, k, v); err != nil {
+						return fmt.Errorf("could not index record %x: %w", k, err)
+					}
+				}
+
+				if k == nil {
+					isDone = true
+
+					return markerBucket.Delete(migration.markerKey())
+				}
+
+				return markerBucket.Put(migration.markerKey(), append([]byte{0}, k...))
+			})
+			if err != nil {
+				return fmt.Errorf("could not migrate %s index: %w", string(migration.index), err)
+			}
+
+			if isDone {
+				break
+			}
+		}
+	}
+
+	return nil
+}
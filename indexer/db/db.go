@@ -1,13 +1,31 @@
 package db
 
 import (
+	"fmt"
+
 	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
 	indexerbbolt "github.com/Ethernal-Tech/cardano-infrastructure/indexer/db/bbolt"
+	indexersql "github.com/Ethernal-Tech/cardano-infrastructure/indexer/db/sql"
+)
+
+const (
+	BBoltDatabaseName  = "bbolt"
+	SQLiteDatabaseName = "sqlite"
 )
 
+// NewDatabaseInit creates and initializes database by its name. Empty name is the same as bbolt
 func NewDatabaseInit(name string, filePath string) (indexer.Database, error) {
-	// currently name is not used because only bbolt is supported
-	db := &indexerbbolt.BBoltDatabase{}
+	var db indexer.Database
+
+	switch name {
+	case "", BBoltDatabaseName:
+		db = &indexerbbolt.BBoltDatabase{}
+	case SQLiteDatabaseName:
+		db = indexersql.NewSQLiteDatabase()
+	default:
+		return nil, fmt.Errorf("unsupported database: %s", name)
+	}
+
 	if err := db.Init(filePath); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_ExportImport(t *testing.T) {
+	t.Parallel()
+
+	txOutputs := []*indexer.TxInputOutput{
+		{
+			Input: indexer.TxInput{Hash: indexer.Hash{1}, Index: 0},
+			Output: indexer.TxOutput{
+				Address: "addr_test1", Slot: 10, Amount: 100,
+				Tokens: []indexer.TokenAmount{{PolicyID: "29f2fe", Name: "Route3", Amount: 5}},
+			},
+		},
+		{
+			Input:  indexer.TxInput{Hash: indexer.Hash{1}, Index: 1},
+			Output: indexer.TxOutput{Address: "addr_test2", Slot: 10, Amount: 200, Datum: []byte{0x18, 0x2a}},
+		},
+		{
+			Input:  indexer.TxInput{Hash: indexer.Hash{2}, Index: 0},
+			Output: indexer.TxOutput{Address: "addr_test1", Slot: 20, Amount: 300},
+		},
+	}
+	txs := []*indexer.Tx{
+		{BlockSlot: 10, BlockHash: indexer.Hash{10}, Hash: indexer.Hash{1}, Fee: 10, Valid: true},
+		{BlockSlot: 20, BlockHash: indexer.Hash{20}, Hash: indexer.Hash{2}, Metadata: []byte{0xa0}},
+		{BlockSlot: 20, BlockHash: indexer.Hash{20}, Indx: 1, Hash: indexer.Hash{3}},
+	}
+	blocks := []*indexer.CardanoBlock{
+		{Slot: 10, Hash: indexer.Hash{10}, Number: 1, Txs: []indexer.Hash{{1}}},
+		{Slot: 20, Hash: indexer.Hash{20}, Number: 2, Txs: []indexer.Hash{{2}, {3}}},
+	}
+	undoRecord := &indexer.UndoRecord{
+		BlockPoint:     indexer.BlockPoint{BlockSlot: 20, BlockHash: indexer.Hash{20}},
+		PrevBlockPoint: &indexer.BlockPoint{BlockSlot: 10, BlockHash: indexer.Hash{10}},
+		TxIndexes:      []uint32{0, 1},
+		AddedTxOutputs: []indexer.TxInput{txOutputs[2].Input},
+	}
+	latestPoint := &indexer.BlockPoint{BlockSlot: 20, BlockHash: indexer.Hash{20}}
+
+	for _, names := range [][2]string{
+		{BBoltDatabaseName, SQLiteDatabaseName},
+		{SQLiteDatabaseName, BBoltDatabaseName},
+	} {
+		t.Run(names[0]+" to "+names[1], func(t *testing.T) {
+			t.Parallel()
+
+			source, err := NewDatabaseInit(names[0], filepath.Join(t.TempDir(), "source.db"))
+			require.NoError(t, err)
+
+			defer source.Close()
+
+			target, err := NewDatabaseInit(names[1], filepath.Join(t.TempDir(), "target.db"))
+			require.NoError(t, err)
+
+			defer target.Close()
+
+			require.NoError(t, source.OpenTx().
+				AddTxOutputs(txOutputs).
+				RemoveTxOutputs([]indexer.TxInput{txOutputs[1].Input}, true).
+				AddConfirmedTxs(txs).
+				AddConfirmedBlock(blocks[0]).
+				AddConfirmedBlock(blocks[1]).
+				AddUndoRecord(undoRecord, 10).
+				SetAddressesOfInterest([]string{"addr_test1"}).
+				SetLatestBlockPoint(latestPoint).
+				Execute())
+			require.NoError(t, source.MarkConfirmedTxsProcessed(txs[:1]))
+
+			var sourceExport, targetExport bytes.Buffer
+
+			require.NoError(t, source.Export(&sourceExport))
+			require.NoError(t, target.Import(bytes.NewReader(sourceExport.Bytes())))
+			require.NoError(t, target.Export(&targetExport))
+			require.Equal(t, sourceExport.Bytes(), targetExport.Bytes())
+
+			// database must be empty
+			require.ErrorContains(t, target.Import(bytes.NewReader(sourceExport.Bytes())), "not empty")
+
+			point, err := target.GetLatestBlockPoint()
+			require.NoError(t, err)
+			require.Equal(t, latestPoint, point)
+
+			output, err := target.GetTxOutput(txOutputs[1].Input)
+			require.NoError(t, err)
+			require.True(t, output.IsUsed)
+
+			unprocessedTxs, err := target.GetUnprocessedConfirmedTxs(0)
+			require.NoError(t, err)
+			require.Equal(t, txs[1:], unprocessedTxs)
+
+			records, err := target.GetUndoRecords(0)
+			require.NoError(t, err)
+			require.Equal(t, []*indexer.UndoRecord{undoRecord}, records)
+
+			addresses, err := target.GetAddressesOfInterest()
+			require.NoError(t, err)
+			require.Equal(t, []string{"addr_test1"}, addresses)
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+
+		source, err := NewDatabaseInit(BBoltDatabaseName, filepath.Join(t.TempDir(), "source.db"))
+		require.NoError(t, err)
+
+		defer source.Close()
+
+		require.NoError(t, source.OpenTx().AddTxOutputs(txOutputs).SetLatestBlockPoint(latestPoint).Execute())
+
+		var export bytes.Buffer
+
+		require.NoError(t, source.Export(&export))
+
+		target, err := NewDatabaseInit(SQLiteDatabaseName, filepath.Join(t.TempDir(), "target.db"))
+		require.NoError(t, err)
+
+		defer target.Close()
+
+		require.Error(t, target.Import(bytes.NewReader(export.Bytes()[:export.Len()-5])))
+
+		// latest block point is written only if the whole export is imported
+		point, err := target.GetLatestBlockPoint()
+		require.NoError(t, err)
+		require.Nil(t, point)
+	})
+}
+
+func TestBlockIndexer_ConfirmedBlocksBatch(t *testing.T) {
+	t.Parallel()
+
+	const (
+		address     = "addr_test1"
+		blocksCount = 8
+	)
+
+	// every tx spends the output of the previous one
+	txsRetriever := &indexer.BlockTxsRetrieverMock{
+		RetrieveFn: func(blockHeader indexer.BlockHeader) ([]*indexer.Tx, error) {
+			tx := &indexer.Tx{
+				BlockSlot: blockHeader.Slot,
+				BlockHash: blockHeader.Hash,
+				Hash:      indexer.Hash{byte(blockHeader.Slot)},
+				Outputs:   []*indexer.TxOutput{{Address: address, Slot: blockHeader.Slot, Amount: blockHeader.Slot}},
+				Valid:     true,
+			}
+
+			if blockHeader.Slot > 1 {
+				tx.Inputs = []*indexer.TxInputOutput{{Input: indexer.TxInput{Hash: indexer.Hash{byte(blockHeader.Slot - 1)}}}}
+			}
+
+			return []*indexer.Tx{tx}, nil
+		},
+	}
+
+	runIndexer := func(batchSize uint, onRollForward func(*indexer.BlockIndexer, indexer.Database, uint64, int)) []byte {
+		db, err := NewDatabaseInit(BBoltDatabaseName, filepath.Join(t.TempDir(), "temp.db"))
+		require.NoError(t, err)
+
+		defer db.Close()
+
+		confirmedCnt := 0
+		blockIndexer := indexer.NewBlockIndexer(&indexer.BlockIndexerConfig{
+			ConfirmationBlockCount:   1,
+			AddressesOfInterest:      []string{address},
+			AddressCheck:             indexer.AddressCheckAll,
+			UndoJournalDepth:         4,
+			ConfirmedBlocksBatchSize: batchSize,
+		}, func(_ *indexer.CardanoBlock, _ []*indexer.Tx) error {
+			confirmedCnt++
+
+			return nil
+		}, db, hclog.NewNullLogger())
+
+		_, err = blockIndexer.Reset()
+		require.NoError(t, err)
+
+		blockIndexer.SetChainTip(indexer.BlockPoint{BlockSlot: 100_000})
+
+		for slot := uint64(1); slot <= blocksCount; slot++ {
+			require.NoError(t, blockIndexer.RollForward(indexer.BlockHeader{
+				Slot: slot, Hash: indexer.Hash{byte(slot), 1}, Number: slot,
+			}, txsRetriever))
+
+			onRollForward(blockIndexer, db, slot, confirmedCnt)
+		}
+
+		require.Equal(t, blocksCount-1, confirmedCnt)
+		require.Equal(t, uint64(blocksCount-1), blockIndexer.GetThroughputStats().ConfirmedBlocks)
+
+		var export bytes.Buffer
+
+		require.NoError(t, db.Export(&export))
+
+		return export.Bytes()
+	}
+
+	getLatestSlot := func(db indexer.Database) uint64 {
+		point, err := db.GetLatestBlockPoint()
+		require.NoError(t, err)
+
+		if point == nil {
+			return 0
+		}
+
+		return point.BlockSlot
+	}
+
+	expected := runIndexer(0, func(_ *indexer.BlockIndexer, db indexer.Database, slot uint64, confirmedCnt int) {
+		require.Equal(t, slot-1, getLatestSlot(db))
+		require.Equal(t, int(slot-1), confirmedCnt) //nolint:gosec
+	})
+
+	actual := runIndexer(3, func(bi *indexer.BlockIndexer, db indexer.Database, slot uint64, confirmedCnt int) {
+		switch slot {
+		case 1, 2, 3:
+			require.Equal(t, uint64(0), getLatestSlot(db))
+			require.Equal(t, 0, confirmedCnt)
+		case 4:
+			// batch of the three blocks is saved
+			require.Equal(t, uint64(3), getLatestSlot(db))
+			require.Equal(t, 3, confirmedCnt)
+			require.Equal(t, uint64(1), bi.GetThroughputStats().Commits)
+
+			// not catching up anymore -> every block is saved immediately
+			bi.SetChainTip(indexer.BlockPoint{BlockSlot: slot + 1})
+		default:
+			require.Equal(t, slot-1, getLatestSlot(db))
+			require.Equal(t, int(slot-1), confirmedCnt) //nolint:gosec
+		}
+	})
+
+	require.Equal(t, expected, actual)
+}
@@ -0,0 +1,169 @@
+package indexersql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+)
+
+// Export streams the whole database state from a single read only transaction, so the export is consistent
+func (sd *SQLDatabase) Export(w io.Writer) error {
+	writer, err := core.NewExportWriter(w)
+	if err != nil {
+		return err
+	}
+
+	tx, err := sd.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := forEachRow(tx, `SELECT tx_hash, tx_index, is_used, data FROM tx_outputs ORDER BY tx_hash, tx_index`,
+		func(rows *sql.Rows) error {
+			var (
+				txInOut core.TxInputOutput
+				hash    string
+				isUsed  bool
+				data    []byte
+			)
+
+			if err := rows.Scan(&hash, &txInOut.Input.Index, &isUsed, &data); err != nil {
+				return err
+			}
+
+			if err := json.Unmarshal(data, &txInOut.Output); err != nil {
+				return err
+			}
+
+			txInOut.Input.Hash = core.NewHashFromHexString(hash)
+			txInOut.Output.IsUsed = isUsed
+
+			return writer.Write(&core.ExportEntry{Type: core.ExportEntryTxOutput, TxOutput: &txInOut})
+		}); err != nil {
+		return err
+	}
+
+	if err := forEachJSONRow(tx, `SELECT data FROM blocks ORDER BY slot`, func(block *core.CardanoBlock) error {
+		return writer.Write(&core.ExportEntry{Type: core.ExportEntryConfirmedBlock, Block: block})
+	}); err != nil {
+		return err
+	}
+
+	if err := forEachRow(tx, `SELECT processed, data FROM txs ORDER BY processed, block_slot, tx_index`,
+		func(rows *sql.Rows) error {
+			var (
+				cardTx    *core.Tx
+				processed bool
+				data      []byte
+			)
+
+			if err := rows.Scan(&processed, &data); err != nil {
+				return err
+			}
+
+			if err := json.Unmarshal(data, &cardTx); err != nil {
+				return err
+			}
+
+			entryType := core.ExportEntryUnprocessedTx
+			if processed {
+				entryType = core.ExportEntryProcessedTx
+			}
+
+			return writer.Write(&core.ExportEntry{Type: entryType, Tx: cardTx})
+		}); err != nil {
+		return err
+	}
+
+	// undo records are ordered from the oldest one
+	if err := forEachJSONRow(tx, `SELECT data FROM undo_records ORDER BY slot`, func(record *core.UndoRecord) error {
+		return writer.Write(&core.ExportEntry{Type: core.ExportEntryUndoRecord, UndoRecord: record})
+	}); err != nil {
+		return err
+	}
+
+	var data []byte
+
+	err = tx.QueryRow(`SELECT data FROM addresses_of_interest WHERE id = 0`).Scan(&data)
+	if err == nil {
+		addresses := []string{}
+
+		if err := json.Unmarshal(data, &addresses); err != nil {
+			return err
+		}
+
+		if err := writer.Write(&core.ExportEntry{
+			Type: core.ExportEntryAddressesOfInterest, Addresses: addresses,
+		}); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	var (
+		slot uint64
+		hash string
+	)
+
+	err = tx.QueryRow(`SELECT slot, hash FROM latest_block_point WHERE id = 0`).Scan(&slot, &hash)
+	if err == nil {
+		if err := writer.Write(&core.ExportEntry{
+			Type:       core.ExportEntryLatestBlockPoint,
+			BlockPoint: &core.BlockPoint{BlockSlot: slot, BlockHash: core.NewHashFromHexString(hash)},
+		}); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// Import imports the export stream into the empty database
+func (sd *SQLDatabase) Import(r io.Reader) error {
+	return core.ImportDatabase(sd, r)
+}
+
+func forEachRow(tx *sql.Tx, query string, handler func(rows *sql.Rows) error) error {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := handler(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func forEachJSONRow[T any](tx *sql.Tx, query string, handler func(*T) error) error {
+	return forEachRow(tx, query, func(rows *sql.Rows) error {
+		var (
+			data []byte
+			item *T
+		)
+
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(data, &item); err != nil {
+			return err
+		}
+
+		return handler(item)
+	})
+}
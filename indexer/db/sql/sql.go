@@ -0,0 +1,367 @@
+package indexersql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	_ "modernc.org/sqlite" // registers sqlite driver
+)
+
+const (
+	SQLiteDriverName = "sqlite"
+
+	sqliteConnectionParams = "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+)
+
+// schema is created on Init. Hashes are stored as hex strings and full records as json
+// so the tables can be queried ad-hoc (e.g. with json_extract)
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS tx_outputs (
+		tx_hash TEXT NOT NULL,
+		tx_index INTEGER NOT NULL,
+		address TEXT NOT NULL,
+		slot INTEGER NOT NULL,
+		amount INTEGER NOT NULL,
+		is_used INTEGER NOT NULL DEFAULT 0,
+		data TEXT NOT NULL,
+		PRIMARY KEY (tx_hash, tx_index)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_tx_outputs_address ON tx_outputs (address)`,
+	`CREATE INDEX IF NOT EXISTS idx_tx_outputs_slot ON tx_outputs (slot)`,
+	`CREATE TABLE IF NOT EXISTS txs (
+		block_slot INTEGER NOT NULL,
+		tx_index INTEGER NOT NULL,
+		hash TEXT NOT NULL,
+		block_hash TEXT NOT NULL,
+		processed INTEGER NOT NULL DEFAULT 0,
+		data TEXT NOT NULL,
+		PRIMARY KEY (block_slot, tx_index)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_txs_hash ON txs (hash)`,
+	`CREATE INDEX IF NOT EXISTS idx_txs_processed ON txs (processed, block_slot, tx_index)`,
+	`CREATE TABLE IF NOT EXISTS blocks (
+		slot INTEGER NOT NULL PRIMARY KEY,
+		hash TEXT NOT NULL,
+		number INTEGER NOT NULL,
+		data TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_blocks_hash ON blocks (hash)`,
+	`CREATE TABLE IF NOT EXISTS undo_records (
+		slot INTEGER NOT NULL PRIMARY KEY,
+		data TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS latest_block_point (
+		id INTEGER NOT NULL PRIMARY KEY CHECK (id = 0),
+		slot INTEGER NOT NULL,
+		hash TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS addresses_of_interest (
+		id INTEGER NOT NULL PRIMARY KEY CHECK (id = 0),
+		data TEXT NOT NULL
+	)`,
+}
+
+type SQLDatabase struct {
+	driverName string
+	db         *sql.DB
+}
+
+var _ core.Database = (*SQLDatabase)(nil)
+
+func NewSQLiteDatabase() *SQLDatabase {
+	return &SQLDatabase{
+		driverName: SQLiteDriverName,
+	}
+}
+
+func (sd *SQLDatabase) Init(filePath string) error {
+	dataSourceName := filePath
+	if sd.driverName == SQLiteDriverName {
+		// wal journal allows other processes to read the database while the indexer writes into it
+		dataSourceName = "file:" + filePath + sqliteConnectionParams
+	}
+
+	db, err := sql.Open(sd.driverName, dataSourceName)
+	if err != nil {
+		return fmt.Errorf("could not open db: %w", err)
+	}
+
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			return errors.Join(fmt.Errorf("could not create schema: %w", err), db.Close())
+		}
+	}
+
+	sd.db = db
+
+	return nil
+}
+
+func (sd *SQLDatabase) Close() error {
+	return sd.db.Close()
+}
+
+func (sd *SQLDatabase) GetLatestBlockPoint() (*core.BlockPoint, error) {
+	var (
+		slot uint64
+		hash string
+	)
+
+	err := sd.db.QueryRow(`SELECT slot, hash FROM latest_block_point WHERE id = 0`).Scan(&slot, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &core.BlockPoint{
+		BlockSlot: slot,
+		BlockHash: core.NewHashFromHexString(hash),
+	}, nil
+}
+
+func (sd *SQLDatabase) GetAddressesOfInterest() ([]string, error) {
+	var data []byte
+
+	err := sd.db.QueryRow(`SELECT data FROM addresses_of_interest WHERE id = 0`).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (sd *SQLDatabase) GetTxOutput(txInput core.TxInput) (result core.TxOutput, err error) {
+	var (
+		data   []byte
+		isUsed bool
+	)
+
+	err = sd.db.QueryRow(`SELECT data, is_used FROM tx_outputs WHERE tx_hash = ? AND tx_index = ?`,
+		txInput.Hash.String(), txInput.Index).Scan(&data, &isUsed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, nil
+	} else if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, err
+	}
+
+	result.IsUsed = isUsed
+
+	return result, nil
+}
+
+func (sd *SQLDatabase) MarkConfirmedTxsProcessed(txs []*core.Tx) error {
+	tx, err := sd.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, cardTx := range txs {
+		if _, err := tx.Exec(`UPDATE txs SET processed = 1 WHERE block_slot = ? AND tx_index = ?`,
+			cardTx.BlockSlot, cardTx.Indx); err != nil {
+			return errors.Join(fmt.Errorf("could not move to processed txs: %w", err), tx.Rollback())
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (sd *SQLDatabase) GetUnprocessedConfirmedTxs(maxCnt int) ([]*core.Tx, error) {
+	rows, err := sd.db.Query(
+		`SELECT data FROM txs WHERE processed = 0 ORDER BY block_slot, tx_index LIMIT ?`, getLimit(maxCnt))
+	if err != nil {
+		return nil, err
+	}
+
+	return readJSONRows[core.Tx](rows)
+}
+
+func (sd *SQLDatabase) GetLatestConfirmedBlocks(maxCnt int) ([]*core.CardanoBlock, error) {
+	rows, err := sd.db.Query(`SELECT data FROM blocks ORDER BY slot DESC LIMIT ?`, getLimit(maxCnt))
+	if err != nil {
+		return nil, err
+	}
+
+	return readJSONRows[core.CardanoBlock](rows)
+}
+
+func (sd *SQLDatabase) GetConfirmedBlocksFrom(slotNumber uint64, maxCnt int) ([]*core.CardanoBlock, error) {
+	rows, err := sd.db.Query(
+		`SELECT data FROM blocks WHERE slot >= ? ORDER BY slot LIMIT ?`, slotNumber, getLimit(maxCnt))
+	if err != nil {
+		return nil, err
+	}
+
+	return readJSONRows[core.CardanoBlock](rows)
+}
+
+func (sd *SQLDatabase) GetTxByHash(hash core.Hash) (*core.Tx, error) {
+	var data []byte
+
+	err := sd.db.QueryRow(`SELECT data FROM txs WHERE hash = ? LIMIT 1`, hash.String()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var result *core.Tx
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (sd *SQLDatabase) GetConfirmedBlockTxs(blockSlot uint64) ([]*core.Tx, error) {
+	rows, err := sd.db.Query(`SELECT data FROM txs WHERE block_slot = ? ORDER BY tx_index`, blockSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	return readJSONRows[core.Tx](rows)
+}
+
+func (sd *SQLDatabase) GetAllTxOutputs(address string, onlyNotUsed bool) ([]*core.TxInputOutput, error) {
+	query := `SELECT tx_hash, tx_index, data, is_used FROM tx_outputs WHERE address = ?`
+	if onlyNotUsed {
+		query += ` AND is_used = 0`
+	}
+
+	rows, err := sd.db.Query(query, address)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var result []*core.TxInputOutput
+
+	for rows.Next() {
+		var (
+			item   core.TxInputOutput
+			hash   string
+			data   []byte
+			isUsed bool
+		)
+
+		if err := rows.Scan(&hash, &item.Input.Index, &data, &isUsed); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, &item.Output); err != nil {
+			return nil, err
+		}
+
+		item.Input.Hash = core.NewHashFromHexString(hash)
+		item.Output.IsUsed = isUsed
+
+		result = append(result, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return core.SortTxInputOutputs(result), nil
+}
+
+func (sd *SQLDatabase) GetUndoRecords(afterSlot uint64) ([]*core.UndoRecord, error) {
+	rows, err := sd.db.Query(`SELECT data FROM undo_records WHERE slot > ? ORDER BY slot DESC`, afterSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	return readJSONRows[core.UndoRecord](rows)
+}
+
+func (sd *SQLDatabase) PruneConfirmedBlocks(slotNumber uint64, maxCnt int) (int, error) {
+	return sd.prune(
+		`DELETE FROM blocks WHERE slot IN (SELECT slot FROM blocks WHERE slot < ? ORDER BY slot LIMIT ?)`,
+		slotNumber, maxCnt)
+}
+
+func (sd *SQLDatabase) PruneProcessedTxs(slotNumber uint64, maxCnt int) (int, error) {
+	return sd.prune(
+		`DELETE FROM txs WHERE (block_slot, tx_index) IN (
+			SELECT block_slot, tx_index FROM txs WHERE processed = 1 AND block_slot < ?
+			ORDER BY block_slot, tx_index LIMIT ?)`,
+		slotNumber, maxCnt)
+}
+
+func (sd *SQLDatabase) PruneUsedTxOutputs(slotNumber uint64, maxCnt int) (int, error) {
+	return sd.prune(
+		`DELETE FROM tx_outputs WHERE (tx_hash, tx_index) IN (
+			SELECT tx_hash, tx_index FROM tx_outputs WHERE is_used = 1 AND slot < ? LIMIT ?)`,
+		slotNumber, maxCnt)
+}
+
+func (sd *SQLDatabase) prune(query string, slotNumber uint64, maxCnt int) (int, error) {
+	result, err := sd.db.Exec(query, slotNumber, getLimit(maxCnt))
+	if err != nil {
+		return 0, err
+	}
+
+	cnt, err := result.RowsAffected()
+
+	return int(cnt), err
+}
+
+func (sd *SQLDatabase) OpenTx() core.DBTransactionWriter {
+	return &SQLTransactionWriter{
+		db: sd.db,
+	}
+}
+
+func readJSONRows[T any](rows *sql.Rows) ([]*T, error) {
+	defer rows.Close()
+
+	var result []*T
+
+	for rows.Next() {
+		var data []byte
+
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var item *T
+
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getLimit returns value for the LIMIT clause (negative value means there is no limit)
+func getLimit(maxCnt int) int {
+	if maxCnt > 0 {
+		return maxCnt
+	}
+
+	return -1
+}
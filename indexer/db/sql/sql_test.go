@@ -0,0 +1,389 @@
+package indexersql
+
+import (
+	"path/filepath"
+	"testing"
+
+	indexer "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase(t *testing.T) {
+	t.Parallel()
+
+	initDB := func(t *testing.T) *SQLDatabase {
+		t.Helper()
+
+		db := NewSQLiteDatabase()
+		require.NoError(t, db.Init(filepath.Join(t.TempDir(), "temp_test.db")))
+
+		t.Cleanup(func() {
+			require.NoError(t, db.Close())
+		})
+
+		return db
+	}
+
+	t.Run("InitDatabaseTwice", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "temp_test.db")
+
+		db := NewSQLiteDatabase()
+		require.NoError(t, db.Init(filePath))
+		require.NoError(t, db.Close())
+
+		db = NewSQLiteDatabase()
+		require.NoError(t, db.Init(filePath))
+		require.NoError(t, db.Close())
+	})
+
+	t.Run("GetLatestBlockPoint", func(t *testing.T) {
+		db := initDB(t)
+
+		blockPoint, err := db.GetLatestBlockPoint()
+		require.NoError(t, err)
+		require.Nil(t, blockPoint)
+
+		blockPoint2 := &indexer.BlockPoint{BlockSlot: 2, BlockHash: indexer.Hash{12}}
+
+		require.NoError(t, db.OpenTx().
+			SetLatestBlockPoint(&indexer.BlockPoint{BlockSlot: 1, BlockHash: indexer.Hash{1}}).
+			SetLatestBlockPoint(blockPoint2).
+			Execute())
+
+		blockPoint, err = db.GetLatestBlockPoint()
+		require.NoError(t, err)
+		require.Equal(t, blockPoint2, blockPoint)
+	})
+
+	t.Run("GetAddressesOfInterest", func(t *testing.T) {
+		db := initDB(t)
+
+		addresses, err := db.GetAddressesOfInterest()
+		require.NoError(t, err)
+		require.Nil(t, addresses)
+
+		require.NoError(t, db.OpenTx().SetAddressesOfInterest([]string{"addr1", "addr2"}).Execute())
+
+		addresses, err = db.GetAddressesOfInterest()
+		require.NoError(t, err)
+		require.Equal(t, []string{"addr1", "addr2"}, addresses)
+
+		require.NoError(t, db.OpenTx().SetAddressesOfInterest(nil).Execute())
+
+		addresses, err = db.GetAddressesOfInterest()
+		require.NoError(t, err)
+		require.NotNil(t, addresses)
+		require.Empty(t, addresses)
+	})
+
+	t.Run("GetTxOutput", func(t *testing.T) {
+		db := initDB(t)
+
+		txInOut := &indexer.TxInputOutput{
+			Input: indexer.TxInput{Hash: indexer.Hash{1, 2}, Index: 1},
+			Output: indexer.TxOutput{
+				Address: "addr_out_1",
+				Amount:  1000000,
+				Slot:    10,
+				Datum:   []byte{1, 2},
+				Tokens:  []indexer.TokenAmount{{PolicyID: "1", Name: "test", Amount: 100}},
+			},
+		}
+
+		txOutput, err := db.GetTxOutput(txInOut.Input)
+		require.NoError(t, err)
+		require.Empty(t, txOutput)
+
+		require.NoError(t, db.OpenTx().AddTxOutputs([]*indexer.TxInputOutput{txInOut}).Execute())
+
+		txOutput, err = db.GetTxOutput(txInOut.Input)
+		require.NoError(t, err)
+		require.Equal(t, txInOut.Output, txOutput)
+	})
+
+	t.Run("GetConfirmedBlocks", func(t *testing.T) {
+		db := initDB(t)
+
+		blocks, err := db.GetLatestConfirmedBlocks(10)
+		require.NoError(t, err)
+		require.Empty(t, blocks)
+
+		dbTx := db.OpenTx()
+
+		for i := uint64(1); i <= 4; i++ {
+			dbTx.AddConfirmedBlock(&indexer.CardanoBlock{
+				Slot: i * 10, Hash: indexer.Hash{byte(i)}, Number: i, Txs: []indexer.Hash{{byte(i), 1}},
+			})
+		}
+
+		require.NoError(t, dbTx.Execute())
+
+		blocks, err = db.GetLatestConfirmedBlocks(3)
+		require.NoError(t, err)
+		require.Len(t, blocks, 3)
+		require.Equal(t, uint64(40), blocks[0].Slot)
+		require.Equal(t, uint64(20), blocks[2].Slot)
+		require.Equal(t, []indexer.Hash{{4, 1}}, blocks[0].Txs)
+
+		blocks, err = db.GetConfirmedBlocksFrom(15, 2)
+		require.NoError(t, err)
+		require.Len(t, blocks, 2)
+		require.Equal(t, uint64(20), blocks[0].Slot)
+		require.Equal(t, uint64(30), blocks[1].Slot)
+
+		blocks, err = db.GetConfirmedBlocksFrom(20, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 3)
+	})
+
+	t.Run("ConfirmedTxs", func(t *testing.T) {
+		db := initDB(t)
+
+		txs := []*indexer.Tx{
+			{BlockSlot: 2, Indx: 0, Hash: indexer.Hash{3}, Fee: 10},
+			{BlockSlot: 1, Indx: 1, Hash: indexer.Hash{2}, Metadata: []byte{1}},
+			{BlockSlot: 1, Indx: 0, Hash: indexer.Hash{1}},
+		}
+
+		require.NoError(t, db.OpenTx().AddConfirmedTxs(txs).Execute())
+
+		unprocessedTxs, err := db.GetUnprocessedConfirmedTxs(0)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.Tx{txs[2], txs[1], txs[0]}, unprocessedTxs)
+
+		require.NoError(t, db.MarkConfirmedTxsProcessed(txs[1:]))
+
+		unprocessedTxs, err = db.GetUnprocessedConfirmedTxs(1)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.Tx{txs[0]}, unprocessedTxs)
+
+		for _, cardTx := range txs {
+			result, err := db.GetTxByHash(cardTx.Hash)
+			require.NoError(t, err)
+			require.Equal(t, cardTx, result)
+		}
+
+		result, err := db.GetTxByHash(indexer.Hash{4})
+		require.NoError(t, err)
+		require.Nil(t, result)
+
+		blockTxs, err := db.GetConfirmedBlockTxs(1)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.Tx{txs[2], txs[1]}, blockTxs)
+
+		blockTxs, err = db.GetConfirmedBlockTxs(3)
+		require.NoError(t, err)
+		require.Empty(t, blockTxs)
+	})
+
+	t.Run("GetAllTxOutputs", func(t *testing.T) {
+		db := initDB(t)
+
+		txInOuts := []*indexer.TxInputOutput{
+			{
+				Input:  indexer.TxInput{Hash: indexer.Hash{2}, Index: 0},
+				Output: indexer.TxOutput{Address: "addr1", Amount: 2},
+			},
+			{
+				Input:  indexer.TxInput{Hash: indexer.Hash{1}, Index: 1},
+				Output: indexer.TxOutput{Address: "addr1", Amount: 1},
+			},
+			{
+				Input:  indexer.TxInput{Hash: indexer.Hash{1}, Index: 2},
+				Output: indexer.TxOutput{Address: "addr2", Amount: 3},
+			},
+		}
+
+		require.NoError(t, db.OpenTx().AddTxOutputs(txInOuts).Execute())
+		require.NoError(t, db.OpenTx().RemoveTxOutputs([]indexer.TxInput{txInOuts[0].Input}, true).Execute())
+
+		outputs, err := db.GetAllTxOutputs("addr1", false)
+		require.NoError(t, err)
+		require.Len(t, outputs, 2)
+		require.Equal(t, txInOuts[1], outputs[0])
+		require.Equal(t, txInOuts[0].Input, outputs[1].Input)
+		require.True(t, outputs[1].Output.IsUsed)
+
+		outputs, err = db.GetAllTxOutputs("addr1", true)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.TxInputOutput{txInOuts[1]}, outputs)
+
+		require.NoError(t, db.OpenTx().RemoveTxOutputs([]indexer.TxInput{txInOuts[1].Input}, false).Execute())
+
+		outputs, err = db.GetAllTxOutputs("addr1", false)
+		require.NoError(t, err)
+		require.Len(t, outputs, 1)
+
+		require.NoError(t, db.OpenTx().DeleteAllTxOutputsPhysically().Execute())
+
+		outputs, err = db.GetAllTxOutputs("addr2", false)
+		require.NoError(t, err)
+		require.Empty(t, outputs)
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		db := initDB(t)
+
+		txs := []*indexer.Tx{{BlockSlot: 1, Indx: 0}, {BlockSlot: 1, Indx: 1}, {BlockSlot: 5}, {BlockSlot: 6}}
+		txOutputs := []*indexer.TxInputOutput{
+			{Input: indexer.TxInput{Hash: indexer.Hash{1}}, Output: indexer.TxOutput{Address: "a", Slot: 1}},
+			{Input: indexer.TxInput{Hash: indexer.Hash{2}}, Output: indexer.TxOutput{Address: "a", Slot: 2}},
+			{Input: indexer.TxInput{Hash: indexer.Hash{3}}, Output: indexer.TxOutput{Address: "a", Slot: 8}},
+		}
+		dbTx := db.OpenTx().AddConfirmedTxs(txs).AddTxOutputs(txOutputs)
+
+		for _, slot := range []uint64{1, 2, 5, 6} {
+			dbTx.AddConfirmedBlock(&indexer.CardanoBlock{Slot: slot})
+		}
+
+		require.NoError(t, dbTx.Execute())
+		require.NoError(t, db.MarkConfirmedTxsProcessed(txs[:3]))
+		require.NoError(t, db.OpenTx().RemoveTxOutputs([]indexer.TxInput{
+			txOutputs[0].Input, txOutputs[2].Input,
+		}, true).Execute())
+
+		cnt, err := db.PruneConfirmedBlocks(5, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+
+		cnt, err = db.PruneConfirmedBlocks(5, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+
+		blocks, err := db.GetConfirmedBlocksFrom(0, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 2)
+		require.Equal(t, uint64(5), blocks[0].Slot)
+
+		cnt, err = db.PruneProcessedTxs(6, 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, cnt)
+
+		unprocessedTxs, err := db.GetUnprocessedConfirmedTxs(0)
+		require.NoError(t, err)
+		require.Len(t, unprocessedTxs, 1)
+
+		cnt, err = db.PruneUsedTxOutputs(5, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+
+		outputs, err := db.GetAllTxOutputs("a", false)
+		require.NoError(t, err)
+		require.Len(t, outputs, 2)
+		require.Equal(t, txOutputs[1], outputs[0])
+
+		// output is pruned by its creation slot (8) regardless of when it was spent
+		cnt, err = db.PruneUsedTxOutputs(9, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, cnt)
+
+		outputs, err = db.GetAllTxOutputs("a", false)
+		require.NoError(t, err)
+		require.Equal(t, txOutputs[1:2], outputs)
+	})
+
+	t.Run("UndoJournal", func(t *testing.T) {
+		db := initDB(t)
+
+		outputA := &indexer.TxInputOutput{
+			Input: indexer.TxInput{Hash: indexer.Hash{1}}, Output: indexer.TxOutput{Address: "a", Amount: 1, Slot: 10},
+		}
+		outputB := &indexer.TxInputOutput{
+			Input: indexer.TxInput{Hash: indexer.Hash{2}}, Output: indexer.TxOutput{Address: "a", Amount: 2, Slot: 20},
+		}
+		points := []*indexer.BlockPoint{
+			{BlockSlot: 10, BlockHash: indexer.Hash{10}},
+			{BlockSlot: 20, BlockHash: indexer.Hash{20}},
+			{BlockSlot: 30, BlockHash: indexer.Hash{30}},
+		}
+		records := []*indexer.UndoRecord{
+			{
+				BlockPoint:     *points[0],
+				PrevBlockPoint: &indexer.BlockPoint{},
+				TxIndexes:      []uint32{0},
+				AddedTxOutputs: []indexer.TxInput{outputA.Input},
+			},
+			{
+				BlockPoint:       *points[1],
+				PrevBlockPoint:   points[0],
+				TxIndexes:        []uint32{0},
+				AddedTxOutputs:   []indexer.TxInput{outputB.Input},
+				RemovedTxOutputs: []*indexer.TxInputOutput{outputA},
+			},
+			{
+				BlockPoint:     *points[2],
+				PrevBlockPoint: points[1],
+			},
+		}
+
+		require.NoError(t, db.OpenTx().
+			AddTxOutputs([]*indexer.TxInputOutput{outputA}).
+			AddConfirmedTxs([]*indexer.Tx{{BlockSlot: 10}}).
+			AddConfirmedBlock(&indexer.CardanoBlock{Slot: 10}).
+			SetLatestBlockPoint(points[0]).
+			AddUndoRecord(records[0], 2).
+			Execute())
+		require.NoError(t, db.OpenTx().
+			RemoveTxOutputs([]indexer.TxInput{outputA.Input}, true).
+			AddTxOutputs([]*indexer.TxInputOutput{outputB}).
+			AddConfirmedTxs([]*indexer.Tx{{BlockSlot: 20}}).
+			AddConfirmedBlock(&indexer.CardanoBlock{Slot: 20}).
+			SetLatestBlockPoint(points[1]).
+			AddUndoRecord(records[1], 2).
+			Execute())
+		require.NoError(t, db.MarkConfirmedTxsProcessed([]*indexer.Tx{{BlockSlot: 20}}))
+		require.NoError(t, db.OpenTx().
+			AddConfirmedBlock(&indexer.CardanoBlock{Slot: 30}).
+			SetLatestBlockPoint(points[2]).
+			AddUndoRecord(records[2], 2).
+			Execute())
+
+		// the oldest record is removed because of the depth
+		undoRecords, err := db.GetUndoRecords(0)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.UndoRecord{records[2], records[1]}, undoRecords)
+
+		undoRecords, err = db.GetUndoRecords(20)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.UndoRecord{records[2]}, undoRecords)
+
+		require.NoError(t, db.OpenTx().RevertUndoRecord(records[2]).RevertUndoRecord(records[1]).Execute())
+
+		latestPoint, err := db.GetLatestBlockPoint()
+		require.NoError(t, err)
+		require.Equal(t, points[0], latestPoint)
+
+		outputs, err := db.GetAllTxOutputs("a", false)
+		require.NoError(t, err)
+		require.Equal(t, []*indexer.TxInputOutput{outputA}, outputs)
+
+		blocks, err := db.GetLatestConfirmedBlocks(0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		require.Equal(t, uint64(10), blocks[0].Slot)
+
+		txs, err := db.GetUnprocessedConfirmedTxs(0)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		require.Equal(t, uint64(10), txs[0].BlockSlot)
+
+		undoRecords, err = db.GetUndoRecords(0)
+		require.NoError(t, err)
+		require.Empty(t, undoRecords)
+	})
+
+	t.Run("ExecuteRollback", func(t *testing.T) {
+		db := initDB(t)
+
+		err := db.OpenTx().
+			SetLatestBlockPoint(&indexer.BlockPoint{BlockSlot: 1}).
+			AddTxOutputs([]*indexer.TxInputOutput{{}}).
+			AddTxOutputs([]*indexer.TxInputOutput{{Output: indexer.TxOutput{Amount: 1 << 63}}}).
+			Execute()
+		require.Error(t, err)
+
+		blockPoint, err := db.GetLatestBlockPoint()
+		require.NoError(t, err)
+		require.Nil(t, blockPoint)
+	})
+}
@@ -0,0 +1,262 @@
+package indexersql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	core "github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+)
+
+type txOperation func(tx *sql.Tx) error
+
+type SQLTransactionWriter struct {
+	db         *sql.DB
+	operations []txOperation
+}
+
+var _ core.DBTransactionWriter = (*SQLTransactionWriter)(nil)
+
+func (tw *SQLTransactionWriter) SetLatestBlockPoint(point *core.BlockPoint) core.DBTransactionWriter {
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(
+			`INSERT INTO latest_block_point (id, slot, hash) VALUES (0, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET slot = excluded.slot, hash = excluded.hash`,
+			point.BlockSlot, point.BlockHash.String()); err != nil {
+			return fmt.Errorf("latest block point write error: %w", err)
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) SetAddressesOfInterest(addresses []string) core.DBTransactionWriter {
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		if addresses == nil {
+			addresses = []string{}
+		}
+
+		bytes, err := json.Marshal(addresses)
+		if err != nil {
+			return fmt.Errorf("could not marshal addresses of interest: %w", err)
+		}
+
+		if _, err := tx.Exec(
+			`INSERT INTO addresses_of_interest (id, data) VALUES (0, ?)
+			ON CONFLICT (id) DO UPDATE SET data = excluded.data`, string(bytes)); err != nil {
+			return fmt.Errorf("addresses of interest write error: %w", err)
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) AddTxOutputs(txOutputs []*core.TxInputOutput) core.DBTransactionWriter {
+	if len(txOutputs) == 0 {
+		return tw
+	}
+
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(
+			`INSERT INTO tx_outputs (tx_hash, tx_index, address, slot, amount, is_used, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (tx_hash, tx_index) DO UPDATE SET address = excluded.address, slot = excluded.slot,
+			amount = excluded.amount, is_used = excluded.is_used, data = excluded.data`)
+		if err != nil {
+			return err
+		}
+
+		defer stmt.Close()
+
+		for _, inpOut := range txOutputs {
+			bytes, err := json.Marshal(inpOut.Output)
+			if err != nil {
+				return fmt.Errorf("could not marshal tx output: %w", err)
+			}
+
+			if _, err := stmt.Exec(inpOut.Input.Hash.String(), inpOut.Input.Index, inpOut.Output.Address,
+				inpOut.Output.Slot, inpOut.Output.Amount, inpOut.Output.IsUsed, string(bytes)); err != nil {
+				return fmt.Errorf("tx output write error: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) AddConfirmedBlock(block *core.CardanoBlock) core.DBTransactionWriter {
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		bytes, err := json.Marshal(block)
+		if err != nil {
+			return fmt.Errorf("could not marshal confirmed block: %w", err)
+		}
+
+		if _, err := tx.Exec(
+			`INSERT INTO blocks (slot, hash, number, data) VALUES (?, ?, ?, ?)
+			ON CONFLICT (slot) DO UPDATE SET hash = excluded.hash, number = excluded.number, data = excluded.data`,
+			block.Slot, block.Hash.String(), block.Number, string(bytes)); err != nil {
+			return fmt.Errorf("confirmed block write error: %w", err)
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) AddConfirmedTxs(txs []*core.Tx) core.DBTransactionWriter {
+	if len(txs) == 0 {
+		return tw
+	}
+
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(
+			`INSERT INTO txs (block_slot, tx_index, hash, block_hash, processed, data) VALUES (?, ?, ?, ?, 0, ?)
+			ON CONFLICT (block_slot, tx_index) DO UPDATE SET hash = excluded.hash, block_hash = excluded.block_hash,
+			processed = 0, data = excluded.data`)
+		if err != nil {
+			return err
+		}
+
+		defer stmt.Close()
+
+		for _, cardTx := range txs {
+			bytes, err := json.Marshal(cardTx)
+			if err != nil {
+				return fmt.Errorf("could not marshal confirmed tx: %w", err)
+			}
+
+			if _, err := stmt.Exec(cardTx.BlockSlot, cardTx.Indx, cardTx.Hash.String(),
+				cardTx.BlockHash.String(), string(bytes)); err != nil {
+				return fmt.Errorf("confirmed tx write error: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) RemoveTxOutputs(txInputs []core.TxInput, softDelete bool) core.DBTransactionWriter {
+	if len(txInputs) == 0 {
+		return tw
+	}
+
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		query := `DELETE FROM tx_outputs WHERE tx_hash = ? AND tx_index = ?`
+		if softDelete {
+			query = `UPDATE tx_outputs SET is_used = 1 WHERE tx_hash = ? AND tx_index = ?`
+		}
+
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return err
+		}
+
+		defer stmt.Close()
+
+		for _, inp := range txInputs {
+			if _, err := stmt.Exec(inp.Hash.String(), inp.Index); err != nil {
+				return fmt.Errorf("delete utxo error: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) DeleteAllTxOutputsPhysically() core.DBTransactionWriter {
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM tx_outputs`)
+
+		return err
+	})
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) AddUndoRecord(record *core.UndoRecord, maxDepth uint) core.DBTransactionWriter {
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		bytes, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("could not marshal undo record: %w", err)
+		}
+
+		if _, err := tx.Exec(
+			`INSERT INTO undo_records (slot, data) VALUES (?, ?)
+			ON CONFLICT (slot) DO UPDATE SET data = excluded.data`,
+			record.BlockPoint.BlockSlot, string(bytes)); err != nil {
+			return fmt.Errorf("undo record write error: %w", err)
+		}
+
+		if _, err := tx.Exec(
+			`DELETE FROM undo_records WHERE slot NOT IN (SELECT slot FROM undo_records ORDER BY slot DESC LIMIT ?)`,
+			maxDepth); err != nil {
+			return fmt.Errorf("undo record delete error: %w", err)
+		}
+
+		return nil
+	})
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) RevertUndoRecord(record *core.UndoRecord) core.DBTransactionWriter {
+	tw.RemoveTxOutputs(record.AddedTxOutputs, false)
+	tw.AddTxOutputs(record.RemovedTxOutputs)
+
+	tw.operations = append(tw.operations, func(tx *sql.Tx) error {
+		slot := record.BlockPoint.BlockSlot
+
+		if _, err := tx.Exec(`DELETE FROM blocks WHERE slot = ?`, slot); err != nil {
+			return fmt.Errorf("confirmed block delete error: %w", err)
+		}
+
+		for _, indx := range record.TxIndexes {
+			if _, err := tx.Exec(`DELETE FROM txs WHERE block_slot = ? AND tx_index = ?`, slot, indx); err != nil {
+				return fmt.Errorf("confirmed tx delete error: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM undo_records WHERE slot = ?`, slot); err != nil {
+			return fmt.Errorf("undo record delete error: %w", err)
+		}
+
+		return nil
+	})
+
+	if record.PrevBlockPoint != nil {
+		tw.SetLatestBlockPoint(record.PrevBlockPoint)
+	}
+
+	return tw
+}
+
+func (tw *SQLTransactionWriter) Execute() error {
+	defer func() {
+		tw.operations = nil
+	}()
+
+	tx, err := tw.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, op := range tw.operations {
+		if err := op(tx); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	return tx.Commit()
+}
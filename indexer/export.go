@@ -0,0 +1,232 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	exportFormatName = "cardano-indexer-export"
+	ExportVersion    = 1
+
+	importBatchSize = 1000
+)
+
+type ExportEntryType byte
+
+const (
+	ExportEntryEnd ExportEntryType = iota
+	ExportEntryTxOutput
+	ExportEntryConfirmedBlock
+	ExportEntryUnprocessedTx
+	ExportEntryProcessedTx
+	ExportEntryLatestBlockPoint
+	ExportEntryAddressesOfInterest
+	ExportEntryUndoRecord
+)
+
+type exportHeader struct {
+	Format  string `cbor:"format"`
+	Version int    `cbor:"version"`
+}
+
+// ExportEntry is a single database entry of the export stream. Only the field matching the type is set
+type ExportEntry struct {
+	Type       ExportEntryType `cbor:"t"`
+	TxOutput   *TxInputOutput  `cbor:"o,omitempty"`
+	Block      *CardanoBlock   `cbor:"b,omitempty"`
+	Tx         *Tx             `cbor:"tx,omitempty"`
+	BlockPoint *BlockPoint     `cbor:"p,omitempty"`
+	Addresses  []string        `cbor:"a,omitempty"`
+	UndoRecord *UndoRecord     `cbor:"u,omitempty"`
+	// Count is number of all the entries before the end entry
+	Count uint64 `cbor:"c,omitempty"`
+}
+
+// ExportWriter writes backend agnostic export stream: versioned header, entries and the end entry
+type ExportWriter struct {
+	encoder *cbor.Encoder
+	count   uint64
+}
+
+func NewExportWriter(w io.Writer) (*ExportWriter, error) {
+	encoder := cbor.NewEncoder(w)
+
+	if err := encoder.Encode(&exportHeader{Format: exportFormatName, Version: ExportVersion}); err != nil {
+		return nil, err
+	}
+
+	return &ExportWriter{encoder: encoder}, nil
+}
+
+func (ew *ExportWriter) Write(entry *ExportEntry) error {
+	if err := ew.encoder.Encode(entry); err != nil {
+		return err
+	}
+
+	ew.count++
+
+	return nil
+}
+
+// Close writes the end entry, so truncated streams can be detected
+func (ew *ExportWriter) Close() error {
+	return ew.encoder.Encode(&ExportEntry{Type: ExportEntryEnd, Count: ew.count})
+}
+
+type ExportReader struct {
+	decoder *cbor.Decoder
+	count   uint64
+	isEnd   bool
+}
+
+func NewExportReader(r io.Reader) (*ExportReader, error) {
+	var header exportHeader
+
+	decoder := cbor.NewDecoder(r)
+
+	if err := decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("invalid export header: %w", err)
+	}
+
+	if header.Format != exportFormatName {
+		return nil, fmt.Errorf("invalid export format: %s", header.Format)
+	}
+
+	if header.Version != ExportVersion {
+		return nil, fmt.Errorf("unsupported export version: %d", header.Version)
+	}
+
+	return &ExportReader{decoder: decoder}, nil
+}
+
+// Next returns the next entry or io.EOF after the end entry
+func (er *ExportReader) Next() (*ExportEntry, error) {
+	if er.isEnd {
+		return nil, io.EOF
+	}
+
+	var entry ExportEntry
+
+	if err := er.decoder.Decode(&entry); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, fmt.Errorf("invalid export entry: %w", err)
+	}
+
+	if entry.Type == ExportEntryEnd {
+		if entry.Count != er.count {
+			return nil, fmt.Errorf("invalid export entries count: expected %d, read %d", entry.Count, er.count)
+		}
+
+		er.isEnd = true
+
+		return nil, io.EOF
+	}
+
+	er.count++
+
+	return &entry, nil
+}
+
+// ImportDatabase imports export stream into the empty database using only database interface methods,
+// so it can be used by any backend. Entries are written in batches
+func ImportDatabase(db Database, r io.Reader) error {
+	latestPoint, err := db.GetLatestBlockPoint()
+	if err != nil {
+		return err
+	}
+
+	if latestPoint != nil {
+		return fmt.Errorf("database is not empty, latest block point: %s", latestPoint)
+	}
+
+	reader, err := NewExportReader(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		dbTx             = db.OpenTx()
+		processedTxs     []*Tx
+		batchCnt         int
+		latestBlockPoint *BlockPoint
+	)
+
+	flush := func() error {
+		if batchCnt == 0 {
+			return nil
+		}
+
+		if err := dbTx.Execute(); err != nil {
+			return err
+		}
+
+		if len(processedTxs) > 0 {
+			if err := db.MarkConfirmedTxsProcessed(processedTxs); err != nil {
+				return err
+			}
+		}
+
+		dbTx, processedTxs, batchCnt = db.OpenTx(), nil, 0
+
+		return nil
+	}
+
+	for {
+		entry, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		switch entry.Type {
+		case ExportEntryTxOutput:
+			dbTx.AddTxOutputs([]*TxInputOutput{entry.TxOutput})
+		case ExportEntryConfirmedBlock:
+			dbTx.AddConfirmedBlock(entry.Block)
+		case ExportEntryUnprocessedTx:
+			dbTx.AddConfirmedTxs([]*Tx{entry.Tx})
+		case ExportEntryProcessedTx:
+			// processed txs are added as confirmed ones and marked as processed after the batch is written
+			dbTx.AddConfirmedTxs([]*Tx{entry.Tx})
+
+			processedTxs = append(processedTxs, entry.Tx)
+		case ExportEntryLatestBlockPoint:
+			latestBlockPoint = entry.BlockPoint
+
+			continue
+		case ExportEntryAddressesOfInterest:
+			dbTx.SetAddressesOfInterest(entry.Addresses)
+		case ExportEntryUndoRecord:
+			// journal depth is limited by the records of the exported database
+			dbTx.AddUndoRecord(entry.UndoRecord, math.MaxInt32)
+		default:
+			return fmt.Errorf("unknown export entry type: %d", entry.Type)
+		}
+
+		if batchCnt++; batchCnt >= importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	// latest block point is written at the end, so partially imported database is still considered empty
+	if latestBlockPoint != nil {
+		return db.OpenTx().SetLatestBlockPoint(latestBlockPoint).Execute()
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package indexer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportWriterReader(t *testing.T) {
+	t.Parallel()
+
+	entries := []*ExportEntry{
+		{
+			Type: ExportEntryTxOutput,
+			TxOutput: &TxInputOutput{
+				Input:  TxInput{Hash: Hash{1}, Index: 2},
+				Output: TxOutput{Address: addresses[0], Amount: 100, IsUsed: true},
+			},
+		},
+		{Type: ExportEntryAddressesOfInterest, Addresses: []string{addresses[0]}},
+		{Type: ExportEntryLatestBlockPoint, BlockPoint: &BlockPoint{BlockSlot: 10, BlockHash: Hash{3}}},
+	}
+
+	var buffer bytes.Buffer
+
+	writer, err := NewExportWriter(&buffer)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		require.NoError(t, writer.Write(entry))
+	}
+
+	require.NoError(t, writer.Close())
+
+	readAll := func(data []byte) ([]*ExportEntry, error) {
+		reader, err := NewExportReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		var result []*ExportEntry
+
+		for {
+			entry, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			result = append(result, entry)
+		}
+	}
+
+	result, err := readAll(buffer.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, entries, result)
+
+	_, err = readAll(buffer.Bytes()[:buffer.Len()-3])
+	require.Error(t, err)
+
+	header, err := cbor.Marshal(&exportHeader{Format: exportFormatName, Version: ExportVersion + 1})
+	require.NoError(t, err)
+
+	_, err = readAll(header)
+	require.ErrorContains(t, err, "unsupported export version")
+
+	header, err = cbor.Marshal(&exportHeader{Format: exportFormatName, Version: ExportVersion})
+	require.NoError(t, err)
+
+	end, err := cbor.Marshal(&ExportEntry{Type: ExportEntryEnd, Count: 1})
+	require.NoError(t, err)
+
+	_, err = readAll(append(header, end...))
+	require.ErrorContains(t, err, "invalid export entries count")
+}
@@ -0,0 +1,254 @@
+package gouroboros
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/protocol/blockfetch"
+	"github.com/blinklabs-io/gouroboros/protocol/common"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	prefetchQueueSize   = 16
+	prefetchWaitTimeout = time.Minute
+)
+
+// FetchStats are the block fetching counters of the block syncer
+type FetchStats struct {
+	// RangeRequests is the number of the range requests sent by the prefetcher
+	RangeRequests uint64 `json:"rangeRequests"`
+	// PrefetchedBlocks is the number of the blocks received through the range requests
+	PrefetchedBlocks uint64 `json:"prefetchedBlocks"`
+	// SingleFetches is the number of the blocks which were not prefetched and had to be fetched one by one
+	SingleFetches uint64 `json:"singleFetches"`
+}
+
+type fetchStats struct {
+	rangeRequests    atomic.Uint64
+	prefetchedBlocks atomic.Uint64
+	singleFetches    atomic.Uint64
+}
+
+func (s *fetchStats) get() FetchStats {
+	return FetchStats{
+		RangeRequests:    s.rangeRequests.Load(),
+		PrefetchedBlocks: s.prefetchedBlocks.Load(),
+		SingleFetches:    s.singleFetches.Load(),
+	}
+}
+
+type prefetchBatch struct {
+	points []common.Point
+	done   chan struct{}
+}
+
+type blockRangeFetcher interface {
+	GetBlockRange(start common.Point, end common.Point) error
+}
+
+// blockPrefetcher collects headers received through the chain-sync and requests their bodies
+// with the range block-fetch requests, so the bodies are in the received blocks cache before they are needed
+type blockPrefetcher struct {
+	batchSize int
+	cache     *receivedBlocksCache
+	stats     *fetchStats
+	logger    hclog.Logger
+
+	pending  []common.Point
+	inFlight map[indexer.Hash]*prefetchBatch
+	// requested batches in the order of the range requests. The node sends the ranges one after another
+	requested []*prefetchBatch
+	requestCh chan *prefetchBatch
+	lock      sync.Mutex
+}
+
+func newBlockPrefetcher(
+	batchSize int, cache *receivedBlocksCache, stats *fetchStats, logger hclog.Logger,
+) *blockPrefetcher {
+	return &blockPrefetcher{
+		batchSize: batchSize,
+		cache:     cache,
+		stats:     stats,
+		logger:    logger,
+		inFlight:  map[indexer.Hash]*prefetchBatch{},
+		requestCh: make(chan *prefetchBatch, prefetchQueueSize),
+	}
+}
+
+// run sends range requests until the close channel is closed
+func (p *blockPrefetcher) run(fetcher blockRangeFetcher, closeCh <-chan struct{}) {
+	for {
+		select {
+		case <-closeCh:
+			p.completeAll()
+
+			return
+		case batch := <-p.requestCh:
+			start, end := batch.points[0], batch.points[len(batch.points)-1]
+
+			p.logger.Debug("Prefetch blocks", "from", start.Slot, "to", end.Slot, "count", len(batch.points))
+
+			p.stats.rangeRequests.Add(1)
+
+			p.lock.Lock()
+			p.requested = append(p.requested, batch)
+			p.lock.Unlock()
+
+			if err := fetcher.GetBlockRange(start, end); err != nil {
+				p.logger.Warn("Failed to prefetch blocks", "from", start.Slot, "to", end.Slot, "err", err)
+
+				p.completeBatch(batch)
+			}
+		}
+	}
+}
+
+// addHeader adds the header point to the pending batch and requests the batch if it is full
+func (p *blockPrefetcher) addHeader(point common.Point) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.pending = append(p.pending, point)
+
+	if len(p.pending) >= p.batchSize {
+		p.requestPendingNoLock()
+	}
+}
+
+// reset discards pending headers after the roll backward
+func (p *blockPrefetcher) reset() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.pending = nil
+}
+
+// wait waits until the block is received if it is pending or in flight
+func (p *blockPrefetcher) wait(hash indexer.Hash) {
+	p.lock.Lock()
+
+	batch := p.inFlight[hash]
+	if batch == nil && p.isPendingNoLock(hash) {
+		// block is needed right now -> do not wait for the batch to be full
+		batch = p.requestPendingNoLock()
+	}
+
+	p.lock.Unlock()
+
+	if batch == nil {
+		return
+	}
+
+	select {
+	case <-batch.done:
+	case <-time.After(prefetchWaitTimeout):
+	}
+}
+
+// blockFetchCallback is called by the block-fetch protocol for every block of the range request.
+// The batch is completed when the block with the end slot (or a later one) is received,
+// so the waiters are released even if the node sends the blocks of another fork
+func (p *blockPrefetcher) blockFetchCallback(_ blockfetch.CallbackContext, _ uint, block ledger.Block) error {
+	hash := indexer.NewHashFromHexString(block.Hash())
+
+	p.cache.add(hash, block)
+	p.stats.prefetchedBlocks.Add(1)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.inFlight, hash)
+
+	if len(p.requested) == 0 {
+		return nil
+	}
+
+	if batch := p.requested[0]; block.SlotNumber() >= batch.points[len(batch.points)-1].Slot {
+		p.completeBatchNoLock(batch)
+	}
+
+	return nil
+}
+
+func (p *blockPrefetcher) completeBatch(batch *prefetchBatch) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.completeBatchNoLock(batch)
+}
+
+// completeBatchNoLock drops in flight blocks of the batch which are not received and releases the waiters
+func (p *blockPrefetcher) completeBatchNoLock(batch *prefetchBatch) {
+	for _, point := range batch.points {
+		if hash := indexer.Hash(point.Hash); p.inFlight[hash] == batch {
+			delete(p.inFlight, hash)
+		}
+	}
+
+	if indx := slices.Index(p.requested, batch); indx != -1 {
+		p.requested = slices.Delete(p.requested, indx, indx+1)
+	}
+
+	closeBatchNoLock(batch)
+}
+
+// completeAll releases all the waiters when the connection is closed
+func (p *blockPrefetcher) completeAll() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for hash, batch := range p.inFlight {
+		delete(p.inFlight, hash)
+		closeBatchNoLock(batch)
+	}
+
+	p.requested = nil
+}
+
+func (p *blockPrefetcher) requestPendingNoLock() *prefetchBatch {
+	batch := &prefetchBatch{
+		points: p.pending,
+		done:   make(chan struct{}),
+	}
+
+	p.pending = nil
+
+	select {
+	case p.requestCh <- batch:
+	default:
+		// blocks will be fetched one by one
+		p.logger.Debug("Prefetch queue is full", "count", len(batch.points))
+
+		return nil
+	}
+
+	// blocks can not be received before they are registered because the lock is held
+	for _, point := range batch.points {
+		p.inFlight[indexer.Hash(point.Hash)] = batch
+	}
+
+	return batch
+}
+
+func (p *blockPrefetcher) isPendingNoLock(hash indexer.Hash) bool {
+	for _, point := range p.pending {
+		if indexer.Hash(point.Hash) == hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+func closeBatchNoLock(batch *prefetchBatch) {
+	select {
+	case <-batch.done:
+	default:
+		close(batch.done)
+	}
+}
@@ -0,0 +1,134 @@
+package gouroboros
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/blinklabs-io/gouroboros/protocol/blockfetch"
+	"github.com/blinklabs-io/gouroboros/protocol/common"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+type blockRangeFetcherMock struct {
+	prefetcher *blockPrefetcher
+	ranges     [][2]uint64
+	err        error
+	// isFork delivers blocks with different hashes than the requested ones
+	isFork bool
+}
+
+func (m *blockRangeFetcherMock) GetBlockRange(start common.Point, end common.Point) error {
+	m.ranges = append(m.ranges, [2]uint64{start.Slot, end.Slot})
+
+	if m.err != nil {
+		return m.err
+	}
+
+	hashOffset := uint64(0)
+	if m.isFork {
+		hashOffset = 100
+	}
+
+	// blocks are delivered asynchronously by the block-fetch protocol
+	go func() {
+		for slot := start.Slot; slot <= end.Slot; slot++ {
+			_ = m.prefetcher.blockFetchCallback(
+				blockfetch.CallbackContext{}, 0, &testBlock{hash: getTestPointHash(slot + hashOffset).String(), slot: slot})
+		}
+	}()
+
+	return nil
+}
+
+func getTestPointHash(slot uint64) indexer.Hash {
+	return indexer.Hash{byte(slot), 0xff}
+}
+
+func getTestPoint(slot uint64) common.Point {
+	hash := getTestPointHash(slot)
+
+	return common.NewPoint(slot, hash[:])
+}
+
+func TestBlockPrefetcher(t *testing.T) {
+	t.Parallel()
+
+	stats := &fetchStats{}
+	cache := newReceivedBlocksCache(10)
+	prefetcher := newBlockPrefetcher(3, cache, stats, hclog.NewNullLogger())
+	fetcher := &blockRangeFetcherMock{prefetcher: prefetcher}
+	closeCh := make(chan struct{})
+	doneCh := make(chan struct{})
+
+	go func() {
+		prefetcher.run(fetcher, closeCh)
+		close(doneCh)
+	}()
+
+	for slot := uint64(1); slot <= 4; slot++ {
+		prefetcher.addHeader(getTestPoint(slot))
+	}
+
+	// the first batch is full and requested
+	prefetcher.wait(getTestPointHash(2))
+	require.NotNil(t, cache.get(getTestPointHash(1)))
+	require.NotNil(t, cache.get(getTestPointHash(2)))
+	require.NotNil(t, cache.get(getTestPointHash(3)))
+	require.Nil(t, cache.get(getTestPointHash(4)))
+
+	// the pending block is requested immediately when it is needed
+	prefetcher.wait(getTestPointHash(4))
+	require.NotNil(t, cache.get(getTestPointHash(4)))
+
+	// unknown block -> nothing to wait for
+	prefetcher.wait(getTestPointHash(5))
+
+	// pending headers are discarded after the roll backward
+	prefetcher.addHeader(getTestPoint(5))
+	prefetcher.reset()
+	prefetcher.wait(getTestPointHash(5))
+	require.Nil(t, cache.get(getTestPointHash(5)))
+
+	// failed request releases the waiters
+	fetcher.err = errors.New("no blocks")
+
+	prefetcher.addHeader(getTestPoint(6))
+	prefetcher.wait(getTestPointHash(6))
+	require.Nil(t, cache.get(getTestPointHash(6)))
+
+	// blocks of another fork complete the batch when the end slot is reached
+	fetcher.err = nil
+	fetcher.isFork = true
+
+	for slot := uint64(7); slot <= 9; slot++ {
+		prefetcher.addHeader(getTestPoint(slot))
+	}
+
+	waitCh := make(chan struct{})
+
+	go func() {
+		prefetcher.wait(getTestPointHash(8))
+		close(waitCh)
+	}()
+
+	select {
+	case <-waitCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch of another fork is not completed")
+	}
+
+	prefetcher.lock.Lock()
+	require.Empty(t, prefetcher.inFlight)
+	require.Empty(t, prefetcher.requested)
+	prefetcher.lock.Unlock()
+	require.Nil(t, cache.get(getTestPointHash(8)))
+
+	close(closeCh)
+	<-doneCh
+
+	require.Equal(t, [][2]uint64{{1, 3}, {4, 4}, {6, 6}, {7, 9}}, fetcher.ranges)
+	require.Equal(t, FetchStats{RangeRequests: 4, PrefetchedBlocks: 7}, stats.get())
+}
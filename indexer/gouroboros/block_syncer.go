@@ -3,6 +3,7 @@ package gouroboros
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/protocol/blockfetch"
 	"github.com/blinklabs-io/gouroboros/protocol/chainsync"
 	"github.com/blinklabs-io/gouroboros/protocol/common"
 	"github.com/hashicorp/go-hclog"
@@ -19,9 +21,39 @@ const (
 	ProtocolTCP  = "tcp"
 	ProtocolUnix = "unix"
 
+	// ConnectionModeAuto uses node-to-client for the unix socket and node-to-node otherwise
+	ConnectionModeAuto         = ""
+	ConnectionModeNodeToNode   = "nodeToNode"
+	ConnectionModeNodeToClient = "nodeToClient"
+
 	syncStartTriesDefault = 4
+	blockCacheSizeDefault = 256
 )
 
+// blockHandlerErrorPrefix is used to recognize the block syncer handler errors received from the connection
+const blockHandlerErrorPrefix = "block syncer handler failed"
+
+// blockHandlerError wraps errors of the block syncer handler so they are not treated as the peer failures
+type blockHandlerError struct {
+	err error
+}
+
+func (e *blockHandlerError) Error() string {
+	return fmt.Sprintf("%s: %v", blockHandlerErrorPrefix, e.err)
+}
+
+func (e *blockHandlerError) Unwrap() error {
+	return e.err
+}
+
+// restartRequest is handled by the single restart routine so the error handler and the tip checker
+// of the same session can not restart the synchronization twice
+type restartRequest struct {
+	address   string
+	err       error // nil if the peer is lagging behind the other peers
+	sessionCh <-chan struct{}
+}
+
 type BlockSyncerConfig struct {
 	NetworkMagic   uint32        `json:"networkMagic"`
 	NodeAddress    string        `json:"nodeAddress"`
@@ -29,10 +61,46 @@ type BlockSyncerConfig struct {
 	RestartDelay   time.Duration `json:"restartDelay"`
 	SyncStartTries int           `json:"syncStartTries"`
 	KeepAlive      bool          `json:"keepAlive"`
+	// Peers used instead of NodeAddress. On connection error syncer fails over to the next healthy peer
+	Peers []BlockSyncerPeer `json:"peers"`
+	// PeerBanDuration is how long the failed peer is skipped
+	PeerBanDuration time.Duration `json:"peerBanDuration"`
+	// TipCheckInterval enables periodic comparison of the current peer tip with tips of the other peers
+	TipCheckInterval time.Duration `json:"tipCheckInterval"`
+	// MaxTipSlotLag is the number of slots the current peer can lag behind the others before failover
+	MaxTipSlotLag uint64 `json:"maxTipSlotLag"`
+	// ConnectionMode is one of ConnectionMode* values
+	ConnectionMode string `json:"connectionMode"`
+	// BlockCacheSize is the number of the latest received blocks kept in memory (node-to-client or prefetched ones).
+	// It should be greater than the confirmation depth plus the block indexer runner queue size
+	BlockCacheSize int `json:"blockCacheSize"`
+	// PipelineLimit is the number of the pipelined chain-sync requests (values greater than 10 are not recommended)
+	PipelineLimit int `json:"pipelineLimit"`
+	// SyncedSlotLag is the max number of slots between the tip and the latest point while the syncer is synced
+	SyncedSlotLag uint64 `json:"syncedSlotLag"`
+	// BlockFetchBatchSize is the number of the block bodies requested with a single block-fetch range request
+	// in node-to-node mode. It should not be greater than the confirmation depth. Prefetching is disabled if it is <= 1
+	BlockFetchBatchSize int `json:"blockFetchBatchSize"`
 }
 
 func (bsc BlockSyncerConfig) Protocol() string {
-	if strings.HasPrefix(bsc.NodeAddress, "/") {
+	return getProtocol(bsc.NodeAddress)
+}
+
+// IsNodeToClient returns true if the node-to-client protocol should be used for the given address
+func (bsc BlockSyncerConfig) IsNodeToClient(address string) bool {
+	switch bsc.ConnectionMode {
+	case ConnectionModeNodeToClient:
+		return true
+	case ConnectionModeNodeToNode:
+		return false
+	default:
+		return getProtocol(address) == ProtocolUnix
+	}
+}
+
+func getProtocol(address string) string {
+	if strings.HasPrefix(address, "/") {
 		return ProtocolUnix
 	}
 
@@ -44,25 +112,52 @@ type BlockSyncerImpl struct {
 	blockHandler indexer.BlockSyncerHandler
 	config       *BlockSyncerConfig
 	logger       hclog.Logger
-
-	errorCh  chan error
-	closeCh  chan struct{}
-	lock     sync.Mutex
-	isClosed bool
+	peers        *peerManager
+	blocksCache  *receivedBlocksCache
+	prefetcher   *blockPrefetcher
+	fetchStats   fetchStats
+	progress     *indexer.SyncProgressTracker
+	metrics      indexer.Metrics
+	queryPeerTip func(address string) (chainsync.Tip, error)
+
+	errorCh     chan error
+	closeCh     chan struct{}
+	sessionCh   chan struct{} // closed when the current connection is closed
+	restartCh   chan restartRequest
+	restartOnce sync.Once
+	lock        sync.Mutex
+	isClosed    bool
 }
 
-var _ indexer.BlockSyncer = (*BlockSyncerImpl)(nil)
+var (
+	_ indexer.BlockSyncer          = (*BlockSyncerImpl)(nil)
+	_ indexer.SyncProgressProvider = (*BlockSyncerImpl)(nil)
+)
 
 func NewBlockSyncer(
 	config *BlockSyncerConfig, blockHandler indexer.BlockSyncerHandler, logger hclog.Logger,
 ) *BlockSyncerImpl {
-	return &BlockSyncerImpl{
+	blockCacheSize := config.BlockCacheSize
+	if blockCacheSize <= 0 {
+		blockCacheSize = blockCacheSizeDefault
+	}
+
+	bs := &BlockSyncerImpl{
 		blockHandler: blockHandler,
 		config:       config,
+		peers:        newPeerManager(config),
+		blocksCache:  newReceivedBlocksCache(blockCacheSize),
+		progress:     indexer.NewSyncProgressTracker(config.SyncedSlotLag),
+		metrics:      indexer.NoopMetrics{},
 		errorCh:      make(chan error, 1),
 		closeCh:      make(chan struct{}),
+		restartCh:    make(chan restartRequest),
 		logger:       logger,
 	}
+
+	bs.queryPeerTip = bs.getPeerTip
+
+	return bs
 }
 
 func (bs *BlockSyncerImpl) Sync() (err error) {
@@ -71,11 +166,22 @@ func (bs *BlockSyncerImpl) Sync() (err error) {
 		cntTries = syncStartTriesDefault
 	}
 
+	// each peer should be tried at least once
+	cntTries = max(cntTries, len(bs.peers.peers))
+
 	for i := 1; i <= cntTries; i++ {
-		if err = bs.syncExecute(); err == nil {
+		peer := bs.peers.selectPeer()
+
+		if err = bs.syncExecute(peer); err == nil {
 			break
 		} else if i < cntTries {
-			bs.logger.Warn("Error while starting syncer", "err", err, "attempt", i, "of", cntTries)
+			bs.logger.Warn("Error while starting syncer", "addr", peer.Address, "err", err, "attempt", i, "of", cntTries)
+		}
+
+		// fail over to the next healthy peer without delay only if the failed peer is banned,
+		// otherwise the same peer would be retried immediately
+		if bs.peers.isBanned(peer.Address) && bs.peers.hasHealthyPeer() {
+			continue
 		}
 
 		select {
@@ -106,7 +212,32 @@ func (bs *BlockSyncerImpl) ErrorCh() <-chan error {
 	return bs.errorCh
 }
 
-func (bs *BlockSyncerImpl) syncExecute() error {
+// PeersStatus returns the health of all the configured peers ordered by priority
+func (bs *BlockSyncerImpl) PeersStatus() []PeerStatus {
+	return bs.peers.status()
+}
+
+// GetSyncProgress returns the tip of the current peer, the latest point passed to the block handler and the lag
+func (bs *BlockSyncerImpl) GetSyncProgress() indexer.SyncProgress {
+	return bs.progress.GetSyncProgress()
+}
+
+// SetSyncStateHandler sets handler which is notified when the syncer becomes synced or falls behind the tip
+func (bs *BlockSyncerImpl) SetSyncStateHandler(handler indexer.SyncStateHandler) {
+	bs.progress.SetSyncStateHandler(handler)
+}
+
+// GetFetchStats returns the block fetching counters
+func (bs *BlockSyncerImpl) GetFetchStats() FetchStats {
+	return bs.fetchStats.get()
+}
+
+// SetMetrics sets the receiver of the block fetch, reconnects and tip measurements. It must be called before Sync
+func (bs *BlockSyncerImpl) SetMetrics(metrics indexer.Metrics) {
+	bs.metrics = metrics
+}
+
+func (bs *BlockSyncerImpl) syncExecute(peer BlockSyncerPeer) error {
 	// if the syncer is closed in the meantime -> quit
 	select {
 	case <-bs.closeCh:
@@ -121,36 +252,66 @@ func (bs *BlockSyncerImpl) syncExecute() error {
 
 	bs.closeConnectionNoLock()
 
-	bs.logger.Debug("Start syncing requested", "addr", bs.config.NodeAddress, "magic", bs.config.NetworkMagic)
+	isNodeToClient := bs.config.IsNodeToClient(peer.Address)
 
-	// create connection
-	connection, err := ouroboros.NewConnection(
+	bs.logger.Debug("Start syncing requested",
+		"addr", peer.Address, "magic", bs.config.NetworkMagic, "n2c", isNodeToClient)
+
+	chainSyncOptions := []chainsync.ChainSyncOptionFunc{
+		chainsync.WithRollBackwardFunc(bs.rollBackwardCallback),
+		chainsync.WithRollForwardFunc(bs.rollForwardCallback),
+	}
+	if bs.config.PipelineLimit > 0 {
+		chainSyncOptions = append(chainSyncOptions, chainsync.WithPipelineLimit(bs.config.PipelineLimit))
+	}
+
+	options := []ouroboros.ConnectionOptionFunc{
 		ouroboros.WithNetworkMagic(bs.config.NetworkMagic),
-		ouroboros.WithNodeToNode(true),
+		ouroboros.WithNodeToNode(!isNodeToClient),
 		ouroboros.WithKeepAlive(bs.config.KeepAlive),
-		ouroboros.WithChainSyncConfig(chainsync.NewConfig(
-			chainsync.WithRollBackwardFunc(bs.rollBackwardCallback),
-			chainsync.WithRollForwardFunc(bs.rollForwardCallback),
-		)),
-	)
+		ouroboros.WithChainSyncConfig(chainsync.NewConfig(chainSyncOptions...)),
+	}
+
+	// node-to-client chain-sync delivers the whole blocks so there is nothing to prefetch
+	var prefetcher *blockPrefetcher
+
+	if !isNodeToClient && bs.config.BlockFetchBatchSize > 1 {
+		prefetcher = newBlockPrefetcher(bs.config.BlockFetchBatchSize, bs.blocksCache, &bs.fetchStats, bs.logger)
+		options = append(options, ouroboros.WithBlockFetchConfig(blockfetch.NewConfig(
+			blockfetch.WithBlockFunc(prefetcher.blockFetchCallback),
+		)))
+	}
+
+	// create connection
+	connection, err := ouroboros.NewConnection(options...)
 	if err != nil {
 		return err
 	}
 
 	// dial node -> connect to node
-	if err := connection.Dial(bs.config.Protocol(), bs.config.NodeAddress); err != nil {
+	if err := connection.Dial(getProtocol(peer.Address), peer.Address); err != nil {
+		bs.peers.markFailure(peer.Address)
+
 		return err
 	}
 
 	bs.connection = connection
+	bs.prefetcher = prefetcher
+	bs.sessionCh = make(chan struct{})
+
+	if prefetcher != nil {
+		go prefetcher.run(connection.BlockFetch().Client, bs.sessionCh)
+	}
 
-	bs.logger.Debug("Connection established", "addr", bs.config.NodeAddress, "magic", bs.config.NetworkMagic)
+	bs.logger.Debug("Connection established", "addr", peer.Address, "magic", bs.config.NetworkMagic)
 
 	blockPoint, err := bs.blockHandler.Reset()
 	if err != nil {
 		return err
 	}
 
+	bs.progress.SetLatestPoint(blockPoint)
+
 	// start syncing
 	var blockPointLedger common.Point
 	if blockPoint.BlockSlot != 0 {
@@ -158,14 +319,22 @@ func (bs *BlockSyncerImpl) syncExecute() error {
 	}
 
 	if err := connection.ChainSync().Client.Sync([]common.Point{blockPointLedger}); err != nil {
+		bs.peers.markFailure(peer.Address)
+
 		return err
 	}
 
-	bs.logger.Debug("Syncing started", "addr", bs.config.NodeAddress,
+	bs.peers.markSuccess(peer.Address)
+
+	bs.logger.Debug("Syncing started", "addr", peer.Address,
 		"magic", bs.config.NetworkMagic, "point", blockPoint)
 
 	// in separated routine wait for async errors
-	go bs.errorHandler(connection.ErrorChan())
+	go bs.errorHandler(connection.ErrorChan(), peer.Address, bs.sessionCh)
+
+	if bs.config.TipCheckInterval > 0 && len(bs.peers.peers) > 1 {
+		go bs.tipChecker(peer.Address, bs.sessionCh)
+	}
 
 	return nil
 }
@@ -177,12 +346,33 @@ func (bs *BlockSyncerImpl) rollBackwardCallback(
 		"hash", hex.EncodeToString(point.Hash), "slot", point.Slot,
 		"tip_slot", tip.Point.Slot, "tip_hash", hex.EncodeToString(tip.Point.Hash))
 
+	bs.setTip(tip)
+
+	bs.lock.Lock()
+	if bs.prefetcher != nil {
+		bs.prefetcher.reset()
+	}
+	bs.lock.Unlock()
+
+	blockPoint := newBlockPoint(point)
+
+	if err := bs.blockHandler.RollBackward(blockPoint); err != nil {
+		return &blockHandlerError{err: err}
+	}
+
+	bs.progress.SetLatestPoint(blockPoint)
+	bs.recordProgress()
+
+	return nil
+}
+
+func newBlockPoint(point common.Point) indexer.BlockPoint {
 	blockPoint := indexer.BlockPoint{BlockSlot: point.Slot}
 	if len(point.Hash) == indexer.HashSize {
 		blockPoint.BlockHash = indexer.Hash(point.Hash)
 	}
 
-	return bs.blockHandler.RollBackward(blockPoint)
+	return blockPoint
 }
 
 func (bs *BlockSyncerImpl) rollForwardCallback(
@@ -193,27 +383,54 @@ func (bs *BlockSyncerImpl) rollForwardCallback(
 		return errors.New("failed to get block header with gouroboros")
 	}
 
-	bs.lock.Lock()
-	conn := bs.connection
-	bs.lock.Unlock()
+	var txsRetriever indexer.BlockTxsRetriever
+
+	hash := indexer.NewHashFromHexString(blockHeader.Hash())
+
+	// node-to-client chain-sync delivers the whole block so there is no need to fetch it again
+	if block, ok := blockInfo.(ledger.Block); ok {
+		bs.blocksCache.add(hash, block)
+
+		txsRetriever = newReceivedBlocksTxsRetriever(bs.blocksCache, bs.logger)
+	} else {
+		bs.lock.Lock()
+		conn, prefetcher := bs.connection, bs.prefetcher
+		bs.lock.Unlock()
 
-	if conn == nil {
-		return errors.New("failed to get block transactions: no connection")
+		if conn == nil {
+			return errors.New("failed to get block transactions: no connection")
+		}
+
+		if prefetcher != nil {
+			prefetcher.addHeader(common.NewPoint(blockHeader.SlotNumber(), hash[:]))
+		}
+
+		txsRetriever = newBlockTxsRetrieverImpl(conn, prefetcher, &bs.fetchStats, bs.metrics, bs.logger)
 	}
 
 	bs.logger.Debug("Roll forward",
 		"hash", blockHeader.Hash(), "slot", blockHeader.SlotNumber(), "number", blockHeader.BlockNumber(),
 		"tip_slot", tip.Point.Slot, "tip_hash", hex.EncodeToString(tip.Point.Hash))
 
-	return bs.blockHandler.RollForward(indexer.BlockHeader{
+	bs.setTip(tip)
+
+	err := bs.blockHandler.RollForward(indexer.BlockHeader{
 		Slot:   blockHeader.SlotNumber(),
-		Hash:   indexer.NewHashFromHexString(blockHeader.Hash()),
+		Hash:   hash,
 		Number: blockHeader.BlockNumber(),
 		EraID:  blockHeader.Era().Id,
-	}, newBlockTxsRetrieverImpl(conn, bs.logger))
+	}, txsRetriever)
+	if err != nil {
+		return &blockHandlerError{err: err}
+	}
+
+	bs.progress.SetLatestPoint(indexer.BlockPoint{BlockSlot: blockHeader.SlotNumber(), BlockHash: hash})
+	bs.recordProgress()
+
+	return nil
 }
 
-func (bs *BlockSyncerImpl) errorHandler(errorCh <-chan error) {
+func (bs *BlockSyncerImpl) errorHandler(errorCh <-chan error, address string, sessionCh <-chan struct{}) {
 	var (
 		err error
 		ok  bool
@@ -230,25 +447,213 @@ func (bs *BlockSyncerImpl) errorHandler(errorCh <-chan error) {
 
 	// retry syncing again if not fatal error and if RestartOnError is true (errors.Is does not work in this case)
 	if !strings.Contains(err.Error(), indexer.ErrBlockIndexerFatal.Error()) && bs.config.RestartOnError {
-		bs.logger.Warn("Error happened during synchronization", "err", err)
+		bs.requestRestart(restartRequest{address: address, err: err, sessionCh: sessionCh})
+	} else {
+		bs.logger.Error("Error happened during synchronization. Restart the syncer manually.", "err", err)
+		bs.propagateError(err)
+	}
+}
+
+// requestRestart passes the request to the restart routine which is started with the first request
+func (bs *BlockSyncerImpl) requestRestart(request restartRequest) {
+	bs.restartOnce.Do(func() {
+		go bs.restartLoop()
+	})
 
+	select {
+	case bs.restartCh <- request:
+	case <-request.sessionCh: // the session has already been restarted
+	case <-bs.closeCh:
+	}
+}
+
+// restartLoop handles the restart requests one by one
+func (bs *BlockSyncerImpl) restartLoop() {
+	for {
 		select {
 		case <-bs.closeCh:
 			return
-		case <-time.After(bs.config.RestartDelay):
+		case request := <-bs.restartCh:
+			bs.restart(request)
 		}
+	}
+}
 
-		if err := bs.Sync(); err != nil {
-			bs.logger.Error("Error happened while trying to restart the synchronization", "err", err)
-			bs.errorCh <- err // propagate error
-		}
+func (bs *BlockSyncerImpl) restart(request restartRequest) {
+	// the other request of the same session has already restarted the synchronization
+	select {
+	case <-request.sessionCh:
+		return
+	default:
+	}
+
+	if request.err == nil {
+		bs.logger.Warn("Switching to another peer", "addr", request.address)
+
+		bs.peers.markFailure(request.address)
+		bs.metrics.AddCounter(indexer.MetricSyncerReconnects, 1, "reason", "failover")
 	} else {
-		bs.logger.Error("Error happened during synchronization. Restart the syncer manually.", "err", err)
-		bs.errorCh <- err // propagate error
+		bs.logger.Warn("Error happened during synchronization", "addr", request.address, "err", request.err)
+
+		// block handler (database, indexer) errors are not caused by the peer
+		isPeerErr := isPeerError(request.err)
+		if isPeerErr {
+			bs.peers.markFailure(request.address)
+		}
+
+		bs.metrics.AddCounter(indexer.MetricSyncerReconnects, 1, "reason", "error")
+
+		// restart without delay only if the failed peer is banned and there is another healthy peer,
+		// otherwise the same peer would be retried immediately
+		if !isPeerErr || !bs.peers.isBanned(request.address) || !bs.peers.hasHealthyPeer() {
+			select {
+			case <-bs.closeCh:
+				return
+			case <-time.After(bs.config.RestartDelay):
+			}
+		}
+	}
+
+	if err := bs.Sync(); err != nil {
+		bs.logger.Error("Error happened while trying to restart the synchronization", "err", err)
+		bs.propagateError(err)
 	}
 }
 
+// propagateError sends the error to the error channel unless the syncer is closed
+func (bs *BlockSyncerImpl) propagateError(err error) {
+	select {
+	case bs.errorCh <- err:
+	case <-bs.closeCh:
+	}
+}
+
+// isPeerError returns true if the synchronization error is caused by the connection or the protocol
+// and not by the block syncer handler. The connection passes the protocol errors as the plain text
+// so the handler error is recognized by its message if it is not in the error chain
+func isPeerError(err error) bool {
+	var handlerErr *blockHandlerError
+	if errors.As(err, &handlerErr) {
+		return false
+	}
+
+	return !strings.Contains(err.Error(), blockHandlerErrorPrefix)
+}
+
+// setTip updates tip of the current peer and notifies the block handler about it
+func (bs *BlockSyncerImpl) setTip(tip chainsync.Tip) {
+	tipPoint := newBlockPoint(tip.Point)
+
+	bs.peers.setCurrentTip(tip)
+	bs.progress.SetTip(tipPoint)
+	bs.recordProgress()
+
+	if tipHandler, ok := bs.blockHandler.(indexer.ChainTipHandler); ok {
+		tipHandler.SetChainTip(tipPoint)
+	}
+}
+
+// recordProgress updates the tip and the slot lag gauges
+func (bs *BlockSyncerImpl) recordProgress() {
+	progress := bs.progress.GetSyncProgress()
+
+	bs.metrics.SetGauge(indexer.MetricSyncerTipSlot, float64(progress.Tip.BlockSlot))
+	bs.metrics.SetGauge(indexer.MetricSyncerSlotLag, float64(progress.SlotLag))
+}
+
+// tipChecker periodically compares tip of the current peer with tips of the other peers
+// and fails over to another peer if the current one is lagging behind
+func (bs *BlockSyncerImpl) tipChecker(address string, sessionCh <-chan struct{}) {
+	ticker := time.NewTicker(bs.config.TipCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sessionCh:
+			return
+		case <-ticker.C:
+		}
+
+		if !bs.checkPeerTips(address) {
+			continue
+		}
+
+		bs.requestRestart(restartRequest{address: address, sessionCh: sessionCh})
+
+		return
+	}
+}
+
+// checkPeerTips returns true if the peer with the given address is lagging behind the other healthy peers
+func (bs *BlockSyncerImpl) checkPeerTips(address string) (isLagging bool) {
+	currentTip := bs.peers.getTip(address)
+	if currentTip.Point.Slot == 0 {
+		return false // tip is not received yet
+	}
+
+	maxLag := bs.config.MaxTipSlotLag
+	if maxLag == 0 {
+		maxLag = maxTipSlotLagDefault
+	}
+
+	for _, otherAddress := range bs.peers.healthyPeers(address) {
+		tip, err := bs.queryPeerTip(otherAddress)
+		if err != nil {
+			bs.logger.Warn("Failed to retrieve peer tip", "addr", otherAddress, "err", err)
+			bs.peers.markFailure(otherAddress)
+
+			continue
+		}
+
+		bs.peers.setTip(otherAddress, tip)
+
+		if isTipForked(currentTip, tip) {
+			bs.logger.Warn("Peers have different blocks at the same tip slot", "slot", tip.Point.Slot,
+				"addr", address, "hash", hex.EncodeToString(currentTip.Point.Hash),
+				"other_addr", otherAddress, "other_hash", hex.EncodeToString(tip.Point.Hash))
+		} else if tip.Point.Slot > currentTip.Point.Slot+maxLag {
+			bs.logger.Warn("Peer is lagging behind", "addr", address, "slot", currentTip.Point.Slot,
+				"other_addr", otherAddress, "other_slot", tip.Point.Slot)
+
+			isLagging = true
+		}
+	}
+
+	return isLagging
+}
+
+// getPeerTip retrieves the current tip of the peer through the short lived connection
+func (bs *BlockSyncerImpl) getPeerTip(address string) (chainsync.Tip, error) {
+	connection, err := ouroboros.NewConnection(
+		ouroboros.WithNetworkMagic(bs.config.NetworkMagic),
+		ouroboros.WithNodeToNode(!bs.config.IsNodeToClient(address)),
+	)
+	if err != nil {
+		return chainsync.Tip{}, err
+	}
+
+	if err := connection.Dial(getProtocol(address), address); err != nil {
+		return chainsync.Tip{}, err
+	}
+
+	defer func() {
+		_ = connection.Close()
+	}()
+
+	tip, err := connection.ChainSync().Client.GetCurrentTip()
+	if err != nil {
+		return chainsync.Tip{}, err
+	}
+
+	return *tip, nil
+}
+
 func (bs *BlockSyncerImpl) closeConnectionNoLock() {
+	if bs.sessionCh != nil {
+		close(bs.sessionCh)
+		bs.sessionCh = nil
+	}
+
 	if oldConn := bs.connection; oldConn != nil {
 		bs.logger.Debug("Closing old connection")
 
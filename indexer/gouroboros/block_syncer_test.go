@@ -2,6 +2,7 @@ package gouroboros
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -12,6 +13,7 @@ import (
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/blinklabs-io/gouroboros/ledger/byron"
 	"github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	"github.com/blinklabs-io/gouroboros/protocol/common"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/require"
 )
@@ -216,13 +218,36 @@ func TestSyncer_RollForwardCallback_ConnectionNil(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestSyncer_RollBackwardCallback_HandlerError(t *testing.T) {
+	t.Parallel()
+
+	handlerMock := indexer.NewBlockSyncerHandlerMock(existingPointSlot, existingPointHashStr)
+	handlerMock.RollBackwardFuncFn = func(indexer.BlockPoint) error {
+		return errors.New("db error")
+	}
+	syncer := NewBlockSyncer(&BlockSyncerConfig{}, handlerMock, hclog.NewNullLogger())
+
+	err := syncer.rollBackwardCallback(chainsync.CallbackContext{}, common.NewPoint(10, []byte{1}), chainsync.Tip{})
+	require.ErrorContains(t, err, "db error")
+
+	var handlerErr *blockHandlerError
+
+	require.ErrorAs(t, err, &handlerErr)
+
+	// handler errors must not ban the peer, even if they are received as the plain text from the connection
+	require.False(t, isPeerError(err))
+	require.False(t, isPeerError(fmt.Errorf("sync: %w", err)))
+	require.False(t, isPeerError(errors.New(err.Error())))
+	require.True(t, isPeerError(errors.New("connection reset by peer")))
+}
+
 func TestSyncer_Sync_ConnectionIsClosed(t *testing.T) {
 	t.Parallel()
 
 	syncer := getTestSyncer(existingPointSlot, existingPointHashStr)
 	syncer.Close()
 
-	require.NoError(t, syncer.syncExecute())
+	require.NoError(t, syncer.syncExecute(BlockSyncerPeer{Address: nodeAddress}))
 	require.Nil(t, syncer.connection)
 
 	require.NoError(t, syncer.Sync())
@@ -243,7 +268,7 @@ func TestSyncer_ErrorHandler(t *testing.T) {
 		syncer.config.RestartOnError = true
 
 		go func() {
-			syncer.errorHandler(errCh)
+			syncer.errorHandler(errCh, nodeAddress, nil)
 			waitCh <- Good
 		}()
 
@@ -266,7 +291,7 @@ func TestSyncer_ErrorHandler(t *testing.T) {
 		syncer.config.RestartOnError = true
 
 		go func() {
-			syncer.errorHandler(errCh)
+			syncer.errorHandler(errCh, nodeAddress, nil)
 			waitCh <- Good
 		}()
 
@@ -294,7 +319,7 @@ func TestSyncer_ErrorHandler(t *testing.T) {
 		go func() {
 			defer wg.Done()
 
-			syncer.errorHandler(errCh)
+			syncer.errorHandler(errCh, nodeAddress, nil)
 		}()
 
 		go func() {
@@ -321,13 +346,14 @@ func TestSyncer_ErrorHandler(t *testing.T) {
 		syncer := getTestSyncer(existingPointSlot, existingPointHashStr)
 		syncer.config.RestartOnError = true
 		syncer.config.NodeAddress = "invalid node address"
+		syncer.peers = newPeerManager(syncer.config)
 
 		wg.Add(2)
 
 		go func() {
 			defer wg.Done()
 
-			syncer.errorHandler(errCh)
+			syncer.errorHandler(errCh, nodeAddress, nil)
 		}()
 
 		go func() {
@@ -344,6 +370,39 @@ func TestSyncer_ErrorHandler(t *testing.T) {
 		require.True(t, isOk)
 	})
 
+	t.Run("restart requests of the same session restart once", func(t *testing.T) {
+		t.Parallel()
+
+		errCh := make(chan error, 1)
+		syncer := getTestSyncer(existingPointSlot, existingPointHashStr)
+		syncer.config.RestartOnError = true
+		syncer.config.NodeAddress = "invalid node address"
+		syncer.peers = newPeerManager(syncer.config)
+		syncer.sessionCh = make(chan struct{})
+
+		defer syncer.Close()
+
+		sessionCh := syncer.sessionCh
+
+		go syncer.errorHandler(errCh, nodeAddress, sessionCh)
+		go syncer.requestRestart(restartRequest{address: nodeAddress, sessionCh: sessionCh})
+
+		errCh <- errors.New("test error")
+
+		select {
+		case err := <-syncer.ErrorCh():
+			require.ErrorContains(t, err, "missing port")
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout")
+		}
+
+		select {
+		case err := <-syncer.ErrorCh():
+			t.Fatalf("synchronization restarted twice: %v", err)
+		case <-time.After(time.Second):
+		}
+	})
+
 	t.Run("close during re-sync", func(t *testing.T) {
 		t.Parallel()
 
@@ -360,7 +419,7 @@ func TestSyncer_ErrorHandler(t *testing.T) {
 		go func() {
 			defer wg.Done()
 
-			syncer.errorHandler(errCh)
+			syncer.errorHandler(errCh, nodeAddress, nil)
 		}()
 
 		go func() {
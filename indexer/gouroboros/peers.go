@@ -0,0 +1,220 @@
+package gouroboros
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/protocol/chainsync"
+)
+
+const (
+	peerBanDurationDefault = time.Minute
+	maxTipSlotLagDefault   = 120
+)
+
+type BlockSyncerPeer struct {
+	Address string `json:"address"`
+	// Priority of the peer, peers with the lower value are used first
+	Priority int `json:"priority"`
+}
+
+// PeerStatus is the health of the peer tracked by the block syncer
+type PeerStatus struct {
+	Address  string `json:"address"`
+	Priority int    `json:"priority"`
+	Active   bool   `json:"active"`
+	Healthy  bool   `json:"healthy"`
+	Failures int    `json:"failures"`
+	TipSlot  uint64 `json:"tipSlot"`
+}
+
+type peerState struct {
+	BlockSyncerPeer
+	failures    int
+	bannedUntil time.Time
+	tip         chainsync.Tip
+}
+
+// peerManager tracks health of the peers. Failed peer is not used until the ban duration expires
+// unless all the peers are failed
+type peerManager struct {
+	peers       []*peerState
+	current     *peerState
+	banDuration time.Duration
+	lock        sync.Mutex
+}
+
+func newPeerManager(config *BlockSyncerConfig) *peerManager {
+	peers := config.Peers
+	if len(peers) == 0 {
+		peers = []BlockSyncerPeer{{Address: config.NodeAddress}}
+	}
+
+	banDuration := config.PeerBanDuration
+	if banDuration <= 0 {
+		banDuration = peerBanDurationDefault
+	}
+
+	states := make([]*peerState, len(peers))
+	for i, peer := range peers {
+		states[i] = &peerState{BlockSyncerPeer: peer}
+	}
+
+	slices.SortStableFunc(states, func(a, b *peerState) int {
+		return a.Priority - b.Priority
+	})
+
+	return &peerManager{
+		peers:       states,
+		banDuration: banDuration,
+	}
+}
+
+// selectPeer selects the healthy peer with the highest priority or the one which ban expires first
+func (pm *peerManager) selectPeer() BlockSyncerPeer {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	now := time.Now()
+	selected := pm.peers[0]
+
+	for _, peer := range pm.peers {
+		if !peer.bannedUntil.After(now) {
+			selected = peer
+
+			break
+		} else if peer.bannedUntil.Before(selected.bannedUntil) {
+			selected = peer
+		}
+	}
+
+	pm.current = selected
+
+	return selected.BlockSyncerPeer
+}
+
+func (pm *peerManager) markFailure(address string) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if peer := pm.getPeerNoLock(address); peer != nil {
+		peer.failures++
+		peer.bannedUntil = time.Now().Add(pm.banDuration)
+	}
+}
+
+func (pm *peerManager) markSuccess(address string) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if peer := pm.getPeerNoLock(address); peer != nil {
+		peer.failures = 0
+		peer.bannedUntil = time.Time{}
+	}
+}
+
+// isBanned returns true if the peer is banned because of the recent failure
+func (pm *peerManager) isBanned(address string) bool {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	peer := pm.getPeerNoLock(address)
+
+	return peer != nil && peer.bannedUntil.After(time.Now())
+}
+
+// hasHealthyPeer returns true if there is at least one peer which is not banned
+func (pm *peerManager) hasHealthyPeer() bool {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	now := time.Now()
+
+	return slices.ContainsFunc(pm.peers, func(peer *peerState) bool {
+		return !peer.bannedUntil.After(now)
+	})
+}
+
+// healthyPeers returns addresses of all the healthy peers except the given one
+func (pm *peerManager) healthyPeers(exceptAddress string) (result []string) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	now := time.Now()
+
+	for _, peer := range pm.peers {
+		if peer.Address != exceptAddress && !peer.bannedUntil.After(now) {
+			result = append(result, peer.Address)
+		}
+	}
+
+	return result
+}
+
+func (pm *peerManager) setTip(address string, tip chainsync.Tip) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if peer := pm.getPeerNoLock(address); peer != nil {
+		peer.tip = tip
+	}
+}
+
+// setCurrentTip sets the tip of the last selected peer
+func (pm *peerManager) setCurrentTip(tip chainsync.Tip) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if pm.current != nil {
+		pm.current.tip = tip
+	}
+}
+
+func (pm *peerManager) getTip(address string) chainsync.Tip {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if peer := pm.getPeerNoLock(address); peer != nil {
+		return peer.tip
+	}
+
+	return chainsync.Tip{}
+}
+
+func (pm *peerManager) status() []PeerStatus {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	now := time.Now()
+	result := make([]PeerStatus, len(pm.peers))
+
+	for i, peer := range pm.peers {
+		result[i] = PeerStatus{
+			Address:  peer.Address,
+			Priority: peer.Priority,
+			Active:   peer == pm.current,
+			Healthy:  !peer.bannedUntil.After(now),
+			Failures: peer.failures,
+			TipSlot:  peer.tip.Point.Slot,
+		}
+	}
+
+	return result
+}
+
+func (pm *peerManager) getPeerNoLock(address string) *peerState {
+	for _, peer := range pm.peers {
+		if peer.Address == address {
+			return peer
+		}
+	}
+
+	return nil
+}
+
+// isTipForked returns true if the tips are at the same slot but with the different hashes
+func isTipForked(a, b chainsync.Tip) bool {
+	return a.Point.Slot == b.Point.Slot && !bytes.Equal(a.Point.Hash, b.Point.Hash)
+}
@@ -0,0 +1,152 @@
+package gouroboros
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	"github.com/blinklabs-io/gouroboros/protocol/common"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerManager(t *testing.T) {
+	t.Parallel()
+
+	t.Run("node address without peers", func(t *testing.T) {
+		t.Parallel()
+
+		pm := newPeerManager(&BlockSyncerConfig{NodeAddress: "node:3001"})
+
+		require.Equal(t, BlockSyncerPeer{Address: "node:3001"}, pm.selectPeer())
+
+		// the only peer is used even if it is banned
+		pm.markFailure("node:3001")
+
+		require.False(t, pm.hasHealthyPeer())
+		require.Equal(t, BlockSyncerPeer{Address: "node:3001"}, pm.selectPeer())
+	})
+
+	t.Run("failover by priority", func(t *testing.T) {
+		t.Parallel()
+
+		pm := newPeerManager(&BlockSyncerConfig{
+			NodeAddress: "ignored:3001",
+			Peers: []BlockSyncerPeer{
+				{Address: "c:3001", Priority: 3},
+				{Address: "a:3001", Priority: 1},
+				{Address: "b:3001", Priority: 2},
+			},
+			PeerBanDuration: time.Hour,
+		})
+
+		require.Equal(t, "a:3001", pm.selectPeer().Address)
+
+		require.False(t, pm.isBanned("a:3001"))
+
+		pm.markFailure("a:3001")
+
+		require.True(t, pm.isBanned("a:3001"))
+		require.False(t, pm.isBanned("b:3001"))
+		require.Equal(t, "b:3001", pm.selectPeer().Address)
+		require.Equal(t, []string{"c:3001"}, pm.healthyPeers("b:3001"))
+
+		pm.markFailure("b:3001")
+
+		require.Equal(t, "c:3001", pm.selectPeer().Address)
+
+		pm.markFailure("c:3001")
+
+		// all peers are banned -> the one which ban expires first
+		require.False(t, pm.hasHealthyPeer())
+		require.Equal(t, "a:3001", pm.selectPeer().Address)
+
+		pm.markSuccess("b:3001")
+
+		require.True(t, pm.hasHealthyPeer())
+		require.Equal(t, "b:3001", pm.selectPeer().Address)
+
+		pm.setCurrentTip(chainsync.Tip{Point: common.NewPoint(100, []byte{1})})
+
+		require.Equal(t, []PeerStatus{
+			{Address: "a:3001", Priority: 1, Failures: 1},
+			{Address: "b:3001", Priority: 2, Active: true, Healthy: true, TipSlot: 100},
+			{Address: "c:3001", Priority: 3, Failures: 1},
+		}, pm.status())
+	})
+
+	t.Run("ban expires", func(t *testing.T) {
+		t.Parallel()
+
+		pm := newPeerManager(&BlockSyncerConfig{
+			Peers:           []BlockSyncerPeer{{Address: "a:3001"}, {Address: "b:3001", Priority: 1}},
+			PeerBanDuration: time.Millisecond * 50,
+		})
+
+		require.False(t, pm.isBanned("a:3001"))
+
+		pm.markFailure("a:3001")
+
+		require.True(t, pm.isBanned("a:3001"))
+		require.False(t, pm.isBanned("b:3001"))
+		require.Equal(t, "b:3001", pm.selectPeer().Address)
+
+		time.Sleep(time.Millisecond * 100)
+
+		require.Equal(t, "a:3001", pm.selectPeer().Address)
+	})
+}
+
+func TestSyncer_CheckPeerTips(t *testing.T) {
+	t.Parallel()
+
+	tips := map[string]chainsync.Tip{
+		"b:3001": {Point: common.NewPoint(1000, []byte{2})},
+		"c:3001": {Point: common.NewPoint(1100, []byte{3})},
+	}
+
+	syncer := NewBlockSyncer(&BlockSyncerConfig{
+		Peers: []BlockSyncerPeer{
+			{Address: "a:3001"}, {Address: "b:3001", Priority: 1}, {Address: "c:3001", Priority: 2},
+		},
+		MaxTipSlotLag:   50,
+		PeerBanDuration: time.Hour,
+	}, nil, hclog.NewNullLogger())
+
+	syncer.queryPeerTip = func(address string) (chainsync.Tip, error) {
+		tip, exists := tips[address]
+		if !exists {
+			return chainsync.Tip{}, errors.New("connection refused")
+		}
+
+		return tip, nil
+	}
+
+	require.Equal(t, "a:3001", syncer.peers.selectPeer().Address)
+
+	// tip of the current peer is not known yet
+	require.False(t, syncer.checkPeerTips("a:3001"))
+
+	syncer.peers.setCurrentTip(chainsync.Tip{Point: common.NewPoint(1000, []byte{1})})
+
+	// forked with b and lagging behind c
+	require.True(t, syncer.checkPeerTips("a:3001"))
+
+	syncer.peers.setCurrentTip(chainsync.Tip{Point: common.NewPoint(1060, []byte{1})})
+
+	require.False(t, syncer.checkPeerTips("a:3001"))
+
+	delete(tips, "c:3001")
+
+	// failed peer is banned and not queried anymore
+	require.False(t, syncer.checkPeerTips("a:3001"))
+	require.Equal(t, []string{"b:3001"}, syncer.peers.healthyPeers("a:3001"))
+
+	status := syncer.PeersStatus()
+
+	require.Len(t, status, 3)
+	require.Equal(t, uint64(1060), status[0].TipSlot)
+	require.Equal(t, uint64(1000), status[1].TipSlot)
+	require.Equal(t, 1, status[2].Failures)
+}
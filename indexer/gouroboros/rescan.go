@@ -0,0 +1,49 @@
+package gouroboros
+
+import (
+	"context"
+
+	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/hashicorp/go-hclog"
+)
+
+// Rescan syncs historical blocks with its own node connection and blocks until the rescanner is done.
+// Live block syncer is not affected
+func Rescan(
+	ctx context.Context, config *BlockSyncerConfig, rescanner *indexer.Rescanner, logger hclog.Logger,
+) error {
+	syncer := NewBlockSyncer(config, rescanner, logger)
+
+	defer syncer.Close() //nolint:errcheck
+
+	if err := syncer.Sync(); err != nil {
+		return err
+	}
+
+	select {
+	case <-rescanner.Done():
+		return nil
+	case err := <-syncer.ErrorCh():
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewAddressesBackfillHandler returns handler which rescans history of the addresses added to the block indexer.
+// Syncing starts from the given point (chain origin if nil), blocks before the backfill slot are only skipped
+func NewAddressesBackfillHandler(
+	config *BlockSyncerConfig, startingBlockPoint *indexer.BlockPoint,
+	db indexer.BlockIndexerDB, softDeleteUtxo bool, logger hclog.Logger,
+) indexer.AddressesBackfillHandler {
+	return func(fromSlot uint64, addresses []string) error {
+		rescanner := indexer.NewRescanner(&indexer.RescanConfig{
+			StartingBlockPoint: startingBlockPoint,
+			FromSlot:           fromSlot,
+			Addresses:          addresses,
+			SoftDeleteUtxo:     softDeleteUtxo,
+		}, db, logger.Named("rescanner"))
+
+		return Rescan(context.Background(), config, rescanner, logger)
+	}
+}
@@ -1,22 +1,38 @@
 package gouroboros
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
 	ouroboros "github.com/blinklabs-io/gouroboros"
+	"github.com/blinklabs-io/gouroboros/ledger"
 	"github.com/blinklabs-io/gouroboros/protocol/common"
 	"github.com/hashicorp/go-hclog"
 )
 
 type blockTxsRetrieverImpl struct {
 	connection *ouroboros.Connection
+	// prefetcher is optional. Block is fetched alone only if it has not been prefetched
+	prefetcher *blockPrefetcher
+	stats      *fetchStats
+	metrics    indexer.Metrics
 	logger     hclog.Logger
 }
 
 var _ indexer.BlockTxsRetriever = (*blockTxsRetrieverImpl)(nil)
 
-func newBlockTxsRetrieverImpl(conn *ouroboros.Connection, logger hclog.Logger) *blockTxsRetrieverImpl {
+func newBlockTxsRetrieverImpl(
+	conn *ouroboros.Connection, prefetcher *blockPrefetcher, stats *fetchStats,
+	metrics indexer.Metrics, logger hclog.Logger,
+) *blockTxsRetrieverImpl {
 	return &blockTxsRetrieverImpl{
 		connection: conn,
+		prefetcher: prefetcher,
+		stats:      stats,
+		metrics:    metrics,
 		logger:     logger,
 	}
 }
@@ -24,6 +40,20 @@ func newBlockTxsRetrieverImpl(conn *ouroboros.Connection, logger hclog.Logger) *
 func (br *blockTxsRetrieverImpl) GetBlockTransactions(blockHeader indexer.BlockHeader) ([]*indexer.Tx, error) {
 	br.logger.Debug("Get block transactions", "slot", blockHeader.Slot, "hash", blockHeader.Hash)
 
+	startTime := time.Now()
+
+	if br.prefetcher != nil {
+		br.prefetcher.wait(blockHeader.Hash)
+
+		if block := br.prefetcher.cache.get(blockHeader.Hash); block != nil {
+			br.metrics.ObserveDuration(indexer.MetricBlockFetchDuration, time.Since(startTime), "source", "prefetch")
+
+			return getBlockTxs(&blockHeader, block)
+		}
+	}
+
+	br.stats.singleFetches.Add(1)
+
 	block, err := br.connection.BlockFetch().Client.GetBlock(
 		common.NewPoint(blockHeader.Slot, blockHeader.Hash[:]),
 	)
@@ -31,11 +61,90 @@ func (br *blockTxsRetrieverImpl) GetBlockTransactions(blockHeader indexer.BlockH
 		return nil, err
 	}
 
+	br.metrics.ObserveDuration(indexer.MetricBlockFetchDuration, time.Since(startTime), "source", "single")
+
+	return getBlockTxs(&blockHeader, block)
+}
+
+// receivedBlocksTxsRetriever retrieves txs from the blocks already received through the node-to-client chain-sync
+type receivedBlocksTxsRetriever struct {
+	cache  *receivedBlocksCache
+	logger hclog.Logger
+}
+
+var _ indexer.BlockTxsRetriever = (*receivedBlocksTxsRetriever)(nil)
+
+func newReceivedBlocksTxsRetriever(cache *receivedBlocksCache, logger hclog.Logger) *receivedBlocksTxsRetriever {
+	return &receivedBlocksTxsRetriever{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+func (br *receivedBlocksTxsRetriever) GetBlockTransactions(blockHeader indexer.BlockHeader) ([]*indexer.Tx, error) {
+	br.logger.Debug("Get received block transactions", "slot", blockHeader.Slot, "hash", blockHeader.Hash)
+
+	// evicted block can not be received again within the same session so retrying would never succeed
+	block := br.cache.get(blockHeader.Hash)
+	if block == nil {
+		return nil, errors.Join(indexer.ErrBlockIndexerFatal,
+			fmt.Errorf("block (%d, %s) is not in the received blocks cache", blockHeader.Slot, blockHeader.Hash))
+	}
+
+	return getBlockTxs(&blockHeader, block)
+}
+
+// receivedBlocksCache keeps the latest size blocks received through the chain-sync
+type receivedBlocksCache struct {
+	blocks map[indexer.Hash]ledger.Block
+	keys   []indexer.Hash
+	next   int
+	size   int
+	lock   sync.Mutex
+}
+
+func newReceivedBlocksCache(size int) *receivedBlocksCache {
+	return &receivedBlocksCache{
+		blocks: make(map[indexer.Hash]ledger.Block, size),
+		keys:   make([]indexer.Hash, 0, size),
+		size:   size,
+	}
+}
+
+func (c *receivedBlocksCache) add(hash indexer.Hash, block ledger.Block) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, exists := c.blocks[hash]; exists {
+		return
+	}
+
+	// replace the oldest block when the cache is full
+	if len(c.keys) == c.size {
+		delete(c.blocks, c.keys[c.next])
+
+		c.keys[c.next] = hash
+		c.next = (c.next + 1) % c.size
+	} else {
+		c.keys = append(c.keys, hash)
+	}
+
+	c.blocks[hash] = block
+}
+
+func (c *receivedBlocksCache) get(hash indexer.Hash) ledger.Block {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.blocks[hash]
+}
+
+func getBlockTxs(blockHeader *indexer.BlockHeader, block ledger.Block) ([]*indexer.Tx, error) {
 	legderTxs := block.Transactions()
 	txs := make([]*indexer.Tx, len(legderTxs))
 
 	for i, ledgerTx := range legderTxs {
-		tx, err := createTx(&blockHeader, ledgerTx, uint32(i)) //nolint:gosec
+		tx, err := createTx(blockHeader, ledgerTx, uint32(i)) //nolint:gosec
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,106 @@
+package gouroboros
+
+import (
+	"testing"
+
+	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	"github.com/blinklabs-io/gouroboros/protocol/common"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+type testBlock struct {
+	ledger.Block
+	hash string
+	slot uint64
+}
+
+func (b *testBlock) Hash() string                       { return b.hash }
+func (b *testBlock) SlotNumber() uint64                 { return b.slot }
+func (b *testBlock) BlockNumber() uint64                { return b.slot / 10 }
+func (b *testBlock) Era() ledger.Era                    { return ledger.Era{Id: 5} }
+func (b *testBlock) Transactions() []ledger.Transaction { return nil }
+
+func TestReceivedBlocksCache(t *testing.T) {
+	t.Parallel()
+
+	cache := newReceivedBlocksCache(2)
+	blocks := []*testBlock{{slot: 10}, {slot: 20}, {slot: 30}}
+
+	cache.add(indexer.Hash{1}, blocks[0])
+	cache.add(indexer.Hash{2}, blocks[1])
+	cache.add(indexer.Hash{1}, blocks[0])
+
+	require.Equal(t, blocks[0], cache.get(indexer.Hash{1}))
+	require.Equal(t, blocks[1], cache.get(indexer.Hash{2}))
+
+	cache.add(indexer.Hash{3}, blocks[2])
+
+	require.Nil(t, cache.get(indexer.Hash{1}))
+	require.Equal(t, blocks[1], cache.get(indexer.Hash{2}))
+	require.Equal(t, blocks[2], cache.get(indexer.Hash{3}))
+	require.Len(t, cache.blocks, 2)
+}
+
+func TestSyncer_RollForwardCallback_NodeToClient(t *testing.T) {
+	t.Parallel()
+
+	const hashStr = "34c36a9eb7228ca529e91babcf2215be29ce2a65b609540b483abc4520848d19"
+
+	handler := indexer.NewBlockSyncerHandlerMock(0, "")
+	syncer := NewBlockSyncer(&BlockSyncerConfig{
+		NodeAddress: "/ipc/node.socket",
+	}, handler, hclog.NewNullLogger())
+	metrics := indexer.NewMetricsMock()
+
+	syncer.SetMetrics(metrics)
+
+	require.True(t, syncer.config.IsNodeToClient("/ipc/node.socket"))
+	require.False(t, syncer.config.IsNodeToClient("relay:3001"))
+
+	var (
+		receivedHeader indexer.BlockHeader
+		receivedErr    error
+	)
+
+	handler.RollForwardFn = func(bh indexer.BlockHeader, txsRetriever indexer.BlockTxsRetriever) error {
+		receivedHeader = bh
+
+		txs, err := txsRetriever.GetBlockTransactions(bh)
+		require.NoError(t, err)
+		require.Empty(t, txs)
+
+		_, receivedErr = txsRetriever.GetBlockTransactions(indexer.BlockHeader{Slot: 5, Hash: indexer.Hash{1}})
+
+		return nil
+	}
+
+	// connection is not needed because the whole block is received
+	require.NoError(t, syncer.rollForwardCallback(
+		chainsync.CallbackContext{}, ledger.BlockTypeBabbage, &testBlock{hash: hashStr, slot: 100},
+		chainsync.Tip{Point: common.NewPoint(150, []byte{1})}))
+
+	require.Equal(t, indexer.BlockHeader{
+		Slot: 100, Hash: indexer.NewHashFromHexString(hashStr), Number: 10, EraID: 5,
+	}, receivedHeader)
+	require.ErrorContains(t, receivedErr, "is not in the received blocks cache")
+
+	progress := syncer.GetSyncProgress()
+	require.Equal(t, uint64(150), progress.Tip.BlockSlot)
+	require.Equal(t, indexer.BlockPoint{BlockSlot: 100, BlockHash: receivedHeader.Hash}, progress.LatestPoint)
+	require.Equal(t, uint64(50), progress.SlotLag)
+	require.True(t, progress.IsSynced)
+	require.Equal(t, float64(150), metrics.GetGauge(indexer.MetricSyncerTipSlot))
+	require.Equal(t, float64(50), metrics.GetGauge(indexer.MetricSyncerSlotLag))
+}
+
+func TestReceivedBlocksTxsRetriever_NotInCache(t *testing.T) {
+	t.Parallel()
+
+	retriever := newReceivedBlocksTxsRetriever(newReceivedBlocksCache(2), hclog.NewNullLogger())
+
+	_, err := retriever.GetBlockTransactions(indexer.BlockHeader{Slot: 10, Hash: indexer.Hash{1}})
+	require.ErrorIs(t, err, indexer.ErrBlockIndexerFatal)
+}
@@ -3,6 +3,7 @@ package gouroboros
 import (
 	"github.com/Ethernal-Tech/cardano-infrastructure/indexer"
 	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 )
 
@@ -20,6 +21,20 @@ func createTx(blockHeader *indexer.BlockHeader, ledgerTx ledger.Transaction, ind
 		tx.Metadata = metadata.Cbor()
 	}
 
+	if mint := ledgerTx.AssetMint(); mint != nil {
+		for _, policyIDRaw := range mint.Policies() {
+			policyID := policyIDRaw.String()
+
+			for _, asset := range mint.Assets(policyIDRaw) {
+				tx.Mint = append(tx.Mint, indexer.MintedToken{
+					PolicyID: policyID,
+					Name:     string(asset),
+					Amount:   mint.Asset(policyIDRaw, asset),
+				})
+			}
+		}
+	}
+
 	if inputs := ledgerTx.Inputs(); len(inputs) > 0 {
 		tx.Inputs = make([]*indexer.TxInputOutput, len(inputs))
 
@@ -67,6 +82,7 @@ func createTxOutput(slot uint64, txOut common.TransactionOutput) *indexer.TxOutp
 	var (
 		datum     []byte
 		datumHash indexer.Hash
+		scriptRef []byte
 	)
 
 	if tmp := txOut.Datum(); tmp != nil {
@@ -77,6 +93,11 @@ func createTxOutput(slot uint64, txOut common.TransactionOutput) *indexer.TxOutp
 		datumHash = indexer.Hash(tmp.Bytes())
 	}
 
+	// script reference is encoded cbor (tag 24) of the script
+	if babbageOut, ok := txOut.(*babbage.BabbageTransactionOutput); ok && babbageOut.ScriptRef != nil {
+		scriptRef, _ = babbageOut.ScriptRef.Content.([]byte)
+	}
+
 	return &indexer.TxOutput{
 		Slot:      slot,
 		Address:   ledgerAddressToString(txOut.Address()),
@@ -84,6 +105,7 @@ func createTxOutput(slot uint64, txOut common.TransactionOutput) *indexer.TxOutp
 		Tokens:    tokens,
 		Datum:     datum,
 		DatumHash: datumHash,
+		ScriptRef: scriptRef,
 	}
 }
 
@@ -0,0 +1,45 @@
+package gouroboros
+
+import (
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/mary"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTxOutput(t *testing.T) {
+	t.Parallel()
+
+	const addrStr = "addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u"
+
+	addr, err := common.NewAddress(addrStr)
+	require.NoError(t, err)
+
+	// native script reference: [0, [0, keyhash]]
+	scriptRef := []byte{
+		0x82, 0x00, 0x82, 0x00, 0x58, 0x1c,
+		0x24, 0x48, 0x77, 0xc1, 0xae, 0xef, 0xc7, 0xfd, 0x54, 0x05, 0xa6, 0xe1, 0x4d, 0x92,
+		0x7d, 0x91, 0x75, 0x8d, 0x45, 0xe3, 0x7c, 0x20, 0xfa, 0x2a, 0xc8, 0x9c, 0xb1, 0x67,
+	}
+
+	txOut := createTxOutput(10, &babbage.BabbageTransactionOutput{
+		OutputAddress: addr,
+		OutputAmount:  mary.MaryTransactionOutputValue{Amount: 1_000_000},
+		ScriptRef:     &cbor.Tag{Number: 24, Content: scriptRef},
+	})
+
+	require.Equal(t, addrStr, txOut.Address)
+	require.Equal(t, uint64(10), txOut.Slot)
+	require.Equal(t, uint64(1_000_000), txOut.Amount)
+	require.Equal(t, scriptRef, txOut.ScriptRef)
+
+	txOut = createTxOutput(10, &babbage.BabbageTransactionOutput{
+		OutputAddress: addr,
+		OutputAmount:  mary.MaryTransactionOutputValue{Amount: 1_000_000},
+	})
+
+	require.Nil(t, txOut.ScriptRef)
+}
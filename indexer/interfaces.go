@@ -29,6 +29,43 @@ type BlockSyncerHandler interface {
 	Reset() (BlockPoint, error)
 }
 
+// ChainTipHandler is optionally implemented by the block syncer handler which wants to know the tip of the chain
+type ChainTipHandler interface {
+	SetChainTip(tip BlockPoint)
+}
+
 type NewConfirmedBlockHandler func(*CardanoBlock, []*Tx) error
 
+// ConfirmedBlocksRevertedHandler is notified about the confirmed blocks (ordered from the oldest) which are about
+// to be reverted by the deep rollback to the point. Txs of these blocks are removed from the database
+// even if they were already processed, so the consumer should undo everything done for them
+type ConfirmedBlocksRevertedHandler func(point BlockPoint, revertedBlocks []BlockPoint) error
+
+type UnconfirmedBlockEventType byte
+
+const (
+	// UnconfirmedBlockEventNew is emitted when new (not yet confirmed) block arrives
+	UnconfirmedBlockEventNew UnconfirmedBlockEventType = iota
+	// UnconfirmedBlockEventRollback is emitted when unconfirmed blocks are discarded by the roll backward
+	UnconfirmedBlockEventRollback
+)
+
+type UnconfirmedBlockEvent struct {
+	Type UnconfirmedBlockEventType
+	// Block and Txs (only relevant ones) are set for the new block event
+	Block *CardanoBlock
+	Txs   []*Tx
+	// RollbackPoint and RolledBackBlocks (discarded blocks ordered from the oldest) are set for the rollback event
+	RollbackPoint    BlockPoint
+	RolledBackBlocks []BlockHeader
+	// RevertedConfirmedBlocks are already confirmed blocks reverted by the deep rollback (ordered from the oldest).
+	// Txs of these blocks are removed from the database even if they were already processed
+	RevertedConfirmedBlocks []BlockPoint
+}
+
+type UnconfirmedBlockHandler func(UnconfirmedBlockEvent) error
+
+// AddressesBackfillHandler should index all the txs of the given addresses starting from the slot
+type AddressesBackfillHandler func(fromSlot uint64, addresses []string) error
+
 type TxInfoParserFunc func(rawTx []byte, full bool) (TxInfo, error)
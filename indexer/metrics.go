@@ -0,0 +1,51 @@
+package indexer
+
+import "time"
+
+const (
+	MetricBlocksRolledForward  = "cardano_indexer_blocks_rolled_forward_total"
+	MetricBlocksRolledBackward = "cardano_indexer_blocks_rolled_backward_total"
+	MetricConfirmedBlocks      = "cardano_indexer_confirmed_blocks_total"
+	MetricTxsOfInterest        = "cardano_indexer_txs_of_interest_total"
+	MetricDBCommitDuration     = "cardano_indexer_db_commit_duration_seconds"
+	MetricBlockFetchDuration   = "cardano_indexer_block_fetch_duration_seconds"
+	MetricRunnerQueueDepth     = "cardano_indexer_runner_queue_depth"
+	MetricRunnerRetries        = "cardano_indexer_runner_retries_total"
+	MetricSyncerReconnects     = "cardano_indexer_syncer_reconnects_total"
+	MetricSyncerTipSlot        = "cardano_indexer_syncer_tip_slot"
+	MetricSyncerSlotLag        = "cardano_indexer_syncer_slot_lag"
+)
+
+// MetricDescriptions are the help texts of all the metrics emitted by the indexer components
+var MetricDescriptions = map[string]string{
+	MetricBlocksRolledForward:  "Number of the blocks rolled forward by the block indexer",
+	MetricBlocksRolledBackward: "Number of the unconfirmed and reverted confirmed blocks discarded by the roll backwards",
+	MetricConfirmedBlocks:      "Number of the confirmed blocks saved in the database",
+	MetricTxsOfInterest:        "Number of the confirmed txs of interest saved in the database",
+	MetricDBCommitDuration:     "Duration of the database transactions which save confirmed blocks",
+	MetricBlockFetchDuration:   "Duration of the block bodies retrieval",
+	MetricRunnerQueueDepth:     "Number of the items waiting in the block indexer runner queue",
+	MetricRunnerRetries:        "Number of the failed block indexer runner items which are retried",
+	MetricSyncerReconnects:     "Number of the block syncer restarts after the synchronization error",
+	MetricSyncerTipSlot:        "Slot of the chain tip reported by the node",
+	MetricSyncerSlotLag:        "Number of slots between the chain tip and the latest block passed to the handler",
+}
+
+// Metrics receives measurements of the indexer components. Labels are key value pairs.
+// Implementation must be safe for the concurrent use
+type Metrics interface {
+	AddCounter(name string, value float64, labels ...string)
+	SetGauge(name string, value float64, labels ...string)
+	ObserveDuration(name string, duration time.Duration, labels ...string)
+}
+
+// NoopMetrics discards all the measurements
+type NoopMetrics struct{}
+
+var _ Metrics = NoopMetrics{}
+
+func (NoopMetrics) AddCounter(string, float64, ...string) {}
+
+func (NoopMetrics) SetGauge(string, float64, ...string) {}
+
+func (NoopMetrics) ObserveDuration(string, time.Duration, ...string) {}
@@ -0,0 +1,180 @@
+package indexer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	defaultPruneBatchSize = 1000
+	defaultPruneInterval  = time.Minute
+)
+
+type RetentionConfig struct {
+	// SlotAge - confirmed blocks and processed txs older than (latest confirmed slot - SlotAge) are pruned
+	SlotAge uint64 `json:"slotAge"`
+	// BlockCount - only confirmed blocks and processed txs of the latest BlockCount confirmed blocks are kept
+	BlockCount uint `json:"blockCount"`
+	// PruneUsedTxOutputs - soft deleted tx outputs created before the prune slot are pruned too.
+	// Creation slot is used because the spend slot is not stored, so an old output is pruned as soon as it is spent
+	PruneUsedTxOutputs bool `json:"pruneUsedTxOutputs"`
+	// BatchSize is maximum number of entries deleted in a single database transaction
+	BatchSize int `json:"batchSize"`
+	// Interval between two prune executions
+	Interval time.Duration `json:"interval"`
+}
+
+// Pruner periodically deletes old entries from the database in bounded batches,
+// so the database is never locked for a long time and block indexer can continue writing in between.
+// Block indexer starts it if the retention is configured (see BlockIndexerConfig.Retention)
+type Pruner struct {
+	config    *RetentionConfig
+	db        Database
+	isStarted uint32
+	isClosed  uint32
+	closeCh   chan struct{}
+	errorCh   chan error
+	logger    hclog.Logger
+}
+
+var _ Service = (*Pruner)(nil)
+
+func NewPruner(config *RetentionConfig, db Database, logger hclog.Logger) *Pruner {
+	return &Pruner{
+		config:  config,
+		db:      db,
+		closeCh: make(chan struct{}),
+		errorCh: make(chan error, 1),
+		logger:  logger,
+	}
+}
+
+// Start starts pruning in the background. Only the first call has effect
+func (p *Pruner) Start() {
+	if !atomic.CompareAndSwapUint32(&p.isStarted, 0, 1) {
+		return
+	}
+
+	interval := p.config.Interval
+	if interval <= 0 {
+		interval = defaultPruneInterval
+	}
+
+	go func() {
+		p.logger.Info("Pruner has been started", "interval", interval)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := p.Prune(); err != nil {
+				p.logger.Error("Prune failed", "err", err)
+
+				select {
+				case p.errorCh <- err:
+				default:
+				}
+			}
+
+			select {
+			case <-p.closeCh:
+				p.logger.Info("Pruner has been stopped")
+
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (p *Pruner) Close() error {
+	if atomic.CompareAndSwapUint32(&p.isClosed, 0, 1) {
+		close(p.closeCh)
+	}
+
+	return nil
+}
+
+func (p *Pruner) ErrorCh() <-chan error {
+	return p.errorCh
+}
+
+// Prune deletes all entries older than the prune slot and returns number of deleted entries
+func (p *Pruner) Prune() (int, error) {
+	slot, err := p.getPruneSlot()
+	if err != nil || slot == 0 {
+		return 0, err
+	}
+
+	pruneFns := []func(uint64, int) (int, error){
+		p.db.PruneConfirmedBlocks,
+		p.db.PruneProcessedTxs,
+	}
+
+	if p.config.PruneUsedTxOutputs {
+		pruneFns = append(pruneFns, p.db.PruneUsedTxOutputs)
+	}
+
+	batchSize := p.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPruneBatchSize
+	}
+
+	total := 0
+
+	for _, pruneFn := range pruneFns {
+		for {
+			cnt, err := pruneFn(slot, batchSize)
+			if err != nil {
+				return total, err
+			}
+
+			total += cnt
+
+			if cnt < batchSize {
+				break
+			}
+
+			select {
+			case <-p.closeCh:
+				return total, nil
+			default:
+			}
+		}
+	}
+
+	if total > 0 {
+		p.logger.Debug("Old entries have been pruned", "slot", slot, "count", total)
+	}
+
+	return total, nil
+}
+
+// getPruneSlot returns slot before which all entries should be pruned (zero means nothing should be pruned)
+func (p *Pruner) getPruneSlot() (uint64, error) {
+	latestPoint, err := p.db.GetLatestBlockPoint()
+	if err != nil || latestPoint == nil {
+		return 0, err
+	}
+
+	slot := uint64(0)
+
+	if p.config.SlotAge > 0 && latestPoint.BlockSlot > p.config.SlotAge {
+		slot = latestPoint.BlockSlot - p.config.SlotAge
+	}
+
+	if p.config.BlockCount > 0 {
+		blocks, err := p.db.GetLatestConfirmedBlocks(int(p.config.BlockCount)) //nolint:gosec
+		if err != nil {
+			return 0, err
+		}
+
+		if len(blocks) == int(p.config.BlockCount) { //nolint:gosec
+			slot = max(slot, blocks[len(blocks)-1].Slot)
+		}
+	}
+
+	return slot, nil
+}
@@ -0,0 +1,134 @@
+package indexer
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruner_Prune(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no latest block point", func(t *testing.T) {
+		dbMock := &DatabaseMock{}
+		dbMock.On("GetLatestBlockPoint").Return((*BlockPoint)(nil), error(nil)).Once()
+
+		cnt, err := NewPruner(&RetentionConfig{SlotAge: 10}, dbMock, hclog.NewNullLogger()).Prune()
+		require.NoError(t, err)
+		require.Equal(t, 0, cnt)
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("slot age", func(t *testing.T) {
+		dbMock := &DatabaseMock{}
+		dbMock.On("GetLatestBlockPoint").Return(&BlockPoint{BlockSlot: 100}, error(nil)).Once()
+		dbMock.On("PruneConfirmedBlocks", uint64(90), 2).Return(2, error(nil)).Once()
+		dbMock.On("PruneConfirmedBlocks", uint64(90), 2).Return(1, error(nil)).Once()
+		dbMock.On("PruneProcessedTxs", uint64(90), 2).Return(0, error(nil)).Once()
+
+		cnt, err := NewPruner(&RetentionConfig{SlotAge: 10, BatchSize: 2}, dbMock, hclog.NewNullLogger()).Prune()
+		require.NoError(t, err)
+		require.Equal(t, 3, cnt)
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("block count and used outputs", func(t *testing.T) {
+		config := &RetentionConfig{SlotAge: 90, BlockCount: 2, PruneUsedTxOutputs: true}
+		dbMock := &DatabaseMock{}
+		dbMock.On("GetLatestBlockPoint").Return(&BlockPoint{BlockSlot: 100}, error(nil)).Once()
+		dbMock.On("GetLatestConfirmedBlocks", 2).Return([]*CardanoBlock{{Slot: 100}, {Slot: 50}}, error(nil)).Once()
+		dbMock.On("PruneConfirmedBlocks", uint64(50), defaultPruneBatchSize).Return(5, error(nil)).Once()
+		dbMock.On("PruneProcessedTxs", uint64(50), defaultPruneBatchSize).Return(3, error(nil)).Once()
+		dbMock.On("PruneUsedTxOutputs", uint64(50), defaultPruneBatchSize).Return(0, error(nil)).Once()
+
+		cnt, err := NewPruner(config, dbMock, hclog.NewNullLogger()).Prune()
+		require.NoError(t, err)
+		require.Equal(t, 8, cnt)
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("not enough blocks", func(t *testing.T) {
+		dbMock := &DatabaseMock{}
+		dbMock.On("GetLatestBlockPoint").Return(&BlockPoint{BlockSlot: 100}, error(nil)).Once()
+		dbMock.On("GetLatestConfirmedBlocks", 3).Return([]*CardanoBlock{{Slot: 100}}, error(nil)).Once()
+
+		cnt, err := NewPruner(&RetentionConfig{BlockCount: 3}, dbMock, hclog.NewNullLogger()).Prune()
+		require.NoError(t, err)
+		require.Equal(t, 0, cnt)
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		dbMock := &DatabaseMock{}
+		dbMock.On("GetLatestBlockPoint").Return(&BlockPoint{BlockSlot: 100}, error(nil)).Once()
+		dbMock.On("PruneConfirmedBlocks", uint64(90), defaultPruneBatchSize).Return(0, errors.New("prune")).Once()
+
+		_, err := NewPruner(&RetentionConfig{SlotAge: 10}, dbMock, hclog.NewNullLogger()).Prune()
+		require.ErrorContains(t, err, "prune")
+		dbMock.AssertExpectations(t)
+	})
+}
+
+func TestPruner_Start(t *testing.T) {
+	t.Parallel()
+
+	dbMock := &DatabaseMock{}
+	dbMock.On("GetLatestBlockPoint").Return((*BlockPoint)(nil), errors.New("db error"))
+
+	pruner := NewPruner(&RetentionConfig{Interval: time.Millisecond}, dbMock, hclog.NewNullLogger())
+	pruner.Start()
+
+	select {
+	case err := <-pruner.ErrorCh():
+		require.ErrorContains(t, err, "db error")
+	case <-time.After(time.Second * 5):
+		t.Fatal("timeout")
+	}
+
+	require.NoError(t, pruner.Close())
+	require.NoError(t, pruner.Close())
+}
+
+func TestPruner_BlockIndexerLifecycle(t *testing.T) {
+	t.Parallel()
+
+	prunedCh := make(chan struct{}, 1)
+	dbMock := &DatabaseMock{}
+	config := &BlockIndexerConfig{
+		AddressCheck: AddressCheckAll,
+		Retention:    &RetentionConfig{SlotAge: 10, Interval: time.Hour},
+	}
+	blockIndexer := NewBlockIndexer(config, nil, dbMock, hclog.NewNullLogger())
+	runner := NewBlockIndexerRunner(blockIndexer, &BlockIndexerRunnerConfig{}, hclog.NewNullLogger())
+
+	dbMock.On("GetLatestBlockPoint").Return(&BlockPoint{BlockSlot: 100}, error(nil))
+	dbMock.On("GetAddressesOfInterest").Return([]string(nil), error(nil))
+	dbMock.On("PruneConfirmedBlocks", uint64(90), defaultPruneBatchSize).Return(0, error(nil))
+	dbMock.On("PruneProcessedTxs", uint64(90), defaultPruneBatchSize).Return(0, error(nil)).Run(func(mock.Arguments) {
+		prunedCh <- struct{}{}
+	})
+
+	require.NotNil(t, blockIndexer.pruner)
+
+	// pruner is started with the first reset of the indexer
+	_, err := runner.Reset()
+	require.NoError(t, err)
+
+	select {
+	case <-prunedCh:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timeout")
+	}
+
+	// and stopped when the runner is closed
+	require.NoError(t, runner.Close())
+	require.Equal(t, uint32(1), atomic.LoadUint32(&blockIndexer.pruner.isClosed))
+
+	// retention is ignored if the database does not support pruning
+	require.Nil(t, NewBlockIndexer(config, nil, struct{ BlockIndexerDB }{dbMock}, hclog.NewNullLogger()).pruner)
+}
@@ -0,0 +1,232 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+type RescanConfig struct {
+	// StartingBlockPoint is the point after which syncing starts (chain origin if nil).
+	// It should be as close as possible to FromSlot, because all the headers between them are synced too
+	StartingBlockPoint *BlockPoint `json:"startingBlockPoint"`
+	// FromSlot is the first slot of the rescanned range
+	FromSlot uint64 `json:"fromSlot"`
+	// ToSlot is the last slot of the rescanned range. Latest block point of the database
+	// at the moment when rescan starts is used if it is zero. Blocks after the latest block point are never rescanned
+	ToSlot uint64 `json:"toSlot"`
+	// Addresses are the only addresses which are rescanned
+	Addresses      []string `json:"addresses"`
+	SoftDeleteUtxo bool     `json:"softDeleteUtxo"`
+}
+
+// Rescanner is block syncer handler which indexes historical blocks for the given addresses.
+// It saves outputs and confirmed txs of the addresses for all blocks inside the slot range.
+// Live block indexer can write into the same database in the meantime, so after the range is rescanned,
+// rescanner continues to remove spent outputs until it reaches the latest block point of the database.
+// Rescanned blocks are not added to the undo journal
+type Rescanner struct {
+	config    *RescanConfig
+	addresses map[string]bool
+	db        BlockIndexerDB
+
+	lastPoint *BlockPoint
+	toSlot    uint64
+	txsCnt    int
+	isDone    bool
+	doneCh    chan struct{}
+	mutex     sync.Mutex
+	logger    hclog.Logger
+}
+
+var _ BlockSyncerHandler = (*Rescanner)(nil)
+
+func NewRescanner(config *RescanConfig, db BlockIndexerDB, logger hclog.Logger) *Rescanner {
+	addresses := make(map[string]bool, len(config.Addresses))
+	for _, x := range config.Addresses {
+		addresses[x] = true
+	}
+
+	return &Rescanner{
+		config:    config,
+		addresses: addresses,
+		db:        db,
+		toSlot:    config.ToSlot,
+		doneCh:    make(chan struct{}),
+		logger:    logger,
+	}
+}
+
+// Done is closed when the whole range is rescanned
+func (r *Rescanner) Done() <-chan struct{} {
+	return r.doneCh
+}
+
+// TxsCount returns number of confirmed txs saved by the rescan
+func (r *Rescanner) TxsCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.txsCnt
+}
+
+func (r *Rescanner) Reset() (BlockPoint, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.toSlot == 0 {
+		latestPoint, err := r.db.GetLatestBlockPoint()
+		if err != nil {
+			return BlockPoint{}, err
+		}
+
+		if latestPoint == nil || latestPoint.BlockSlot < r.config.FromSlot {
+			r.markDoneNoLock()
+		} else {
+			r.toSlot = latestPoint.BlockSlot
+		}
+	}
+
+	// continue from the last processed block if syncer has been restarted
+	if r.lastPoint != nil {
+		return *r.lastPoint, nil
+	}
+
+	if r.config.StartingBlockPoint != nil {
+		return *r.config.StartingBlockPoint, nil
+	}
+
+	return BlockPoint{}, nil
+}
+
+func (r *Rescanner) RollBackward(point BlockPoint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.isDone || r.lastPoint == nil || point.BlockSlot >= r.lastPoint.BlockSlot {
+		return nil
+	}
+
+	// rescanned blocks are older than the latest confirmed block of the database, so this should never happen
+	return errors.Join(ErrBlockIndexerFatal,
+		fmt.Errorf("rescan: roll backward to %s before the last processed block %s", &point, r.lastPoint))
+}
+
+func (r *Rescanner) RollForward(blockHeader BlockHeader, txsRetriever BlockTxsRetriever) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.isDone {
+		return nil
+	}
+
+	latestPoint, err := r.db.GetLatestBlockPoint()
+	if err != nil {
+		return err
+	}
+
+	// rescanner must not process blocks not yet confirmed by the live block indexer
+	if latestPoint == nil || blockHeader.Slot > latestPoint.BlockSlot {
+		r.markDoneNoLock()
+
+		return nil
+	}
+
+	if blockHeader.Slot >= r.config.FromSlot {
+		if err := r.processBlock(blockHeader, txsRetriever, blockHeader.Slot <= r.toSlot); err != nil {
+			return err
+		}
+	}
+
+	r.lastPoint = &BlockPoint{
+		BlockSlot: blockHeader.Slot,
+		BlockHash: blockHeader.Hash,
+	}
+
+	if blockHeader.Slot == latestPoint.BlockSlot {
+		r.markDoneNoLock()
+	}
+
+	return nil
+}
+
+func (r *Rescanner) processBlock(blockHeader BlockHeader, txsRetriever BlockTxsRetriever, isInRange bool) error {
+	txs, err := txsRetriever.GetBlockTransactions(blockHeader)
+	if err != nil {
+		return err
+	}
+
+	var relevantTxs []*Tx
+
+	for _, tx := range txs {
+		for _, inp := range tx.Inputs {
+			if inp.Output.Address == "" {
+				if inp.Output, err = r.db.GetTxOutput(inp.Input); err != nil {
+					return err
+				}
+			}
+		}
+
+		if r.isTxOfInterest(tx) {
+			relevantTxs = append(relevantTxs, tx)
+		}
+	}
+
+	if len(relevantTxs) == 0 {
+		return nil
+	}
+
+	dbTx := r.db.OpenTx()
+
+	// outside of the range only spent outputs are removed
+	if isInRange {
+		dbTx.AddConfirmedTxs(relevantTxs).AddTxOutputs(getTxOutputs(relevantTxs, r.isAddressOfInterest))
+	}
+
+	dbTx.RemoveTxOutputs(getTxInputs(relevantTxs, r.isAddressOfInterest), r.config.SoftDeleteUtxo)
+
+	if err := dbTx.Execute(); err != nil {
+		return err
+	}
+
+	if isInRange {
+		r.txsCnt += len(relevantTxs)
+	}
+
+	r.logger.Debug("Block has been rescanned", "slot", blockHeader.Slot, "hash", blockHeader.Hash,
+		"txs", len(relevantTxs), "inRange", isInRange)
+
+	return nil
+}
+
+func (r *Rescanner) isTxOfInterest(tx *Tx) bool {
+	for _, inp := range tx.Inputs {
+		if r.isAddressOfInterest(inp.Output.Address) {
+			return true
+		}
+	}
+
+	for _, out := range tx.Outputs {
+		if r.isAddressOfInterest(out.Address) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rescanner) isAddressOfInterest(address string) bool {
+	return r.addresses[address]
+}
+
+func (r *Rescanner) markDoneNoLock() {
+	if !r.isDone {
+		r.isDone = true
+
+		r.logger.Info("Rescan has been finished", "from", r.config.FromSlot, "to", r.toSlot, "txs", r.txsCnt)
+
+		close(r.doneCh)
+	}
+}
@@ -0,0 +1,138 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRescanner(t *testing.T) {
+	t.Parallel()
+
+	const address = "addr_test1"
+
+	var (
+		latestSlot = uint64(30)
+		startPoint = BlockPoint{BlockSlot: 3, BlockHash: Hash{3}}
+		outputTx   = &Tx{
+			BlockSlot: 10,
+			Hash:      Hash{10},
+			Outputs:   []*TxOutput{{Address: address, Amount: 100}, {Address: "addr_test2", Amount: 200}},
+		}
+		spendingTx = &Tx{
+			BlockSlot: 35,
+			Hash:      Hash{35},
+			Inputs:    []*TxInputOutput{{Input: TxInput{Hash: Hash{10}}}},
+			Outputs:   []*TxOutput{{Address: "addr_test2", Amount: 100}},
+		}
+		blockTxs = map[uint64][]*Tx{
+			10: {outputTx, {BlockSlot: 10, Indx: 1, Hash: Hash{11}}},
+			35: {spendingTx},
+		}
+	)
+
+	dbMock := &DatabaseMock{
+		Writter: &DBTransactionWriterMock{},
+		GetLatestBlockPointFn: func() (*BlockPoint, error) {
+			return &BlockPoint{BlockSlot: latestSlot}, nil
+		},
+		GetTxOutputFn: func(txInput TxInput) (TxOutput, error) {
+			if txInput.Hash == outputTx.Hash && txInput.Index == 0 {
+				return *outputTx.Outputs[0], nil
+			}
+
+			return TxOutput{}, nil
+		},
+	}
+	dbMock.On("GetLatestBlockPoint")
+	dbMock.On("GetTxOutput", mock.Anything)
+	dbMock.On("OpenTx")
+
+	txsRetriever := &BlockTxsRetrieverMock{
+		RetrieveFn: func(blockHeader BlockHeader) ([]*Tx, error) {
+			if blockHeader.Slot < 10 {
+				return nil, errors.New("blocks before the range should not be fetched")
+			}
+
+			return blockTxs[blockHeader.Slot], nil
+		},
+	}
+
+	rescanner := NewRescanner(&RescanConfig{
+		StartingBlockPoint: &startPoint,
+		FromSlot:           10,
+		Addresses:          []string{address},
+	}, dbMock, hclog.NewNullLogger())
+
+	point, err := rescanner.Reset()
+	require.NoError(t, err)
+	require.Equal(t, startPoint, point)
+
+	require.NoError(t, rescanner.RollBackward(startPoint))
+	require.NoError(t, rescanner.RollForward(BlockHeader{Slot: 5, Hash: Hash{5}}, txsRetriever))
+
+	dbMock.Writter.On("AddConfirmedTxs", []*Tx{outputTx}).Once()
+	dbMock.Writter.On("AddTxOutputs", []*TxInputOutput{
+		{Input: TxInput{Hash: outputTx.Hash}, Output: *outputTx.Outputs[0]},
+	}).Once()
+	dbMock.Writter.On("RemoveTxOutputs", []TxInput(nil), false).Once()
+	dbMock.Writter.On("Execute").Return(error(nil)).Once()
+
+	require.NoError(t, rescanner.RollForward(BlockHeader{Slot: 10, Hash: Hash{10}}, txsRetriever))
+	require.Equal(t, 1, rescanner.TxsCount())
+
+	// live block indexer has confirmed new blocks in the meantime
+	latestSlot = 40
+
+	// after the range only spent outputs are removed
+	dbMock.Writter.On("RemoveTxOutputs", []TxInput{{Hash: outputTx.Hash}}, false).Once()
+	dbMock.Writter.On("Execute").Return(error(nil)).Once()
+
+	require.NoError(t, rescanner.RollForward(BlockHeader{Slot: 35, Hash: Hash{35}}, txsRetriever))
+	require.ErrorIs(t, rescanner.RollBackward(BlockPoint{BlockSlot: 10}), ErrBlockIndexerFatal)
+
+	select {
+	case <-rescanner.Done():
+		t.Fatal("rescanner should not be done")
+	default:
+	}
+
+	require.NoError(t, rescanner.RollForward(BlockHeader{Slot: 40, Hash: Hash{40}}, txsRetriever))
+
+	select {
+	case <-rescanner.Done():
+	default:
+		t.Fatal("rescanner should be done")
+	}
+
+	// everything is ignored after the rescan is done
+	require.NoError(t, rescanner.RollForward(BlockHeader{Slot: 41, Hash: Hash{41}}, txsRetriever))
+	require.NoError(t, rescanner.RollBackward(BlockPoint{BlockSlot: 10}))
+	require.Equal(t, 1, rescanner.TxsCount())
+	dbMock.Writter.AssertExpectations(t)
+}
+
+func TestRescanner_NothingToRescan(t *testing.T) {
+	t.Parallel()
+
+	dbMock := &DatabaseMock{}
+	dbMock.On("GetLatestBlockPoint").Return(&BlockPoint{BlockSlot: 5}, error(nil)).Once()
+
+	rescanner := NewRescanner(&RescanConfig{
+		FromSlot:  10,
+		Addresses: []string{"addr_test1"},
+	}, dbMock, hclog.NewNullLogger())
+
+	point, err := rescanner.Reset()
+	require.NoError(t, err)
+	require.Equal(t, BlockPoint{}, point)
+
+	select {
+	case <-rescanner.Done():
+	default:
+		t.Fatal("rescanner should be done")
+	}
+}
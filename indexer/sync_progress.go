@@ -0,0 +1,140 @@
+package indexer
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	SyncedSlotLagDefault = 120
+
+	catchUpRateInterval = 10 * time.Second
+)
+
+// SyncProgress describes how far the processed chain is from the chain tip
+type SyncProgress struct {
+	Tip BlockPoint `json:"tip"`
+	// LatestPoint is the last processed (rolled forward or backward) point
+	LatestPoint BlockPoint `json:"latestPoint"`
+	SlotLag     uint64     `json:"slotLag"`
+	// EstimatedCatchUpTime is estimated from the recent lag decrease. It is zero if synced or unknown
+	EstimatedCatchUpTime time.Duration `json:"estimatedCatchUpTime"`
+	IsSynced             bool          `json:"isSynced"`
+}
+
+type SyncProgressProvider interface {
+	GetSyncProgress() SyncProgress
+}
+
+// SyncStateHandler is called every time the synced state changes
+type SyncStateHandler func(progress SyncProgress)
+
+// SyncProgressTracker tracks the tip and the latest processed point.
+// Sync is considered synced while the slot lag is not greater than syncedSlotLag
+type SyncProgressTracker struct {
+	syncedSlotLag uint64
+	handler       SyncStateHandler
+
+	tip         BlockPoint
+	latestPoint BlockPoint
+	isSynced    bool
+	// lag decrease in slots per second measured over the last interval
+	catchUpRate     float64
+	rateInterval    time.Duration
+	rateWindowStart time.Time
+	rateWindowLag   uint64
+	lock            sync.Mutex
+}
+
+var _ SyncProgressProvider = (*SyncProgressTracker)(nil)
+
+func NewSyncProgressTracker(syncedSlotLag uint64) *SyncProgressTracker {
+	if syncedSlotLag == 0 {
+		syncedSlotLag = SyncedSlotLagDefault
+	}
+
+	return &SyncProgressTracker{
+		syncedSlotLag: syncedSlotLag,
+		rateInterval:  catchUpRateInterval,
+	}
+}
+
+// SetSyncStateHandler sets handler which is notified when the synced state changes
+func (t *SyncProgressTracker) SetSyncStateHandler(handler SyncStateHandler) {
+	t.lock.Lock()
+	t.handler = handler
+	t.lock.Unlock()
+}
+
+func (t *SyncProgressTracker) SetTip(tip BlockPoint) {
+	t.update(func() {
+		t.tip = tip
+	})
+}
+
+func (t *SyncProgressTracker) SetLatestPoint(point BlockPoint) {
+	t.update(func() {
+		t.latestPoint = point
+	})
+}
+
+func (t *SyncProgressTracker) GetSyncProgress() SyncProgress {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.getProgressNoLock()
+}
+
+func (t *SyncProgressTracker) update(fn func()) {
+	t.lock.Lock()
+
+	hasTip := t.tip.BlockSlot > 0
+
+	fn()
+
+	lag := t.getSlotLagNoLock()
+	now := time.Now()
+
+	// lag is not known until the tip is received
+	if t.rateWindowStart.IsZero() || !hasTip {
+		t.rateWindowStart, t.rateWindowLag = now, lag
+	} else if elapsed := now.Sub(t.rateWindowStart); elapsed >= t.rateInterval {
+		t.catchUpRate = (float64(t.rateWindowLag) - float64(lag)) / elapsed.Seconds()
+		t.rateWindowStart, t.rateWindowLag = now, lag
+	}
+
+	isSynced := lag <= t.syncedSlotLag && t.tip.BlockSlot > 0
+	isChanged := isSynced != t.isSynced
+	t.isSynced = isSynced
+	progress := t.getProgressNoLock()
+	handler := t.handler
+
+	t.lock.Unlock()
+
+	if isChanged && handler != nil {
+		handler(progress)
+	}
+}
+
+func (t *SyncProgressTracker) getProgressNoLock() SyncProgress {
+	progress := SyncProgress{
+		Tip:         t.tip,
+		LatestPoint: t.latestPoint,
+		SlotLag:     t.getSlotLagNoLock(),
+		IsSynced:    t.isSynced,
+	}
+
+	if !progress.IsSynced && t.catchUpRate > 0 {
+		progress.EstimatedCatchUpTime = time.Duration(float64(progress.SlotLag) / t.catchUpRate * float64(time.Second))
+	}
+
+	return progress
+}
+
+func (t *SyncProgressTracker) getSlotLagNoLock() uint64 {
+	if t.tip.BlockSlot <= t.latestPoint.BlockSlot {
+		return 0
+	}
+
+	return t.tip.BlockSlot - t.latestPoint.BlockSlot
+}
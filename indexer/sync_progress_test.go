@@ -0,0 +1,61 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncProgressTracker(t *testing.T) {
+	t.Parallel()
+
+	var events []SyncProgress
+
+	tracker := NewSyncProgressTracker(10)
+	tracker.rateInterval = time.Millisecond * 20
+	tracker.SetSyncStateHandler(func(progress SyncProgress) {
+		events = append(events, progress)
+	})
+
+	// tip is not known yet
+	tracker.SetLatestPoint(BlockPoint{BlockSlot: 100})
+	require.Equal(t, SyncProgress{LatestPoint: BlockPoint{BlockSlot: 100}}, tracker.GetSyncProgress())
+
+	tracker.SetTip(BlockPoint{BlockSlot: 1100, BlockHash: Hash{1}})
+
+	progress := tracker.GetSyncProgress()
+	require.False(t, progress.IsSynced)
+	require.Equal(t, uint64(1000), progress.SlotLag)
+	require.Zero(t, progress.EstimatedCatchUpTime)
+
+	time.Sleep(time.Millisecond * 30)
+
+	// lag is decreased by 500 slots -> the same time is needed for the rest
+	tracker.SetLatestPoint(BlockPoint{BlockSlot: 600})
+
+	progress = tracker.GetSyncProgress()
+	require.Equal(t, uint64(500), progress.SlotLag)
+	require.Greater(t, progress.EstimatedCatchUpTime, time.Millisecond*20)
+	require.Less(t, progress.EstimatedCatchUpTime, time.Second)
+	require.Empty(t, events)
+
+	tracker.SetLatestPoint(BlockPoint{BlockSlot: 1095})
+
+	require.Len(t, events, 1)
+	require.Equal(t, SyncProgress{
+		Tip:         BlockPoint{BlockSlot: 1100, BlockHash: Hash{1}},
+		LatestPoint: BlockPoint{BlockSlot: 1095},
+		SlotLag:     5,
+		IsSynced:    true,
+	}, events[0])
+
+	// no state change
+	tracker.SetLatestPoint(BlockPoint{BlockSlot: 1100})
+	require.Len(t, events, 1)
+
+	tracker.SetTip(BlockPoint{BlockSlot: 1200})
+	require.Len(t, events, 2)
+	require.False(t, events[1].IsSynced)
+	require.Equal(t, uint64(100), events[1].SlotLag)
+}
@@ -2,6 +2,9 @@ package indexer
 
 import (
 	"errors"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -134,6 +137,60 @@ func (m *DatabaseMock) GetAllTxOutputs(address string, onlyNotUser bool) ([]*TxI
 	return args.Get(0).([]*TxInputOutput), args.Error(1)
 }
 
+func (m *DatabaseMock) GetTxByHash(hash Hash) (*Tx, error) {
+	args := m.Called(hash)
+
+	//nolint:forcetypeassert
+	return args.Get(0).(*Tx), args.Error(1)
+}
+
+func (m *DatabaseMock) GetConfirmedBlockTxs(blockSlot uint64) ([]*Tx, error) {
+	args := m.Called(blockSlot)
+
+	//nolint:forcetypeassert
+	return args.Get(0).([]*Tx), args.Error(1)
+}
+
+func (m *DatabaseMock) GetUndoRecords(afterSlot uint64) ([]*UndoRecord, error) {
+	args := m.Called(afterSlot)
+
+	//nolint:forcetypeassert
+	return args.Get(0).([]*UndoRecord), args.Error(1)
+}
+
+func (m *DatabaseMock) GetAddressesOfInterest() ([]string, error) {
+	args := m.Called()
+
+	//nolint:forcetypeassert
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *DatabaseMock) Export(w io.Writer) error {
+	return m.Called(w).Error(0)
+}
+
+func (m *DatabaseMock) Import(r io.Reader) error {
+	return m.Called(r).Error(0)
+}
+
+func (m *DatabaseMock) PruneConfirmedBlocks(slotNumber uint64, maxCnt int) (int, error) {
+	args := m.Called(slotNumber, maxCnt)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *DatabaseMock) PruneProcessedTxs(slotNumber uint64, maxCnt int) (int, error) {
+	args := m.Called(slotNumber, maxCnt)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *DatabaseMock) PruneUsedTxOutputs(slotNumber uint64, maxCnt int) (int, error) {
+	args := m.Called(slotNumber, maxCnt)
+
+	return args.Int(0), args.Error(1)
+}
+
 var _ Database = (*DatabaseMock)(nil)
 
 type DBTransactionWriterMock struct {
@@ -210,6 +267,24 @@ func (m *DBTransactionWriterMock) DeleteAllTxOutputsPhysically() DBTransactionWr
 	return m
 }
 
+func (m *DBTransactionWriterMock) AddUndoRecord(record *UndoRecord, maxDepth uint) DBTransactionWriter {
+	m.Called(record, maxDepth)
+
+	return m
+}
+
+func (m *DBTransactionWriterMock) RevertUndoRecord(record *UndoRecord) DBTransactionWriter {
+	m.Called(record)
+
+	return m
+}
+
+func (m *DBTransactionWriterMock) SetAddressesOfInterest(addresses []string) DBTransactionWriter {
+	m.Called(addresses)
+
+	return m
+}
+
 var _ DBTransactionWriter = (*DBTransactionWriterMock)(nil)
 
 type BlockTxsRetrieverMock struct {
@@ -267,3 +342,63 @@ func (hMock *BlockSyncerHandlerMock) Reset() (BlockPoint, error) {
 
 	return *hMock.defBlockPoint, nil
 }
+
+// MetricsMock records the measurements by the metric name (labels are ignored)
+type MetricsMock struct {
+	Counters     map[string]float64
+	Gauges       map[string]float64
+	Observations map[string]int
+	lock         sync.Mutex
+}
+
+var _ Metrics = (*MetricsMock)(nil)
+
+func NewMetricsMock() *MetricsMock {
+	return &MetricsMock{
+		Counters:     map[string]float64{},
+		Gauges:       map[string]float64{},
+		Observations: map[string]int{},
+	}
+}
+
+func (m *MetricsMock) AddCounter(name string, value float64, _ ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.Counters[name] += value
+}
+
+func (m *MetricsMock) SetGauge(name string, value float64, _ ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.Gauges[name] = value
+}
+
+func (m *MetricsMock) ObserveDuration(name string, _ time.Duration, _ ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.Observations[name]++
+}
+
+func (m *MetricsMock) GetCounter(name string) float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.Counters[name]
+}
+
+func (m *MetricsMock) GetGauge(name string) float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.Gauges[name]
+}
+
+func (m *MetricsMock) GetObservations(name string) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.Observations[name]
+}
@@ -0,0 +1,68 @@
+package indexer
+
+import (
+	"sync"
+	"time"
+)
+
+const throughputIntervalDefault = 30 * time.Second
+
+// ThroughputStats are the confirmed blocks processing counters of the block indexer
+type ThroughputStats struct {
+	ConfirmedBlocks uint64 `json:"confirmedBlocks"`
+	// Txs is the number of all the txs in the confirmed blocks (not only relevant ones)
+	Txs uint64 `json:"txs"`
+	// Commits is the number of the database transactions used to save confirmed blocks
+	Commits uint64 `json:"commits"`
+	// rates measured in the last completed interval
+	BlocksPerSecond float64 `json:"blocksPerSecond"`
+	TxsPerSecond    float64 `json:"txsPerSecond"`
+}
+
+type throughputMeter struct {
+	stats          ThroughputStats
+	interval       time.Duration
+	intervalStart  time.Time
+	intervalBlocks uint64
+	intervalTxs    uint64
+	lock           sync.Mutex
+}
+
+func newThroughputMeter(interval time.Duration) *throughputMeter {
+	return &throughputMeter{
+		interval:      interval,
+		intervalStart: time.Now(),
+	}
+}
+
+// add records the committed blocks and returns true if the interval is completed and the rates are updated
+func (m *throughputMeter) add(blocks int, txs int) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.stats.ConfirmedBlocks += uint64(blocks) //nolint:gosec
+	m.stats.Txs += uint64(txs)                //nolint:gosec
+	m.stats.Commits++
+	m.intervalBlocks += uint64(blocks) //nolint:gosec
+	m.intervalTxs += uint64(txs)       //nolint:gosec
+
+	elapsed := time.Since(m.intervalStart)
+	if elapsed < m.interval {
+		return false
+	}
+
+	m.stats.BlocksPerSecond = float64(m.intervalBlocks) / elapsed.Seconds()
+	m.stats.TxsPerSecond = float64(m.intervalTxs) / elapsed.Seconds()
+	m.intervalStart = time.Now()
+	m.intervalBlocks = 0
+	m.intervalTxs = 0
+
+	return true
+}
+
+func (m *throughputMeter) get() ThroughputStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.stats
+}
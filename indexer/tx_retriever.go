@@ -0,0 +1,42 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Ethernal-Tech/cardano-infrastructure/wallet"
+)
+
+// DatabaseTxRetriever retrieves txs from the indexer database. Only txs of interest are saved in the database
+type DatabaseTxRetriever struct {
+	db Database
+}
+
+var _ wallet.ITxRetriever = (*DatabaseTxRetriever)(nil)
+
+func NewDatabaseTxRetriever(db Database) *DatabaseTxRetriever {
+	return &DatabaseTxRetriever{
+		db: db,
+	}
+}
+
+// GetTxByHash returns json fields of the tx or nil if the tx is not (yet) confirmed
+func (r *DatabaseTxRetriever) GetTxByHash(_ context.Context, hash string) (map[string]interface{}, error) {
+	tx, err := r.db.GetTxByHash(NewHashFromHexString(hash))
+	if err != nil || tx == nil {
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
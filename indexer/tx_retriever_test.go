@@ -0,0 +1,32 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseTxRetriever(t *testing.T) {
+	t.Parallel()
+
+	tx := &Tx{BlockSlot: 10, BlockHash: Hash{1}, Hash: Hash{2}, Fee: 100, Valid: true}
+	dbMock := &DatabaseMock{}
+
+	dbMock.On("GetTxByHash", tx.Hash).Return(tx, error(nil)).Once()
+	dbMock.On("GetTxByHash", Hash{3}).Return((*Tx)(nil), error(nil)).Once()
+
+	retriever := NewDatabaseTxRetriever(dbMock)
+
+	result, err := retriever.GetTxByHash(context.Background(), tx.Hash.String())
+	require.NoError(t, err)
+	require.Equal(t, float64(10), result["slot"])
+	require.Equal(t, float64(100), result["fee"])
+	require.Equal(t, true, result["valid"])
+
+	result, err = retriever.GetTxByHash(context.Background(), Hash{3}.String())
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	dbMock.AssertExpectations(t)
+}
@@ -54,10 +54,11 @@ func getTxHashes(txs []*Tx) []Hash {
 	return result
 }
 
-func getTxOutputs(txs []*Tx, addressesOfInterest map[string]bool) (res []*TxInputOutput) {
+// getTxOutputs returns outputs of the txs with addresses of interest (all outputs if isAddressOfInterest is nil)
+func getTxOutputs(txs []*Tx, isAddressOfInterest func(string) bool) (res []*TxInputOutput) {
 	for _, tx := range txs {
 		for outIndex, txOut := range tx.Outputs {
-			if len(addressesOfInterest) == 0 || addressesOfInterest[txOut.Address] {
+			if isAddressOfInterest == nil || isAddressOfInterest(txOut.Address) {
 				res = append(res, &TxInputOutput{
 					Input: TxInput{
 						Hash:  tx.Hash,
@@ -72,10 +73,11 @@ func getTxOutputs(txs []*Tx, addressesOfInterest map[string]bool) (res []*TxInpu
 	return res
 }
 
-func getTxInputs(txs []*Tx, addressesOfInterest map[string]bool) (res []TxInput) {
+// getTxInputs returns inputs of the txs with addresses of interest (all inputs if isAddressOfInterest is nil)
+func getTxInputs(txs []*Tx, isAddressOfInterest func(string) bool) (res []TxInput) {
 	for _, tx := range txs {
 		for _, inp := range tx.Inputs {
-			if len(addressesOfInterest) == 0 || addressesOfInterest[inp.Output.Address] {
+			if isAddressOfInterest == nil || isAddressOfInterest(inp.Output.Address) {
 				res = append(res, inp.Input)
 			}
 		}
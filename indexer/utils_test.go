@@ -101,14 +101,16 @@ func TestGetTxOutputsAndInputsFuncs(t *testing.T) {
 			},
 		},
 	}
-	addressesOfInterest := map[string]bool{address: true}
+	isAddressOfInterest := func(addr string) bool {
+		return addr == address
+	}
 
 	t.Run("getTxOutputs retrieve all", func(t *testing.T) {
 		require.Len(t, getTxOutputs(txs, nil), 4)
 	})
 
 	t.Run("getTxOutputs retrieve filtered", func(t *testing.T) {
-		require.Len(t, getTxOutputs(txs, addressesOfInterest), 2)
+		require.Len(t, getTxOutputs(txs, isAddressOfInterest), 2)
 	})
 
 	t.Run("getTxInputs retrieve all", func(t *testing.T) {
@@ -116,6 +118,6 @@ func TestGetTxOutputsAndInputsFuncs(t *testing.T) {
 	})
 
 	t.Run("getTxInputs retrieve filtered", func(t *testing.T) {
-		require.Len(t, getTxInputs(txs, addressesOfInterest), 2)
+		require.Len(t, getTxInputs(txs, isAddressOfInterest), 2)
 	})
 }
@@ -13,6 +13,7 @@ const (
 )
 
 type ChainConfig struct {
+	// CardanoCliBinary is path to cardano-cli. If empty, transactions are built natively (without cardano-cli)
 	CardanoCliBinary     string
 	TxProvider           cardanowallet.ITxProvider
 	MultiSigAddr         string
@@ -100,14 +100,14 @@ func (txSnd *TxSender) CreateTxGeneric(
 		return nil, fmt.Errorf("chain %s config not found", txDto.SrcChainID)
 	}
 
-	txBuilder, err := cardanowallet.NewTxBuilder(srcConfig.CardanoCliBinary)
+	txBuilder, err := newTxBuilder(srcConfig.CardanoCliBinary)
 	if err != nil {
 		return nil, err
 	}
 
 	defer txBuilder.Dispose()
 
-	if err := checkAddress(txDto.SenderAddr, txDto.SenderAddrPolicyScript, &srcConfig); err != nil {
+	if err := checkAddress(txDto.SenderAddr, txDto.SenderAddrPolicyScript); err != nil {
 		return nil, err
 	}
 
@@ -134,7 +134,7 @@ func (txSnd *TxSender) SubmitTx(
 		return fmt.Errorf("%s chain config not found", chainID)
 	}
 
-	builder, err := cardanowallet.NewTxBuilder(chainConfig.CardanoCliBinary)
+	builder, err := newTxBuilder(chainConfig.CardanoCliBinary)
 	if err != nil {
 		return err
 	}
@@ -216,7 +216,7 @@ func (txSnd *TxSender) prepareBridgingTx(
 	}
 
 	if validateAddressData {
-		if err := checkAddress(txDto.SenderAddr, txDto.SenderAddrPolicyScript, srcConfig); err != nil {
+		if err := checkAddress(txDto.SenderAddr, txDto.SenderAddrPolicyScript); err != nil {
 			return nil, err
 		}
 	}
@@ -227,7 +227,7 @@ func (txSnd *TxSender) prepareBridgingTx(
 		return nil, err
 	}
 
-	txBuilder, err := cardanowallet.NewTxBuilder(srcConfig.CardanoCliBinary)
+	txBuilder, err := newTxBuilder(srcConfig.CardanoCliBinary)
 	if err != nil {
 		return nil, err
 	}
@@ -579,16 +579,14 @@ func getOutputAmounts(receivers []BridgingTxReceiver) (outputCurrencyLovelace ui
 	return outputCurrencyLovelace
 }
 
-func checkAddress(
-	addrStr string, policyScript *cardanowallet.PolicyScript, config *ChainConfig,
-) error {
+func checkAddress(addrStr string, policyScript *cardanowallet.PolicyScript) error {
 	addr, err := cardanowallet.NewCardanoAddressFromString(addrStr)
 	if err != nil {
 		return fmt.Errorf("invalid address: %w", err)
 	}
 
 	if policyScript != nil {
-		policyID, err := cardanowallet.NewCliUtils(config.CardanoCliBinary).GetPolicyID(policyScript)
+		policyID, err := policyScript.GetPolicyID()
 		if err != nil {
 			return fmt.Errorf("failed to retrieve policy id: %w", err)
 		}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	infracommon "github.com/Ethernal-Tech/cardano-infrastructure/common"
+	cardanowallet "github.com/Ethernal-Tech/cardano-infrastructure/wallet"
 )
 
 func AddrToMetaDataAddr(addr string) []string {
@@ -21,3 +22,12 @@ func setOrDefault[T comparable](val, def T) T {
 
 	return val
 }
+
+// newTxBuilder creates cardano-cli tx builder or native one if cardano-cli binary is not specified
+func newTxBuilder(cardanoCliBinary string) (*cardanowallet.TxBuilder, error) {
+	if cardanoCliBinary == "" {
+		return cardanowallet.NewTxBuilderNative(), nil
+	}
+
+	return cardanowallet.NewTxBuilder(cardanoCliBinary)
+}
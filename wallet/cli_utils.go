@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/Ethernal-Tech/cardano-infrastructure/wallet/bech32"
@@ -303,65 +304,126 @@ func (cu CliUtils) getTxHash(txRaw []byte, baseDirectory, eraName string) (strin
 
 func (cu CliUtils) CreateRegistrationCertificate(
 	stakeAddress string, keyRegDepositAmount uint64,
-) (cert *Certificate, err error) {
-	baseDirectory, err := os.MkdirTemp("", "registration-cert")
+) (*Certificate, error) {
+	cert, err := cu.createCertificate("registration-cert", []string{
+		"stake-address", "registration-certificate",
+		"--stake-address", stakeAddress,
+		"--key-reg-deposit-amt", fmt.Sprintf("%d", keyRegDepositAmount),
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to register certificate: %w", err)
 	}
 
-	defer os.RemoveAll(baseDirectory)
-
-	certFilePath := filepath.Join(baseDirectory, "registration.cert")
+	return cert, nil
+}
 
+func (cu CliUtils) CreateDelegationCertificate(
+	stakeAddress string, poolID string,
+) (*Certificate, error) {
+	// On update to newer version this will fail because of the change:
+	// delegation-certificate -> stake-delegation-certificate
 	args := []string{
-		cu.era, "stake-address", "registration-certificate",
+		"stake-address", "delegation-certificate",
 		"--stake-address", stakeAddress,
-		"--key-reg-deposit-amt", fmt.Sprintf("%d", keyRegDepositAmount),
-		"--out-file", certFilePath}
+		"--stake-pool-id", poolID,
+	}
 
-	_, err = runCommand(cu.cardanoCliBinary, args)
+	cert, err := cu.createCertificate("delegation-cert", args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to register certificate: %w", err)
+		args[1] = "stake-delegation-certificate"
+
+		if cert, err = cu.createCertificate("delegation-cert", args); err != nil {
+			return nil, fmt.Errorf("failed to delegate certificate: %w", err)
+		}
 	}
 
-	bytes, err := os.ReadFile(certFilePath)
+	return cert, nil
+}
+
+// CreateDRepRegistrationCertificate creates drep registration certificate with cardano-cli
+func (cu CliUtils) CreateDRepRegistrationCertificate(
+	drep DRep, depositAmount uint64, anchor *Anchor,
+) (*Certificate, error) {
+	drepArgs, err := getDRepCredentialArgs(drep)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate: %w", err)
+		return nil, err
 	}
 
-	if err := json.Unmarshal(bytes, &cert); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal certificate: %w", err)
+	args := append([]string{
+		"governance", "drep", "registration-certificate",
+		"--key-reg-deposit-amt", strconv.FormatUint(depositAmount, 10),
+	}, append(drepArgs, getAnchorArgs("drep-metadata", anchor)...)...)
+
+	return cu.createCertificate("drep-registration-cert", args)
+}
+
+// CreateDRepUpdateCertificate creates drep update certificate with cardano-cli
+func (cu CliUtils) CreateDRepUpdateCertificate(drep DRep, anchor *Anchor) (*Certificate, error) {
+	drepArgs, err := getDRepCredentialArgs(drep)
+	if err != nil {
+		return nil, err
 	}
 
-	return cert, nil
+	args := append([]string{
+		"governance", "drep", "update-certificate",
+	}, append(drepArgs, getAnchorArgs("drep-metadata", anchor)...)...)
+
+	return cu.createCertificate("drep-update-cert", args)
 }
 
-func (cu CliUtils) CreateDelegationCertificate(
-	stakeAddress string, poolID string,
-) (cert *Certificate, err error) {
-	baseDirectory, err := os.MkdirTemp("", "delegation-cert")
+// CreateDRepRetirementCertificate creates drep retirement certificate with cardano-cli
+func (cu CliUtils) CreateDRepRetirementCertificate(drep DRep, depositAmount uint64) (*Certificate, error) {
+	drepArgs, err := getDRepCredentialArgs(drep)
 	if err != nil {
 		return nil, err
 	}
 
-	defer os.RemoveAll(baseDirectory)
+	args := append([]string{
+		"governance", "drep", "retirement-certificate",
+		"--deposit-amt", strconv.FormatUint(depositAmount, 10),
+	}, drepArgs...)
 
-	certFilePath := filepath.Join(baseDirectory, "delegation.cert")
+	return cu.createCertificate("drep-retirement-cert", args)
+}
 
-	// On update to newer version this will fail because of the change:
-	// delegation-certificate -> stake-delegation-certificate
+// CreateVoteDelegationCertificate creates certificate which delegates voting power of the stake address
+// to the drep (or always abstain/no confidence) with cardano-cli
+func (cu CliUtils) CreateVoteDelegationCertificate(stakeAddress string, drep DRep) (*Certificate, error) {
 	args := []string{
-		cu.era, "stake-address", "delegation-certificate",
+		"stake-address", "vote-delegation-certificate",
 		"--stake-address", stakeAddress,
-		"--stake-pool-id", poolID,
-		"--out-file", certFilePath}
+	}
 
-	if _, err = runCommand(cu.cardanoCliBinary, args); err != nil {
-		args[2] = "stake-delegation-certificate"
+	switch drep.Type {
+	case DRepTypeKeyHash:
+		args = append(args, "--drep-key-hash", drep.Hash)
+	case DRepTypeScriptHash:
+		args = append(args, "--drep-script-hash", drep.Hash)
+	case DRepTypeAlwaysAbstain:
+		args = append(args, "--always-abstain")
+	case DRepTypeAlwaysNoConfidence:
+		args = append(args, "--always-no-confidence")
+	default:
+		return nil, fmt.Errorf("unsupported drep type: %d", drep.Type)
+	}
 
-		if _, err = runCommand(cu.cardanoCliBinary, args); err != nil {
-			return nil, fmt.Errorf("failed to delegate certificate: %w", err)
-		}
+	return cu.createCertificate("vote-delegation-cert", args)
+}
+
+// createCertificate runs cardano-cli command which creates certificate and reads created certificate
+func (cu CliUtils) createCertificate(name string, args []string) (*Certificate, error) {
+	baseDirectory, err := os.MkdirTemp("", name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(baseDirectory)
+
+	certFilePath := filepath.Join(baseDirectory, name)
+
+	_, err = runCommand(cu.cardanoCliBinary, append(append([]string{cu.era}, args...), "--out-file", certFilePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
 	}
 
 	bytes, err := os.ReadFile(certFilePath)
@@ -369,6 +431,8 @@ func (cu CliUtils) CreateDelegationCertificate(
 		return nil, fmt.Errorf("failed to read certificate: %w", err)
 	}
 
+	var cert *Certificate
+
 	if err := json.Unmarshal(bytes, &cert); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal certificate: %w", err)
 	}
@@ -376,6 +440,28 @@ func (cu CliUtils) CreateDelegationCertificate(
 	return cert, nil
 }
 
+func getDRepCredentialArgs(drep DRep) ([]string, error) {
+	switch drep.Type {
+	case DRepTypeKeyHash:
+		return []string{"--drep-key-hash", drep.Hash}, nil
+	case DRepTypeScriptHash:
+		return []string{"--drep-script-hash", drep.Hash}, nil
+	default:
+		return nil, fmt.Errorf("drep of type %d does not have credential", drep.Type)
+	}
+}
+
+func getAnchorArgs(prefix string, anchor *Anchor) []string {
+	if anchor == nil {
+		return nil
+	}
+
+	return []string{
+		fmt.Sprintf("--%s-url", prefix), anchor.URL,
+		fmt.Sprintf("--%s-hash", prefix), anchor.DataHash,
+	}
+}
+
 func getBech32Key(key []byte, prefix string) (string, error) {
 	converted, err := bech32.ConvertBits(key, 8, 5, true)
 	if err != nil {
@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"os/exec"
 	"strings"
 	"testing"
 
@@ -99,6 +100,72 @@ func TestDelegationCertificate(t *testing.T) {
 	require.Equal(t, "83028201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f581c5acc3f8fbc6ecfb86ce73543217a860387c4281bb394b4a123f35b24", stakeRegistrationCert.CborHex)
 }
 
+func TestVoteDelegationCertificate(t *testing.T) {
+	keyHashes := []string{
+		"30356731c6f4d92598732163a68d9dcec7c386075d5da4f1dca5724d",
+		"794eb34ded015c701fcf7b6ec4e0476e3dc2054a8831f636361680c9",
+		"8d2f93fdc4dbe32b1cb6951a441f081d2d111cb4a4c79a69f27d00a9",
+		"9f584550989f8a6cd6ce152b1c34661a764e0237200359e0f553d7db",
+	}
+
+	policyID, err := NewPolicyScript(keyHashes, 3).GetPolicyID()
+	require.NoError(t, err)
+
+	stakeAddress, err := NewPolicyScriptRewardAddress(MainNetNetwork, policyID)
+	require.NoError(t, err)
+
+	expectedCbors := map[DRep]string{
+		NewDRepAlwaysAbstain():      "83098201581c" + policyID + "8102",
+		NewDRepAlwaysNoConfidence(): "83098201581c" + policyID + "8103",
+		NewDRepScriptHash(policyID): "83098201581c" + policyID + "8201581c" + policyID,
+	}
+
+	for drep, expectedCbor := range expectedCbors {
+		cert, err := NewVoteDelegationCertificate(stakeAddress.String(), drep)
+		require.NoError(t, err)
+		require.Equal(t, expectedCbor, cert.CborHex)
+	}
+
+	// native certificates must be the same as the ones created with cardano-cli (if it is installed)
+	cliUtils := NewCliUtils(skipWithoutCardanoCli(t, ResolveCardanoCliBinary(MainNetNetwork)))
+
+	for drep, expectedCbor := range expectedCbors {
+		voteDelegationCert, err := cliUtils.CreateVoteDelegationCertificate(stakeAddress.String(), drep)
+		require.NoError(t, err)
+
+		require.True(t, strings.HasPrefix(voteDelegationCert.Type, "Certificate"))
+		require.Equal(t, expectedCbor, voteDelegationCert.CborHex)
+	}
+}
+
+func TestDRepCertificates(t *testing.T) {
+	const (
+		deposit = 500_000_000
+		keyHash = "30356731c6f4d92598732163a68d9dcec7c386075d5da4f1dca5724d"
+	)
+
+	drep := NewDRepKeyHash(keyHash)
+
+	registrationCert, err := NewDRepRegistrationCertificate(drep, deposit, nil)
+	require.NoError(t, err)
+	require.Equal(t, "84108200581c"+keyHash+"1a1dcd6500f6", registrationCert.CborHex)
+
+	retirementCert, err := NewDRepRetirementCertificate(drep, deposit)
+	require.NoError(t, err)
+	require.Equal(t, "83118200581c"+keyHash+"1a1dcd6500", retirementCert.CborHex)
+
+	// native certificates must be the same as the ones created with cardano-cli (if it is installed)
+	cliUtils := NewCliUtils(skipWithoutCardanoCli(t, ResolveCardanoCliBinary(MainNetNetwork)))
+
+	cliRegistrationCert, err := cliUtils.CreateDRepRegistrationCertificate(drep, deposit, nil)
+	require.NoError(t, err)
+	require.Equal(t, registrationCert.CborHex, cliRegistrationCert.CborHex)
+
+	cliRetirementCert, err := cliUtils.CreateDRepRetirementCertificate(drep, deposit)
+	require.NoError(t, err)
+	require.Equal(t, retirementCert.CborHex, cliRetirementCert.CborHex)
+}
+
 func TestGetRealEraName(t *testing.T) {
 	eraName, err := NewCliUtilsForEra(ResolveCardanoCliBinary(MainNetNetwork), "latest").GetRealEraName()
 
@@ -110,3 +177,14 @@ func TestGetRealEraName(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Conway", eraName)
 }
+
+// skipWithoutCardanoCli skips the rest of the test if the cardano-cli binary is not installed
+func skipWithoutCardanoCli(t *testing.T, binary string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath(binary); err != nil {
+		t.Skipf("%s is not installed: %v", binary, err)
+	}
+
+	return binary
+}
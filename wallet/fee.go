@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	// every output is extended by this number of bytes when calculating min utxo (input size and other overhead)
+	minUtxoConstantOverhead = 160
+
+	// reference scripts fee is tiered: every next refScriptsFeeSizeIncrement bytes are more expensive
+	refScriptsFeeSizeIncrement = 25_600
+	refScriptsFeeMultiplierNum = 6
+	refScriptsFeeMultiplierDen = 5
+
+	// sizes used by cardano-cli (cardano-api estimateTransactionFee) when estimating the fee
+	feeEstimationInputSize   = 1 + (2 + 32) + 5        // array + tx hash + index
+	feeEstimationOutputSize  = 1 + 5 + (2 + 1 + 2*28)  // array + amount + address
+	feeEstimationWitnessSize = 1 + (2 + 32) + (2 + 64) // array + vkey + signature
+	feeEstimationIsValidSize = 1                       // is valid flag is not part of the size computation
+)
+
+// CalculateLinearFee returns fee for transaction of the given size: txFeePerByte * size + txFeeFixed
+func CalculateLinearFee(protocolParameters ProtocolParameters, txSize uint64) uint64 {
+	return protocolParameters.TxFeePerByte*txSize + protocolParameters.TxFeeFixed
+}
+
+// CalculateRefScriptFee returns fee for the total size of reference scripts used by transaction.
+// Price per byte is multiplied by 1.2 for every next 25600 bytes
+func CalculateRefScriptFee(protocolParameters ProtocolParameters, refScriptsSize uint64) uint64 {
+	if protocolParameters.MinFeeRefScriptCostPerByte == nil || refScriptsSize == 0 {
+		return 0
+	}
+
+	var (
+		acc        = new(big.Rat)
+		tierPrice  = floatToRat(*protocolParameters.MinFeeRefScriptCostPerByte)
+		multiplier = big.NewRat(refScriptsFeeMultiplierNum, refScriptsFeeMultiplierDen)
+		increment  = new(big.Rat).SetUint64(refScriptsFeeSizeIncrement)
+	)
+
+	for refScriptsSize >= refScriptsFeeSizeIncrement {
+		acc.Add(acc, new(big.Rat).Mul(increment, tierPrice))
+		tierPrice.Mul(tierPrice, multiplier)
+
+		refScriptsSize -= refScriptsFeeSizeIncrement
+	}
+
+	acc.Add(acc, new(big.Rat).Mul(new(big.Rat).SetUint64(refScriptsSize), tierPrice))
+
+	return ratFloor(acc)
+}
+
+// CalculateExUnitsFee returns fee for the script execution units: ceil(priceMemory * memory + priceSteps * steps)
+func CalculateExUnitsFee(protocolParameters ProtocolParameters, exUnits ProtocolParametersMemorySteps) uint64 {
+	memory := new(big.Rat).Mul(
+		floatToRat(protocolParameters.ExecutionUnitPrices.PriceMemory), new(big.Rat).SetUint64(exUnits.Memory))
+	steps := new(big.Rat).Mul(
+		floatToRat(protocolParameters.ExecutionUnitPrices.PriceSteps), new(big.Rat).SetUint64(exUnits.Steps))
+	total := new(big.Rat).Add(memory, steps)
+
+	result := ratFloor(total)
+	if !total.IsInt() {
+		result++
+	}
+
+	return result
+}
+
+// CalculateMinCollateral returns min total collateral for the transaction fee: ceil(fee * collateralPercentage / 100)
+func CalculateMinCollateral(protocolParameters ProtocolParameters, fee uint64) uint64 {
+	return (fee*protocolParameters.CollateralPercentage + 99) / 100
+}
+
+// CalculateMinFee returns min fee for serialized unsigned transaction which will be signed with witnessCount keys.
+// The size of the transaction is estimated the same way as cardano-cli calculate-min-fee does.
+// Fee for execution units of all redeemers is included
+func CalculateMinFee(protocolParameters ProtocolParameters, txRaw []byte, witnessCount int) (uint64, error) {
+	var (
+		tx   txCbor
+		body struct {
+			Inputs  []cbor.RawMessage `cbor:"0,keyasint"`
+			Outputs []cbor.RawMessage `cbor:"1,keyasint"`
+		}
+		witnessSet struct {
+			Redeemers cbor.RawMessage `cbor:"5,keyasint,omitempty"`
+		}
+	)
+
+	if err := cbor.Unmarshal(txRaw, &tx); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+
+	if err := cbor.Unmarshal(tx.Body, &body); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal transaction body: %w", err)
+	}
+
+	if err := cbor.Unmarshal(tx.WitnessSet, &witnessSet); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal witness set: %w", err)
+	}
+
+	exUnits, err := getRedeemersExUnits(witnessSet.Redeemers)
+	if err != nil {
+		return 0, err
+	}
+
+	size := uint64(len(txRaw)) - feeEstimationIsValidSize +
+		uint64(len(body.Inputs))*feeEstimationInputSize +
+		uint64(len(body.Outputs))*feeEstimationOutputSize +
+		uint64(witnessCount)*feeEstimationWitnessSize //nolint:gosec
+
+	return CalculateLinearFee(protocolParameters, size) + CalculateExUnitsFee(protocolParameters, exUnits), nil
+}
+
+// CalculateMinUtxo returns min lovelace amount required for the output
+func CalculateMinUtxo(protocolParameters ProtocolParameters, output TxOutput) (uint64, error) {
+	// min utxo depends on the output size which depends on the amount itself
+	// so iterate until amount stops changing (the same as ledger does)
+	for {
+		outputCbor, err := newTxOutputCbor(output)
+		if err != nil {
+			return 0, err
+		}
+
+		minUtxo := (minUtxoConstantOverhead + uint64(len(outputCbor))) * protocolParameters.UtxoCostPerByte
+		if minUtxo == output.Amount {
+			return minUtxo, nil
+		}
+
+		output.Amount = minUtxo
+	}
+}
+
+// getRedeemersExUnits returns total execution units of redeemers in both array and map (conway) formats
+func getRedeemersExUnits(redeemersRaw cbor.RawMessage) (ProtocolParametersMemorySteps, error) {
+	var (
+		result    ProtocolParametersMemorySteps
+		redeemers []txRedeemerCbor
+	)
+
+	if len(redeemersRaw) == 0 {
+		return result, nil
+	}
+
+	if err := cbor.Unmarshal(redeemersRaw, &redeemers); err == nil {
+		for _, x := range redeemers {
+			result.Memory += x.ExUnits.Memory
+			result.Steps += x.ExUnits.Steps
+		}
+
+		return result, nil
+	}
+
+	var redeemersMap map[[2]uint64]struct {
+		_       struct{} `cbor:",toarray"`
+		Data    cbor.RawMessage
+		ExUnits txExUnitsCbor
+	}
+
+	if err := cbor.Unmarshal(redeemersRaw, &redeemersMap); err != nil {
+		return result, fmt.Errorf("failed to unmarshal redeemers: %w", err)
+	}
+
+	for _, x := range redeemersMap {
+		result.Memory += x.ExUnits.Memory
+		result.Steps += x.ExUnits.Steps
+	}
+
+	return result, nil
+}
+
+// floatToRat converts float to rational number using its shortest decimal representation (0.0577 = 577/10000)
+func floatToRat(value float64) *big.Rat {
+	result, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if !ok {
+		return new(big.Rat).SetFloat64(value)
+	}
+
+	return result
+}
+
+func ratFloor(value *big.Rat) uint64 {
+	return new(big.Int).Quo(value.Num(), value.Denom()).Uint64()
+}
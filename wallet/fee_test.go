@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateFees(t *testing.T) {
+	t.Parallel()
+
+	var pp ProtocolParameters
+
+	require.NoError(t, json.Unmarshal(protocolParameters, &pp))
+
+	t.Run("linear fee", func(t *testing.T) {
+		require.Equal(t, uint64(155381), CalculateLinearFee(pp, 0))
+		require.Equal(t, uint64(155381+44*1000), CalculateLinearFee(pp, 1000))
+	})
+
+	t.Run("ref script fee", func(t *testing.T) {
+		require.Equal(t, uint64(0), CalculateRefScriptFee(pp, 0))
+		require.Equal(t, uint64(15*1000), CalculateRefScriptFee(pp, 1000))
+		require.Equal(t, uint64(15*25_600), CalculateRefScriptFee(pp, 25_600))
+		require.Equal(t, uint64(15*25_600+18*4_400), CalculateRefScriptFee(pp, 30_000))
+		// 15 * 25600 + 18 * 25600 + 21.6 * 10
+		require.Equal(t, uint64(384_000+460_800+216), CalculateRefScriptFee(pp, 51_210))
+
+		ppWithoutRefScriptCost := pp
+		ppWithoutRefScriptCost.MinFeeRefScriptCostPerByte = nil
+
+		require.Equal(t, uint64(0), CalculateRefScriptFee(ppWithoutRefScriptCost, 1000))
+	})
+
+	t.Run("ex units fee", func(t *testing.T) {
+		require.Equal(t, uint64(0), CalculateExUnitsFee(pp, ProtocolParametersMemorySteps{}))
+		require.Equal(t, uint64(57_700+36_050), CalculateExUnitsFee(pp, NewProtocolParametersMemorySteps(1_000_000, 500_000_000)))
+		// 0.0577 * 1 + 0.0000721 * 1 rounded up
+		require.Equal(t, uint64(1), CalculateExUnitsFee(pp, NewProtocolParametersMemorySteps(1, 1)))
+	})
+
+	t.Run("min collateral", func(t *testing.T) {
+		require.Equal(t, uint64(0), CalculateMinCollateral(pp, 0))
+		require.Equal(t, uint64(300_000), CalculateMinCollateral(pp, 200_000))
+		require.Equal(t, uint64(2), CalculateMinCollateral(pp, 1))
+	})
+
+	t.Run("min fee", func(t *testing.T) {
+		// body with fee 0 (registration certificate test), 1 input, 1 output and 8 witnesses
+		txRaw, err := hex.DecodeString("84a50081825820bb88a2541d545044e400d37c3db3eeb7a452fd9f2c461c89451f7191cc4f4079000181825839301ab8db33cbfe7f75036e213f48500a4723c6f48311beb8e39884ceeab59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f00020003192" +
+			"3fb048182008201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15fa10181830303848200581c0fb340e2fc18865fbf406dce76f743de13c46d2eb91d6e87e6eb63c68200581c41b46f772b622e7e5bc8970d128faccb7a457c610a48d514801a04118200581c5282885af1f234cb9407f05b120f2eb06872f297864ca9066a6570118200581c6a2f73455484b658c168c18ed54222d189e7e746ec3dc2d8d8891e42f5f6")
+		require.NoError(t, err)
+
+		fee, err := CalculateMinFee(pp, txRaw, 8)
+		require.NoError(t, err)
+		require.Equal(t, uint64(207917), fee)
+
+		_, err = CalculateMinFee(pp, []byte{0x1, 0x2}, 1)
+		require.Error(t, err)
+	})
+
+	t.Run("min utxo", func(t *testing.T) {
+		token, err := NewTokenWithFullName("29f8873beb52e126f207a2dfd50f7cff556806b5b4cba9834a7b26a8.Route3", false)
+		require.NoError(t, err)
+
+		output := NewTxOutput("addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u", 0)
+
+		minUtxo, err := CalculateMinUtxo(pp, output)
+		require.NoError(t, err)
+		require.Equal(t, uint64(849070), minUtxo)
+
+		output.Tokens = []TokenAmount{NewTokenAmount(token, 3)}
+
+		minUtxo, err = CalculateMinUtxo(pp, output)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1_025_780), minUtxo)
+
+		_, err = CalculateMinUtxo(pp, NewTxOutput("invalid", 0))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,342 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	conwayCertificateType = "CertificateConway"
+
+	// conway certificate tags as defined in cddl
+	voteDelegationCertificateTag   = 9
+	drepRegistrationCertificateTag = 16
+	drepRetirementCertificateTag   = 17
+	drepUpdateCertificateTag       = 18
+
+	credentialTypeKeyHash    = 0
+	credentialTypeScriptHash = 1
+)
+
+type DRepType byte
+
+const (
+	DRepTypeKeyHash DRepType = iota
+	DRepTypeScriptHash
+	DRepTypeAlwaysAbstain
+	DRepTypeAlwaysNoConfidence
+)
+
+// DRep is delegated representative to which voting power is delegated
+type DRep struct {
+	Type DRepType
+	// Hash is hex encoded key hash or script hash (empty for always abstain and always no confidence)
+	Hash string
+}
+
+func NewDRepKeyHash(keyHash string) DRep {
+	return DRep{Type: DRepTypeKeyHash, Hash: keyHash}
+}
+
+// NewDRepScriptHash creates script DRep. For multisig DRep hash is policy id of the policy script
+func NewDRepScriptHash(scriptHash string) DRep {
+	return DRep{Type: DRepTypeScriptHash, Hash: scriptHash}
+}
+
+func NewDRepAlwaysAbstain() DRep {
+	return DRep{Type: DRepTypeAlwaysAbstain}
+}
+
+func NewDRepAlwaysNoConfidence() DRep {
+	return DRep{Type: DRepTypeAlwaysNoConfidence}
+}
+
+// toCborValue returns drep as defined in cddl: [0, keyhash] / [1, scripthash] / [2] / [3]
+func (d DRep) toCborValue() ([]any, error) {
+	switch d.Type {
+	case DRepTypeKeyHash, DRepTypeScriptHash:
+		hash, err := decodeHash(d.Hash, KeyHashSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drep hash %s: %w", d.Hash, err)
+		}
+
+		return []any{uint64(d.Type), hash}, nil
+	case DRepTypeAlwaysAbstain, DRepTypeAlwaysNoConfidence:
+		return []any{uint64(d.Type)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported drep type: %d", d.Type)
+	}
+}
+
+// toCredentialCborValue returns drep credential: [0, keyhash] / [1, scripthash]
+func (d DRep) toCredentialCborValue() ([]any, error) {
+	if d.Type != DRepTypeKeyHash && d.Type != DRepTypeScriptHash {
+		return nil, fmt.Errorf("drep of type %d does not have credential", d.Type)
+	}
+
+	return d.toCborValue()
+}
+
+// Anchor is url and hash of the off-chain metadata
+type Anchor struct {
+	URL string
+	// DataHash is hex encoded blake2b-256 hash of the metadata
+	DataHash string
+}
+
+func NewAnchor(url string, dataHash string) *Anchor {
+	return &Anchor{
+		URL:      url,
+		DataHash: dataHash,
+	}
+}
+
+func (a *Anchor) toCborValue() (any, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	dataHash, err := decodeHash(a.DataHash, blake2b256HashSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid anchor data hash %s: %w", a.DataHash, err)
+	}
+
+	return []any{a.URL, dataHash}, nil
+}
+
+// NewDRepRegistrationCertificate creates certificate which registers key or script drep
+func NewDRepRegistrationCertificate(drep DRep, deposit uint64, anchor *Anchor) (*Certificate, error) {
+	credential, err := drep.toCredentialCborValue()
+	if err != nil {
+		return nil, err
+	}
+
+	anchorValue, err := anchor.toCborValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConwayCertificate(
+		"DRep Key Registration Certificate",
+		[]any{drepRegistrationCertificateTag, credential, deposit, anchorValue})
+}
+
+// NewDRepUpdateCertificate creates certificate which updates metadata anchor of the drep
+func NewDRepUpdateCertificate(drep DRep, anchor *Anchor) (*Certificate, error) {
+	credential, err := drep.toCredentialCborValue()
+	if err != nil {
+		return nil, err
+	}
+
+	anchorValue, err := anchor.toCborValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConwayCertificate(
+		"DRep Update Certificate",
+		[]any{drepUpdateCertificateTag, credential, anchorValue})
+}
+
+// NewDRepRetirementCertificate creates certificate which retires drep. Deposit must be the same as registration one
+func NewDRepRetirementCertificate(drep DRep, deposit uint64) (*Certificate, error) {
+	credential, err := drep.toCredentialCborValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConwayCertificate(
+		"DRep Retirement Certificate",
+		[]any{drepRetirementCertificateTag, credential, deposit})
+}
+
+// NewVoteDelegationCertificate creates certificate which delegates voting power of the stake address to the drep
+func NewVoteDelegationCertificate(stakeAddress string, drep DRep) (*Certificate, error) {
+	addr, err := NewCardanoAddressFromString(stakeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stake address %s: %w", stakeAddress, err)
+	}
+
+	stake := addr.GetInfo().Stake
+	if stake == nil {
+		return nil, fmt.Errorf("address %s does not have stake credential", stakeAddress)
+	}
+
+	credentialType := credentialTypeKeyHash
+	if stake.IsScript {
+		credentialType = credentialTypeScriptHash
+	}
+
+	drepValue, err := drep.toCborValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConwayCertificate(
+		"Vote Delegation Certificate",
+		[]any{voteDelegationCertificateTag, []any{credentialType, stake.Payload[:]}, drepValue})
+}
+
+func newConwayCertificate(description string, value []any) (*Certificate, error) {
+	bytes, err := cborEncMode.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certificate{
+		Type:        conwayCertificateType,
+		Description: description,
+		CborHex:     hex.EncodeToString(bytes),
+	}, nil
+}
+
+type VoterType byte
+
+const (
+	VoterTypeCommitteeHotKeyHash VoterType = iota
+	VoterTypeCommitteeHotScriptHash
+	VoterTypeDRepKeyHash
+	VoterTypeDRepScriptHash
+	VoterTypeStakePoolKeyHash
+)
+
+type Voter struct {
+	Type VoterType
+	// Hash is hex encoded key hash or script hash of the voter
+	Hash string
+}
+
+func NewVoter(voterType VoterType, hash string) Voter {
+	return Voter{
+		Type: voterType,
+		Hash: hash,
+	}
+}
+
+type VoteChoice byte
+
+const (
+	VoteNo VoteChoice = iota
+	VoteYes
+	VoteAbstain
+)
+
+// GovActionID is id of the governance action: transaction which submitted proposal and index of the proposal
+type GovActionID struct {
+	TxHash string
+	Index  uint16
+}
+
+// Vote is a voting procedure for the governance action
+type Vote struct {
+	Voter       Voter
+	GovActionID GovActionID
+	Choice      VoteChoice
+	Anchor      *Anchor
+}
+
+func NewVote(voter Voter, govActionID GovActionID, choice VoteChoice, anchor *Anchor) Vote {
+	return Vote{
+		Voter:       voter,
+		GovActionID: govActionID,
+		Choice:      choice,
+		Anchor:      anchor,
+	}
+}
+
+// newVotingProceduresCbor returns voting procedures map: { voter => { gov_action_id => [vote, anchor / null] } }
+func newVotingProceduresCbor(votes []Vote) (cbor.RawMessage, error) {
+	if len(votes) == 0 {
+		return nil, nil
+	}
+
+	// keys of the maps are arrays so maps are sorted by their encoded keys (the same as core deterministic encoding)
+	voters := map[string][]rawKeyMapEntryCbor{}
+
+	for _, vote := range votes {
+		if vote.Voter.Type > VoterTypeStakePoolKeyHash {
+			return nil, fmt.Errorf("unsupported voter type: %d", vote.Voter.Type)
+		}
+
+		voterHash, err := decodeHash(vote.Voter.Hash, KeyHashSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid voter hash %s: %w", vote.Voter.Hash, err)
+		}
+
+		voterKey, err := cborEncMode.Marshal([]any{uint64(vote.Voter.Type), voterHash})
+		if err != nil {
+			return nil, err
+		}
+
+		txHash, err := decodeHash(vote.GovActionID.TxHash, blake2b256HashSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid governance action tx hash %s: %w", vote.GovActionID.TxHash, err)
+		}
+
+		govActionKey, err := cborEncMode.Marshal([]any{txHash, vote.GovActionID.Index})
+		if err != nil {
+			return nil, err
+		}
+
+		anchorValue, err := vote.Anchor.toCborValue()
+		if err != nil {
+			return nil, err
+		}
+
+		voters[string(voterKey)] = append(voters[string(voterKey)], rawKeyMapEntryCbor{
+			key:   govActionKey,
+			value: []any{uint64(vote.Choice), anchorValue},
+		})
+	}
+
+	result := make([]rawKeyMapEntryCbor, 0, len(voters))
+
+	for voterKey, procedures := range voters {
+		result = append(result, rawKeyMapEntryCbor{
+			key:   []byte(voterKey),
+			value: newSortedMapCbor(procedures),
+		})
+	}
+
+	return cborEncMode.Marshal(newSortedMapCbor(result))
+}
+
+type rawKeyMapEntryCbor struct {
+	key   []byte
+	value any
+}
+
+// newSortedMapCbor returns map with encoded keys sorted the same way as core deterministic encoding does
+func newSortedMapCbor(entries []rawKeyMapEntryCbor) txMetadataMapCbor {
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	result := make(txMetadataMapCbor, len(entries))
+	for i, entry := range entries {
+		result[i] = txMetadataMapEntryCbor{
+			Key:   cbor.RawMessage(entry.key),
+			Value: entry.value,
+		}
+	}
+
+	return result
+}
+
+func decodeHash(value string, size int) ([]byte, error) {
+	bytes, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes) != size {
+		return nil, errors.New("invalid hash size")
+	}
+
+	return bytes, nil
+}
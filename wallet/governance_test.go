@@ -0,0 +1,189 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGovernanceCertificates(t *testing.T) {
+	t.Parallel()
+
+	const (
+		keyHash  = "244877c1aeefc7fd5405a6e14d927d91758d45e37c20fa2ac89cb167"
+		dataHash = "cb1b53bb62ee65e8ae893d04331dcc70d745298a32fcedf5ff9cc7a12d8471e3"
+		deposit  = 500_000_000
+	)
+
+	policyScript := NewPolicyScript([]string{
+		"d6b67f93ffa4e2651271cc9bcdbdedb2539911266b534d9c163cba21",
+		"cba89c7084bf0ce4bf404346b668a7e83c8c9c250d1cafd8d8996e41",
+	}, 2)
+
+	policyID, err := policyScript.GetPolicyID()
+	require.NoError(t, err)
+
+	stakeAddress, err := NewPolicyScriptRewardAddress(TestNetNetwork, policyID)
+	require.NoError(t, err)
+
+	t.Run("drep registration", func(t *testing.T) {
+		cert, err := NewDRepRegistrationCertificate(NewDRepKeyHash(keyHash), deposit, nil)
+		require.NoError(t, err)
+		require.Equal(t, conwayCertificateType, cert.Type)
+		require.Equal(t, "84108200581c"+keyHash+"1a1dcd6500f6", cert.CborHex)
+
+		cert, err = NewDRepRegistrationCertificate(
+			NewDRepScriptHash(policyID), deposit, NewAnchor("https://a.io", dataHash))
+		require.NoError(t, err)
+		require.Equal(t, "84108201581c"+policyID+"1a1dcd6500826c68747470733a2f2f612e696f5820"+dataHash, cert.CborHex)
+
+		_, err = NewDRepRegistrationCertificate(NewDRepAlwaysAbstain(), deposit, nil)
+		require.Error(t, err)
+
+		_, err = NewDRepRegistrationCertificate(NewDRepKeyHash(keyHash), deposit, NewAnchor("https://a.io", "ff"))
+		require.Error(t, err)
+	})
+
+	t.Run("drep update and retirement", func(t *testing.T) {
+		cert, err := NewDRepUpdateCertificate(NewDRepKeyHash(keyHash), nil)
+		require.NoError(t, err)
+		require.Equal(t, "83128200581c"+keyHash+"f6", cert.CborHex)
+
+		cert, err = NewDRepRetirementCertificate(NewDRepKeyHash(keyHash), deposit)
+		require.NoError(t, err)
+		require.Equal(t, "83118200581c"+keyHash+"1a1dcd6500", cert.CborHex)
+
+		_, err = NewDRepRetirementCertificate(NewDRepKeyHash("ff"), deposit)
+		require.Error(t, err)
+	})
+
+	t.Run("vote delegation", func(t *testing.T) {
+		cert, err := NewVoteDelegationCertificate(stakeAddress.String(), NewDRepAlwaysAbstain())
+		require.NoError(t, err)
+		require.Equal(t, "83098201581c"+policyID+"8102", cert.CborHex)
+
+		cert, err = NewVoteDelegationCertificate(stakeAddress.String(), NewDRepAlwaysNoConfidence())
+		require.NoError(t, err)
+		require.Equal(t, "83098201581c"+policyID+"8103", cert.CborHex)
+
+		cert, err = NewVoteDelegationCertificate(stakeAddress.String(), NewDRepKeyHash(keyHash))
+		require.NoError(t, err)
+		require.Equal(t, "83098201581c"+policyID+"8200581c"+keyHash, cert.CborHex)
+
+		isWitnessRequired, err := txCertificateWithPolicyScript{certificate: cert}.IsWitnessRequired()
+		require.NoError(t, err)
+		require.True(t, isWitnessRequired)
+
+		_, err = NewVoteDelegationCertificate(
+			"addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u", NewDRepAlwaysAbstain())
+		require.Error(t, err)
+
+		_, err = NewVoteDelegationCertificate(stakeAddress.String(), DRep{Type: 10})
+		require.Error(t, err)
+	})
+}
+
+func TestNewVotingProceduresCbor(t *testing.T) {
+	t.Parallel()
+
+	const (
+		drepHash = "244877c1aeefc7fd5405a6e14d927d91758d45e37c20fa2ac89cb167"
+		poolHash = "d6b67f93ffa4e2651271cc9bcdbdedb2539911266b534d9c163cba21"
+		txHash1  = "1f55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb"
+		txHash2  = "ff55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb"
+	)
+
+	value, err := newVotingProceduresCbor(nil)
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	value, err = newVotingProceduresCbor([]Vote{
+		NewVote(NewVoter(VoterTypeStakePoolKeyHash, poolHash), GovActionID{TxHash: txHash1}, VoteAbstain, nil),
+		NewVote(NewVoter(VoterTypeDRepScriptHash, drepHash), GovActionID{TxHash: txHash2, Index: 1}, VoteNo, nil),
+		NewVote(NewVoter(VoterTypeDRepScriptHash, drepHash), GovActionID{TxHash: txHash1, Index: 2}, VoteYes, nil),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "a2"+
+		"8203581c"+drepHash+"a2"+
+		"825820"+txHash1+"02"+"8201f6"+
+		"825820"+txHash2+"01"+"8200f6"+
+		"8204581c"+poolHash+"a1"+
+		"825820"+txHash1+"00"+"8202f6", hex.EncodeToString(value))
+
+	_, err = newVotingProceduresCbor([]Vote{
+		NewVote(NewVoter(VoterTypeDRepKeyHash, "ff"), GovActionID{TxHash: txHash1}, VoteYes, nil),
+	})
+	require.Error(t, err)
+
+	_, err = newVotingProceduresCbor([]Vote{
+		NewVote(NewVoter(VoterTypeDRepKeyHash, drepHash), GovActionID{TxHash: "ff"}, VoteYes, nil),
+	})
+	require.Error(t, err)
+}
+
+func TestTxBuilderNative_Votes(t *testing.T) {
+	t.Parallel()
+
+	const (
+		address = "addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u"
+		txHash  = "1f55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb"
+	)
+
+	var pp ProtocolParameters
+
+	require.NoError(t, json.Unmarshal(protocolParameters, &pp))
+
+	policyScript := NewPolicyScript([]string{
+		"d6b67f93ffa4e2651271cc9bcdbdedb2539911266b534d9c163cba21",
+		"cba89c7084bf0ce4bf404346b668a7e83c8c9c250d1cafd8d8996e41",
+		"79df3577e4c7d7da04872c2182b8d8829d7b477912dbf35d89287c39",
+	}, 2)
+
+	policyID, err := policyScript.GetPolicyID()
+	require.NoError(t, err)
+
+	voter := NewVoter(VoterTypeDRepScriptHash, policyID)
+
+	txBuilder := NewTxBuilderNative()
+	defer txBuilder.Dispose()
+
+	txBuilder.SetProtocolParameters(protocolParameters).SetTimeToLive(1000).
+		AddInputs(NewTxInput(txHash, 0)).
+		AddOutputs(NewTxOutput(address, 2_000_000)).
+		AddVotes(policyScript,
+			NewVote(voter, GovActionID{TxHash: txHash, Index: 0}, VoteYes, nil),
+			NewVote(voter, GovActionID{TxHash: txHash, Index: 1}, VoteNo, nil))
+
+	// one for input and one for each key of the voter policy script (voter is counted once)
+	require.Equal(t, 4, txBuilder.getWitnessCount())
+
+	txRaw, _, err := txBuilder.SetFee(200_000).Build()
+	require.NoError(t, err)
+
+	var (
+		tx   txCbor
+		body struct {
+			VotingProcedures cbor.RawMessage `cbor:"19,keyasint"`
+		}
+		witnessSet txWitnessSetCbor
+	)
+
+	require.NoError(t, cbor.Unmarshal(txRaw, &tx))
+	require.NoError(t, cbor.Unmarshal(tx.Body, &body))
+	require.NoError(t, cbor.Unmarshal(tx.WitnessSet, &witnessSet))
+
+	expectedVotingProcedures, err := newVotingProceduresCbor([]Vote{
+		NewVote(voter, GovActionID{TxHash: txHash, Index: 0}, VoteYes, nil),
+		NewVote(voter, GovActionID{TxHash: txHash, Index: 1}, VoteNo, nil),
+	})
+	require.NoError(t, err)
+
+	scriptCbor, err := policyScript.GetBytesCBOR()
+	require.NoError(t, err)
+
+	require.Equal(t, expectedVotingProcedures, body.VotingProcedures)
+	require.Equal(t, []cbor.RawMessage{scriptCbor}, witnessSet.NativeScripts)
+}
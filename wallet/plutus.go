@@ -0,0 +1,264 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	PlutusScriptV2Type = "PlutusScriptV2"
+	PlutusScriptV3Type = "PlutusScriptV3"
+
+	PlutusV2CostModelName = "PlutusV2"
+	PlutusV3CostModelName = "PlutusV3"
+)
+
+// redeemer tags as defined in cddl
+const (
+	redeemerTagSpend = iota
+	redeemerTagMint
+)
+
+// plutus language ids as defined in cddl (used in script hashes and script data hash)
+const (
+	plutusLanguageV2 = 1
+	plutusLanguageV3 = 2
+)
+
+var plutusCostModelNames = map[uint64]string{
+	plutusLanguageV2: PlutusV2CostModelName,
+	plutusLanguageV3: PlutusV3CostModelName,
+}
+
+// PlutusScript is plutus script in the cardano-cli text envelope format
+type PlutusScript struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	CborHex     string `json:"cborHex"`
+}
+
+func NewPlutusScript(scriptType string, scriptCbor []byte) *PlutusScript {
+	return &PlutusScript{
+		Type:    scriptType,
+		CborHex: hex.EncodeToString(scriptCbor),
+	}
+}
+
+// GetBytesJSON returns plutus script as JSON byte array.
+func (ps PlutusScript) GetBytesJSON() ([]byte, error) {
+	return json.MarshalIndent(ps, "", "  ")
+}
+
+// GetBytes returns script bytes as they are stored in the transaction witness set
+// (content of the cbor byte string from the text envelope)
+func (ps PlutusScript) GetBytes() ([]byte, error) {
+	envelopeBytes, err := hex.DecodeString(ps.CborHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plutus script cbor: %w", err)
+	}
+
+	var scriptBytes []byte
+
+	if err := cbor.Unmarshal(envelopeBytes, &scriptBytes); err != nil {
+		return nil, fmt.Errorf("invalid plutus script cbor: %w", err)
+	}
+
+	return scriptBytes, nil
+}
+
+// GetLanguage returns plutus language id (1 for PlutusV2, 2 for PlutusV3)
+func (ps PlutusScript) GetLanguage() (uint64, error) {
+	switch ps.Type {
+	case PlutusScriptV2Type:
+		return plutusLanguageV2, nil
+	case PlutusScriptV3Type:
+		return plutusLanguageV3, nil
+	default:
+		return 0, fmt.Errorf("unsupported plutus script type: %s", ps.Type)
+	}
+}
+
+// GetScriptHash returns hex encoded hash of the script (policy id for minting scripts)
+func (ps PlutusScript) GetScriptHash() (string, error) {
+	hash, err := ps.getScriptHashBytes()
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash), nil
+}
+
+func (ps PlutusScript) getScriptHashBytes() ([]byte, error) {
+	language, err := ps.GetLanguage()
+	if err != nil {
+		return nil, err
+	}
+
+	scriptBytes, err := ps.GetBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// script prefix is language id + 1 (native scripts have prefix 0)
+	return GetKeyHashBytes(append([]byte{byte(language + 1)}, scriptBytes...))
+}
+
+// PlutusScriptWitness contains everything needed to execute plutus script for an input or a mint
+type PlutusScriptWitness struct {
+	Script *PlutusScript
+	// Datum is cbor of the datum. Should be nil if the datum is inlined in the utxo (or for minting)
+	Datum []byte
+	// Redeemer is cbor of the redeemer
+	Redeemer []byte
+	ExUnits  ProtocolParametersMemorySteps
+	// ReferenceInput is set if the script is not included in the transaction but taken from the output
+	// of this input (Script is still required for the hash and the language)
+	ReferenceInput *TxInput
+}
+
+func NewPlutusScriptWitness(
+	script *PlutusScript, datum []byte, redeemer []byte, exUnits ProtocolParametersMemorySteps,
+) PlutusScriptWitness {
+	return PlutusScriptWitness{
+		Script:   script,
+		Datum:    datum,
+		Redeemer: redeemer,
+		ExUnits:  exUnits,
+	}
+}
+
+// Apply appends cardano-cli arguments for the script witness of the input or the mint
+func (w PlutusScriptWitness) Apply(args *[]string, basePath, filePrefix string, isMint bool) error {
+	if w.Script == nil {
+		return fmt.Errorf("plutus script not set for %s", filePrefix)
+	}
+
+	// reference script flags have different prefixes than the ones for script file
+	var flagPrefix, dataFlagPrefix string
+
+	switch {
+	case isMint && w.ReferenceInput != nil:
+		flagPrefix, dataFlagPrefix = "mint", "mint-reference-tx-in"
+	case isMint:
+		flagPrefix, dataFlagPrefix = "mint", "mint"
+	case w.ReferenceInput != nil:
+		flagPrefix, dataFlagPrefix = "spending", "spending-reference-tx-in"
+	default:
+		flagPrefix, dataFlagPrefix = "tx-in", "tx-in"
+	}
+
+	if w.ReferenceInput != nil {
+		language, err := w.Script.GetLanguage()
+		if err != nil {
+			return err
+		}
+
+		*args = append(*args,
+			fmt.Sprintf("--%s-tx-in-reference", flagPrefix), w.ReferenceInput.String(),
+			fmt.Sprintf("--%s-plutus-script-v%d", flagPrefix, language+1))
+
+		if isMint {
+			policyID, err := w.Script.GetScriptHash()
+			if err != nil {
+				return err
+			}
+
+			*args = append(*args, "--policy-id", policyID)
+		}
+	} else {
+		scriptFilePath, err := writeSerializableToFile(w.Script, basePath, fmt.Sprintf("plutus_%s.json", filePrefix))
+		if err != nil {
+			return err
+		}
+
+		*args = append(*args, fmt.Sprintf("--%s-script-file", flagPrefix), scriptFilePath)
+	}
+
+	if !isMint {
+		if w.Datum != nil {
+			datumFilePath := filepath.Join(basePath, fmt.Sprintf("datum_%s.cbor", filePrefix))
+			if err := os.WriteFile(datumFilePath, w.Datum, FilePermission); err != nil {
+				return err
+			}
+
+			*args = append(*args, fmt.Sprintf("--%s-datum-cbor-file", dataFlagPrefix), datumFilePath)
+		} else {
+			*args = append(*args, fmt.Sprintf("--%s-inline-datum-present", dataFlagPrefix))
+		}
+	}
+
+	redeemerFilePath := filepath.Join(basePath, fmt.Sprintf("redeemer_%s.cbor", filePrefix))
+	if err := os.WriteFile(redeemerFilePath, w.Redeemer, FilePermission); err != nil {
+		return err
+	}
+
+	*args = append(*args,
+		fmt.Sprintf("--%s-redeemer-cbor-file", dataFlagPrefix), redeemerFilePath,
+		fmt.Sprintf("--%s-execution-units", dataFlagPrefix), fmt.Sprintf("(%d, %d)", w.ExUnits.Steps, w.ExUnits.Memory))
+
+	return nil
+}
+
+// getScriptSize returns size of the script bytes (used for reference scripts fee)
+func (w PlutusScriptWitness) getScriptSize() (uint64, error) {
+	if w.Script == nil {
+		return 0, errors.New("plutus script not set")
+	}
+
+	scriptBytes, err := w.Script.GetBytes()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(len(scriptBytes)), nil
+}
+
+// GetDatumHash returns hex encoded blake2b-256 hash of the datum cbor
+func GetDatumHash(datum []byte) string {
+	return hex.EncodeToString(getBlake2b256Hash(datum))
+}
+
+// getScriptDataHash returns hash of redeemers, datums and cost models of used plutus languages
+func getScriptDataHash(
+	protocolParameters ProtocolParameters, redeemers []txRedeemerCbor, datums []cbor.RawMessage, languages []uint64,
+) ([]byte, error) {
+	redeemersRaw, err := cborEncMode.Marshal(redeemers)
+	if err != nil {
+		return nil, err
+	}
+
+	languageViews := make(map[uint64][]int64, len(languages))
+
+	for _, language := range languages {
+		costModel := protocolParameters.CostModels[plutusCostModelNames[language]]
+		if len(costModel) == 0 {
+			return nil, fmt.Errorf("cost model for plutus language %d not found in protocol parameters", language)
+		}
+
+		languageViews[language] = costModel
+	}
+
+	languageViewsRaw, err := cborEncMode.Marshal(languageViews)
+	if err != nil {
+		return nil, err
+	}
+
+	data := redeemersRaw
+
+	if len(datums) > 0 {
+		datumsRaw, err := cborEncMode.Marshal(datums)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, datumsRaw...)
+	}
+
+	return getBlake2b256Hash(append(data, languageViewsRaw...)), nil
+}
@@ -0,0 +1,220 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlutusScript(t *testing.T) {
+	t.Parallel()
+
+	// always succeeds script
+	scriptCbor, err := hex.DecodeString("4e4d01000033222220051200120011")
+	require.NoError(t, err)
+
+	script := NewPlutusScript(PlutusScriptV2Type, scriptCbor)
+
+	scriptBytes, err := script.GetBytes()
+	require.NoError(t, err)
+	require.Equal(t, "4d01000033222220051200120011", hex.EncodeToString(scriptBytes))
+
+	expectedHash, err := GetKeyHashBytes(append([]byte{2}, scriptBytes...))
+	require.NoError(t, err)
+
+	hash, err := script.GetScriptHash()
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(expectedHash), hash)
+
+	_, err = NewPlutusScript("PlutusScriptV1", scriptCbor).GetScriptHash()
+	require.Error(t, err)
+
+	_, err = PlutusScript{Type: PlutusScriptV3Type, CborHex: "zz"}.GetScriptHash()
+	require.Error(t, err)
+}
+
+func TestTxBuilderNative_Plutus(t *testing.T) {
+	t.Parallel()
+
+	const (
+		address       = "addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u"
+		requiredKey   = "d6b67f93ffa4e2651271cc9bcdbdedb2539911266b534d9c163cba21"
+		walletInpHash = "ff55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb"
+		scriptInpHash = "1f55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb"
+	)
+
+	var pp ProtocolParameters
+
+	require.NoError(t, json.Unmarshal(protocolParameters, &pp))
+
+	scriptCbor, err := hex.DecodeString("4e4d01000033222220051200120011")
+	require.NoError(t, err)
+
+	script := NewPlutusScript(PlutusScriptV2Type, scriptCbor)
+
+	policyID, err := script.GetScriptHash()
+	require.NoError(t, err)
+
+	datum := []byte{0x18, 0x2a}          // 42
+	redeemer := []byte{0xd8, 0x79, 0x80} // constr 0 []
+	exUnits := NewProtocolParametersMemorySteps(1_000_000, 500_000_000)
+	spendWitness := NewPlutusScriptWitness(script, datum, redeemer, exUnits)
+	mintWitness := NewPlutusScriptWitness(script, nil, redeemer, exUnits)
+	token := NewTokenAmount(NewToken(policyID, "Route3"), 10)
+
+	txBuilder := NewTxBuilderNative()
+	defer txBuilder.Dispose()
+
+	txBuilder.SetProtocolParameters(protocolParameters).SetTimeToLive(1000).
+		AddInputs(TxInput{Hash: walletInpHash, Index: 0}).
+		AddPlutusInputs(spendWitness, TxInput{Hash: scriptInpHash, Index: 2}).
+		AddCollateralInputs(TxInput{Hash: walletInpHash, Index: 1}).
+		SetCollateralReturn(NewTxOutput(address, 4_000_000), 1_000_000).
+		AddRequiredSigners(requiredKey).
+		AddPlutusTokenMints(mintWitness, []TokenAmount{token}).
+		AddOutputs(
+			TxOutput{Addr: address, Amount: 2_000_000, InlineDatum: datum},
+			TxOutput{Addr: address, Amount: 3_000_000, DatumHash: GetDatumHash(datum), Tokens: []TokenAmount{token}},
+		)
+
+	fee, err := txBuilder.CalculateFee(0)
+	require.NoError(t, err)
+
+	txRawWithoutFee, _, err := txBuilder.buildRawTxNative(0)
+	require.NoError(t, err)
+
+	witnessCount := txBuilder.getWitnessCount()
+
+	linearFee := CalculateLinearFee(pp, uint64(len(txRawWithoutFee))-feeEstimationIsValidSize+
+		2*feeEstimationInputSize+2*feeEstimationOutputSize+uint64(witnessCount)*feeEstimationWitnessSize) //nolint:gosec
+	require.Equal(t, linearFee+CalculateExUnitsFee(pp, NewProtocolParametersMemorySteps(2_000_000, 1_000_000_000)), fee)
+	require.Equal(t, 3, witnessCount) // wallet input, collateral input and required signer
+
+	txBuilder.SetFee(fee)
+
+	txRaw, txHash, err := txBuilder.Build()
+	require.NoError(t, err)
+	require.Len(t, txHash, 64)
+
+	var (
+		tx   txCbor
+		body struct {
+			Outputs          []cbor.RawMessage `cbor:"1,keyasint"`
+			ScriptDataHash   []byte            `cbor:"11,keyasint"`
+			CollateralInputs []txInputCbor     `cbor:"13,keyasint"`
+			RequiredSigners  [][]byte          `cbor:"14,keyasint"`
+			CollateralReturn cbor.RawMessage   `cbor:"16,keyasint"`
+			TotalCollateral  uint64            `cbor:"17,keyasint"`
+		}
+		witnessSet txWitnessSetCbor
+	)
+
+	require.NoError(t, cbor.Unmarshal(txRaw, &tx))
+	require.NoError(t, cbor.Unmarshal(tx.Body, &body))
+	require.NoError(t, cbor.Unmarshal(tx.WitnessSet, &witnessSet))
+
+	require.Len(t, body.CollateralInputs, 1)
+	require.Equal(t, uint32(1), body.CollateralInputs[0].Index)
+	require.Equal(t, requiredKey, hex.EncodeToString(body.RequiredSigners[0]))
+	require.Equal(t, uint64(1_000_000), body.TotalCollateral)
+	require.NotEmpty(t, body.CollateralReturn)
+	// inline datum output and datum hash output
+	require.Equal(t, "a300581d60244877c1aeefc7fd5405a6e14d927d91758d45e37c20fa2ac89cb1670"+
+		"11a001e8480028201d81842182a", hex.EncodeToString(body.Outputs[0]))
+	require.Contains(t, hex.EncodeToString(body.Outputs[1]), "0282005820"+GetDatumHash(datum))
+
+	require.Empty(t, witnessSet.NativeScripts)
+	require.Empty(t, witnessSet.PlutusV3Scripts)
+	require.Equal(t, [][]byte{{0x4d, 0x01, 0x00, 0x00, 0x33, 0x22, 0x22, 0x20, 0x05, 0x12, 0x00, 0x12, 0x00, 0x11}},
+		witnessSet.PlutusV2Scripts)
+	require.Equal(t, []cbor.RawMessage{datum}, witnessSet.PlutusData)
+	require.Len(t, witnessSet.Redeemers, 2)
+	// script input is the first one after sorting
+	require.Equal(t, uint64(redeemerTagSpend), witnessSet.Redeemers[0].Tag)
+	require.Equal(t, uint64(0), witnessSet.Redeemers[0].Index)
+	require.Equal(t, uint64(redeemerTagMint), witnessSet.Redeemers[1].Tag)
+	require.Equal(t, uint64(0), witnessSet.Redeemers[1].Index)
+
+	expectedScriptDataHash, err := getScriptDataHash(
+		pp, witnessSet.Redeemers, witnessSet.PlutusData, []uint64{plutusLanguageV2})
+	require.NoError(t, err)
+	require.Equal(t, expectedScriptDataHash, body.ScriptDataHash)
+
+	// plutus witnesses must be preserved when vkey witnesses are added
+	wallet, err := GenerateWallet(false)
+	require.NoError(t, err)
+
+	witness, err := txBuilder.CreateTxWitness(txRaw, wallet)
+	require.NoError(t, err)
+
+	txSigned, err := txBuilder.AssembleTxWitnesses(txRaw, [][]byte{witness})
+	require.NoError(t, err)
+
+	var witnessSetSigned txWitnessSetCbor
+
+	require.NoError(t, cbor.Unmarshal(txSigned, &tx))
+	require.NoError(t, cbor.Unmarshal(tx.WitnessSet, &witnessSetSigned))
+
+	require.Len(t, witnessSetSigned.VKeyWitnesses, 1)
+	require.Equal(t, witnessSet.Redeemers, witnessSetSigned.Redeemers)
+	require.Equal(t, witnessSet.PlutusV2Scripts, witnessSetSigned.PlutusV2Scripts)
+
+	feeSigned, err := CalculateMinFee(pp, txSigned, 0)
+	require.NoError(t, err)
+	require.Greater(t, feeSigned, CalculateExUnitsFee(pp, NewProtocolParametersMemorySteps(2_000_000, 1_000_000_000)))
+}
+
+func TestGetScriptDataHash(t *testing.T) {
+	t.Parallel()
+
+	var pp ProtocolParameters
+
+	require.NoError(t, json.Unmarshal(protocolParameters, &pp))
+
+	redeemers := []txRedeemerCbor{
+		{Tag: redeemerTagSpend, Index: 0, Data: []byte{0x00}, ExUnits: txExUnitsCbor{Memory: 1, Steps: 2}},
+	}
+
+	hashV2, err := getScriptDataHash(pp, redeemers, nil, []uint64{plutusLanguageV2})
+	require.NoError(t, err)
+
+	hashV2WithDatum, err := getScriptDataHash(pp, redeemers, []cbor.RawMessage{{0x01}}, []uint64{plutusLanguageV2})
+	require.NoError(t, err)
+
+	hashV2V3, err := getScriptDataHash(pp, redeemers, nil, []uint64{plutusLanguageV3, plutusLanguageV2})
+	require.NoError(t, err)
+
+	hashV3V2, err := getScriptDataHash(pp, redeemers, nil, []uint64{plutusLanguageV2, plutusLanguageV3})
+	require.NoError(t, err)
+
+	require.Len(t, hashV2, 32)
+	require.NotEqual(t, hashV2, hashV2WithDatum)
+	require.NotEqual(t, hashV2, hashV2V3)
+	require.Equal(t, hashV2V3, hashV3V2)
+
+	pp.CostModels = nil
+
+	_, err = getScriptDataHash(pp, redeemers, nil, []uint64{plutusLanguageV2})
+	require.Error(t, err)
+}
+
+func TestTxBuilder_GetWitnessCount(t *testing.T) {
+	const signer = "b9b7f3b1e08b5e6b1c4f0b1ef0cbf2c0ed6c06e3d5ae7b3c9b4b5e3a"
+
+	inputHash := "e7eb2e2b7f8c4ae6cfc6d61d1b1e4c5c4c0a1e0d0b1b4f1d5cf30b9a14d0c3cc"
+
+	txBuilder := NewTxBuilderNative()
+	defer txBuilder.Dispose()
+
+	txBuilder.
+		AddInputs(TxInput{Hash: inputHash, Index: 0}, TxInput{Hash: inputHash, Index: 1}).
+		AddCollateralInputs(TxInput{Hash: inputHash, Index: 1}, TxInput{Hash: inputHash, Index: 2}).
+		AddRequiredSigners(signer, strings.ToUpper(signer))
+
+	// collateral input which is also a regular input and the repeated signer are counted once
+	require.Equal(t, 4, txBuilder.getWitnessCount())
+}
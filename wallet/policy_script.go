@@ -3,12 +3,29 @@ package wallet
 import (
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"sort"
+
+	"github.com/fxamacker/cbor/v2"
 )
 
 const (
 	PolicyScriptAtLeastType = "atLeast"
 	PolicyScriptSigType     = "sig"
+	PolicyScriptAllType     = "all"
+	PolicyScriptAnyType     = "any"
+	PolicyScriptAfterType   = "after"
+	PolicyScriptBeforeType  = "before"
+)
+
+// native script tags as defined in cddl
+const (
+	nativeScriptPubKeyTag = iota
+	nativeScriptAllTag
+	nativeScriptAnyTag
+	nativeScriptNOfKTag
+	nativeScriptInvalidBeforeTag
+	nativeScriptInvalidHereafterTag
 )
 
 type PolicyScript struct {
@@ -49,13 +66,13 @@ func (ps PolicyScript) GetCount() (cnt int) {
 	switch ps.Type {
 	case PolicyScriptSigType:
 		cnt = 1
-	case "any":
+	case PolicyScriptAnyType:
 		for _, x := range ps.Scripts {
 			if subCnt := x.GetCount(); cnt < subCnt {
 				cnt = subCnt
 			}
 		}
-	case "all", PolicyScriptAtLeastType:
+	case PolicyScriptAllType, PolicyScriptAtLeastType:
 		for _, x := range ps.Scripts {
 			cnt += x.GetCount()
 		}
@@ -64,6 +81,110 @@ func (ps PolicyScript) GetCount() (cnt int) {
 	return cnt
 }
 
+// GetBytesCBOR returns native script cbor representation
+func (ps PolicyScript) GetBytesCBOR() ([]byte, error) {
+	value, err := ps.toCborValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return cbor.Marshal(value)
+}
+
+// GetPolicyID calculates policy id (script hash) without cardano-cli
+func (ps PolicyScript) GetPolicyID() (string, error) {
+	bytes, err := ps.GetBytesCBOR()
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := getNativeScriptHash(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash), nil
+}
+
+func (ps PolicyScript) toCborValue() (any, error) {
+	toCborValues := func(scripts []PolicyScript) ([]any, error) {
+		values := make([]any, len(scripts))
+
+		for i, x := range scripts {
+			value, err := x.toCborValue()
+			if err != nil {
+				return nil, err
+			}
+
+			values[i] = value
+		}
+
+		return values, nil
+	}
+
+	switch ps.Type {
+	case PolicyScriptSigType:
+		keyHash, err := hex.DecodeString(ps.KeyHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key hash %s: %w", ps.KeyHash, err)
+		}
+
+		return []any{nativeScriptPubKeyTag, keyHash}, nil
+	case PolicyScriptAllType, PolicyScriptAnyType:
+		values, err := toCborValues(ps.Scripts)
+		if err != nil {
+			return nil, err
+		}
+
+		if ps.Type == PolicyScriptAllType {
+			return []any{nativeScriptAllTag, values}, nil
+		}
+
+		return []any{nativeScriptAnyTag, values}, nil
+	case PolicyScriptAtLeastType:
+		values, err := toCborValues(ps.Scripts)
+		if err != nil {
+			return nil, err
+		}
+
+		return []any{nativeScriptNOfKTag, ps.Required, values}, nil
+	case PolicyScriptAfterType:
+		return []any{nativeScriptInvalidBeforeTag, ps.Slot}, nil
+	case PolicyScriptBeforeType:
+		return []any{nativeScriptInvalidHereafterTag, ps.Slot}, nil
+	default:
+		return nil, fmt.Errorf("unknown policy script type: %s", ps.Type)
+	}
+}
+
+// NewPolicyScriptFromIPolicyScript converts any policy script implementation to PolicyScript
+func NewPolicyScriptFromIPolicyScript(script IPolicyScript) (*PolicyScript, error) {
+	switch ps := script.(type) {
+	case *PolicyScript:
+		return ps, nil
+	case PolicyScript:
+		return &ps, nil
+	}
+
+	bytes, err := script.GetBytesJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var ps *PolicyScript
+
+	if err := json.Unmarshal(bytes, &ps); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// getNativeScriptHash returns hash of the script. Native scripts are prefixed with zero byte before hashing
+func getNativeScriptHash(scriptCbor []byte) ([]byte, error) {
+	return GetKeyHashBytes(append([]byte{0}, scriptCbor...))
+}
+
 // NewPolicyScriptBaseAddress returns base address for policy script IDs
 func NewPolicyScriptBaseAddress(
 	networkID CardanoNetworkType, policyID, stakePolicyID string,
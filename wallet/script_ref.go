@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// script types inside output script reference as defined in cddl
+const (
+	scriptRefTypeNative = 0
+	// plutus script type is equal to plutus language id + 1
+	scriptRefTypePlutusOffset = 1
+)
+
+const simpleScriptType = "SimpleScript"
+
+// NewScriptRefFromPolicyScript returns script reference cbor ([0, native script]) which can be attached to the output
+func NewScriptRefFromPolicyScript(ps IPolicyScript) ([]byte, error) {
+	policyScript, err := NewPolicyScriptFromIPolicyScript(ps)
+	if err != nil {
+		return nil, err
+	}
+
+	scriptCbor, err := policyScript.GetBytesCBOR()
+	if err != nil {
+		return nil, err
+	}
+
+	return cborEncMode.Marshal([]any{scriptRefTypeNative, cbor.RawMessage(scriptCbor)})
+}
+
+// NewScriptRefFromPlutusScript returns script reference cbor ([language + 1, script])
+// which can be attached to the output
+func NewScriptRefFromPlutusScript(ps *PlutusScript) ([]byte, error) {
+	language, err := ps.GetLanguage()
+	if err != nil {
+		return nil, err
+	}
+
+	scriptBytes, err := ps.GetBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return cborEncMode.Marshal([]any{language + scriptRefTypePlutusOffset, scriptBytes})
+}
+
+// scriptRefTextEnvelope is the script from the output script reference in the cardano-cli text envelope format
+type scriptRefTextEnvelope struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	CborHex     string `json:"cborHex"`
+}
+
+func newScriptRefTextEnvelope(scriptRef []byte) (*scriptRefTextEnvelope, error) {
+	var (
+		scriptRefParts []cbor.RawMessage
+		scriptType     uint64
+	)
+
+	if err := cbor.Unmarshal(scriptRef, &scriptRefParts); err != nil {
+		return nil, fmt.Errorf("invalid script reference: %w", err)
+	}
+
+	if len(scriptRefParts) != 2 {
+		return nil, errors.New("invalid script reference: expected script type and script")
+	}
+
+	if err := cbor.Unmarshal(scriptRefParts[0], &scriptType); err != nil {
+		return nil, fmt.Errorf("invalid script reference type: %w", err)
+	}
+
+	switch scriptType {
+	case scriptRefTypeNative:
+		return &scriptRefTextEnvelope{
+			Type:    simpleScriptType,
+			CborHex: hex.EncodeToString(scriptRefParts[1]),
+		}, nil
+	case plutusLanguageV2 + scriptRefTypePlutusOffset:
+		return &scriptRefTextEnvelope{
+			Type:    PlutusScriptV2Type,
+			CborHex: hex.EncodeToString(scriptRefParts[1]),
+		}, nil
+	case plutusLanguageV3 + scriptRefTypePlutusOffset:
+		return &scriptRefTextEnvelope{
+			Type:    PlutusScriptV3Type,
+			CborHex: hex.EncodeToString(scriptRefParts[1]),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported script reference type: %d", scriptType)
+	}
+}
+
+// GetBytesJSON returns script as JSON byte array.
+func (e scriptRefTextEnvelope) GetBytesJSON() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
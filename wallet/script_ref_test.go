@@ -0,0 +1,57 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptRef(t *testing.T) {
+	t.Parallel()
+
+	t.Run("policy script", func(t *testing.T) {
+		policyScript := NewPolicyScript([]string{"244877c1aeefc7fd5405a6e14d927d91758d45e37c20fa2ac89cb167"}, 1)
+
+		scriptRef, err := NewScriptRefFromPolicyScript(policyScript)
+		require.NoError(t, err)
+
+		scriptCbor, err := policyScript.GetBytesCBOR()
+		require.NoError(t, err)
+
+		require.Equal(t, "8200"+hex.EncodeToString(scriptCbor), hex.EncodeToString(scriptRef))
+
+		envelope, err := newScriptRefTextEnvelope(scriptRef)
+		require.NoError(t, err)
+		require.Equal(t, simpleScriptType, envelope.Type)
+		require.Equal(t, hex.EncodeToString(scriptCbor), envelope.CborHex)
+	})
+
+	t.Run("plutus script", func(t *testing.T) {
+		scriptCbor, err := hex.DecodeString("4e4d01000033222220051200120011")
+		require.NoError(t, err)
+
+		scriptRef, err := NewScriptRefFromPlutusScript(NewPlutusScript(PlutusScriptV2Type, scriptCbor))
+		require.NoError(t, err)
+		require.Equal(t, "82024e4d01000033222220051200120011", hex.EncodeToString(scriptRef))
+
+		envelope, err := newScriptRefTextEnvelope(scriptRef)
+		require.NoError(t, err)
+		require.Equal(t, PlutusScriptV2Type, envelope.Type)
+		require.Equal(t, "4e4d01000033222220051200120011", envelope.CborHex)
+
+		_, err = NewScriptRefFromPlutusScript(NewPlutusScript("PlutusScriptV1", scriptCbor))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newScriptRefTextEnvelope([]byte{0x82, 0x01, 0x40})
+		require.Error(t, err)
+
+		_, err = newScriptRefTextEnvelope([]byte{0x81, 0x00})
+		require.Error(t, err)
+
+		_, err = newScriptRefTextEnvelope([]byte{0xff})
+		require.Error(t, err)
+	})
+}
@@ -33,6 +33,12 @@ type TxOutput struct {
 	Addr   string        `json:"addr"`
 	Amount uint64        `json:"amount"`
 	Tokens []TokenAmount `json:"token,omitempty"`
+	// DatumHash is hex encoded hash of the datum attached to the output
+	DatumHash string `json:"datumHash,omitempty"`
+	// InlineDatum is cbor of the datum inlined in the output
+	InlineDatum []byte `json:"inlineDatum,omitempty"`
+	// ScriptRef is cbor of the script ([type, script]) which other transactions can use as reference script
+	ScriptRef []byte `json:"scriptRef,omitempty"`
 }
 
 func NewTxOutput(addr string, amount uint64, tokens ...TokenAmount) TxOutput {
@@ -64,13 +70,21 @@ type TxBuilder struct {
 	certificates       []txCertificateWithPolicyScript
 	metadata           []byte
 	protocolParameters []byte
-	timeToLive         uint64
-	testNetMagic       uint
-	fee                uint64
-	withdrawalData     txWithdrawalDataPolicyScript
-	era                string
-	realEraName        string
-	cardanoCliBinary   string
+	// protocolParametersParsed is lazily parsed protocolParameters
+	protocolParametersParsed *ProtocolParameters
+	timeToLive               uint64
+	testNetMagic             uint
+	fee                      uint64
+	withdrawalData           txWithdrawalDataPolicyScript
+	collateralInputs         []TxInput
+	collateralReturn         *TxOutput
+	totalCollateral          uint64
+	requiredSigners          []string
+	referenceInputs          []TxInput
+	votes                    []txVoteWithPolicyScript
+	era                      string
+	realEraName              string
+	cardanoCliBinary         string
 }
 
 func NewTxBuilder(cardanoCliBinary string) (*TxBuilder, error) {
@@ -97,7 +111,9 @@ func NewTxBuilderForEra(cardanoCliBinary string, era string) (*TxBuilder, error)
 }
 
 func (b *TxBuilder) Dispose() {
-	os.RemoveAll(b.baseDirectory)
+	if b.baseDirectory != "" {
+		os.RemoveAll(b.baseDirectory)
+	}
 }
 
 func (b *TxBuilder) SetTestNetMagic(testNetMagic uint) *TxBuilder {
@@ -139,6 +155,31 @@ func (b *TxBuilder) AddInputsWithScripts(inputs []TxInput, scripts []IPolicyScri
 	return b
 }
 
+// AddInputsWithScriptReference adds inputs locked by native script which is not included in the transaction
+// but taken from the output of scriptRefInput
+func (b *TxBuilder) AddInputsWithScriptReference(
+	scriptRefInput TxInput, script IPolicyScript, inputs ...TxInput,
+) *TxBuilder {
+	for _, inp := range inputs {
+		b.inputs = append(b.inputs, txInputWithPolicyScript{
+			txInput:        inp,
+			policyScript:   script,
+			scriptRefInput: &scriptRefInput,
+		})
+	}
+
+	return b
+}
+
+// AddReferenceInputs adds read-only inputs (scripts and datums of their outputs are visible to the transaction).
+// Size of the scripts from these inputs is not included in the fee,
+// use AddInputsWithScriptReference or PlutusScriptWitness.ReferenceInput for that
+func (b *TxBuilder) AddReferenceInputs(inputs ...TxInput) *TxBuilder {
+	b.referenceInputs = append(b.referenceInputs, inputs...)
+
+	return b
+}
+
 func (b *TxBuilder) AddInputs(inputs ...TxInput) *TxBuilder {
 	for _, inp := range inputs {
 		b.inputs = append(b.inputs, txInputWithPolicyScript{
@@ -149,6 +190,40 @@ func (b *TxBuilder) AddInputs(inputs ...TxInput) *TxBuilder {
 	return b
 }
 
+// AddPlutusInputs adds inputs locked by plutus script. Each input will have its own redeemer
+func (b *TxBuilder) AddPlutusInputs(witness PlutusScriptWitness, inputs ...TxInput) *TxBuilder {
+	for _, inp := range inputs {
+		b.inputs = append(b.inputs, txInputWithPolicyScript{
+			txInput:       inp,
+			plutusWitness: &witness,
+		})
+	}
+
+	return b
+}
+
+// AddCollateralInputs adds inputs which are taken if plutus script validation fails
+func (b *TxBuilder) AddCollateralInputs(inputs ...TxInput) *TxBuilder {
+	b.collateralInputs = append(b.collateralInputs, inputs...)
+
+	return b
+}
+
+// SetCollateralReturn sets output which returns the rest of the collateral and total collateral amount
+func (b *TxBuilder) SetCollateralReturn(output TxOutput, totalCollateral uint64) *TxBuilder {
+	b.collateralReturn = &output
+	b.totalCollateral = totalCollateral
+
+	return b
+}
+
+// AddRequiredSigners adds hex encoded key hashes which must sign transaction (visible to plutus scripts)
+func (b *TxBuilder) AddRequiredSigners(keyHashes ...string) *TxBuilder {
+	b.requiredSigners = append(b.requiredSigners, keyHashes...)
+
+	return b
+}
+
 func (b *TxBuilder) AddOutputs(outputs ...TxOutput) *TxBuilder {
 	b.outputs = append(b.outputs, outputs...)
 
@@ -201,6 +276,16 @@ func (b *TxBuilder) AddTokenMints(
 	return b
 }
 
+// AddPlutusTokenMints adds tokens minted with plutus minting policy. Policy id of the tokens is script hash
+func (b *TxBuilder) AddPlutusTokenMints(witness PlutusScriptWitness, tokens []TokenAmount) *TxBuilder {
+	b.mints.plutusMints = append(b.mints.plutusMints, txPlutusTokenMint{
+		witness: witness,
+		tokens:  tokens,
+	})
+
+	return b
+}
+
 func (b *TxBuilder) SetMetaData(metadata []byte) *TxBuilder {
 	b.metadata = metadata
 
@@ -209,6 +294,7 @@ func (b *TxBuilder) SetMetaData(metadata []byte) *TxBuilder {
 
 func (b *TxBuilder) SetProtocolParameters(protocolParameters []byte) *TxBuilder {
 	b.protocolParameters = protocolParameters
+	b.protocolParametersParsed = nil
 
 	return b
 }
@@ -231,6 +317,18 @@ func (b *TxBuilder) SetWithdrawalData(
 	return b
 }
 
+// AddVotes adds voting procedures. Policy script should be set if voter is script (multisig) drep or committee member
+func (b *TxBuilder) AddVotes(script IPolicyScript, votes ...Vote) *TxBuilder {
+	for _, vote := range votes {
+		b.votes = append(b.votes, txVoteWithPolicyScript{
+			vote:         vote,
+			policyScript: script,
+		})
+	}
+
+	return b
+}
+
 func (b *TxBuilder) AddCertificates(script IPolicyScript, certificates ...ICertificate) *TxBuilder {
 	for _, cert := range certificates {
 		b.certificates = append(b.certificates, txCertificateWithPolicyScript{
@@ -247,6 +345,14 @@ func (b *TxBuilder) CalculateFee(witnessCount int) (uint64, error) {
 		return 0, errors.New("protocol parameters not set")
 	}
 
+	if witnessCount == 0 {
+		witnessCount = b.getWitnessCount()
+	}
+
+	if b.IsNative() {
+		return b.calculateFeeNative(witnessCount)
+	}
+
 	protocolParamsFilePath := filepath.Join(b.baseDirectory, "protocol-parameters.json")
 	if err := os.WriteFile(protocolParamsFilePath, b.protocolParameters, FilePermission); err != nil {
 		return 0, err
@@ -256,16 +362,6 @@ func (b *TxBuilder) CalculateFee(witnessCount int) (uint64, error) {
 		return 0, err
 	}
 
-	if witnessCount == 0 {
-		for _, inp := range b.inputs {
-			witnessCount += inp.GetWitnessCount()
-		}
-
-		witnessCount += getCertfificatesWitnessCount(b.certificates)
-		witnessCount += b.withdrawalData.GetWitnessCount()
-		witnessCount = max(witnessCount, 1)
-	}
-
 	response, err := runCommand(b.cardanoCliBinary, append([]string{
 		b.era, "transaction", "calculate-min-fee",
 		"--tx-body-file", filepath.Join(b.baseDirectory, draftTxFile),
@@ -273,7 +369,7 @@ func (b *TxBuilder) CalculateFee(witnessCount int) (uint64, error) {
 		"--tx-out-count", strconv.Itoa(len(b.outputs)),
 		"--witness-count", strconv.FormatUint(uint64(witnessCount), 10),
 		"--protocol-params-file", protocolParamsFilePath,
-	}, getTestNetMagicArgs(b.testNetMagic)...))
+	}, append(b.getReferenceScriptSizeArgs(), getTestNetMagicArgs(b.testNetMagic)...)...))
 	if err != nil {
 		return 0, err
 	}
@@ -296,6 +392,10 @@ func (b *TxBuilder) CalculateMinUtxo(output TxOutput) (uint64, error) {
 		return 0, errors.New("protocol parameters not set")
 	}
 
+	if b.IsNative() {
+		return b.calculateMinUtxoNative(output)
+	}
+
 	protocolParamsFilePath := filepath.Join(b.baseDirectory, "protocol-parameters.json")
 	if err := os.WriteFile(protocolParamsFilePath, b.protocolParameters, FilePermission); err != nil {
 		return 0, err
@@ -325,6 +425,10 @@ func (b *TxBuilder) Build() ([]byte, string, error) {
 		return nil, "", err
 	}
 
+	if b.IsNative() {
+		return b.buildNative()
+	}
+
 	protocolParamsFilePath := filepath.Join(b.baseDirectory, "protocol-parameters.json")
 	if err := os.WriteFile(protocolParamsFilePath, b.protocolParameters, FilePermission); err != nil {
 		return nil, "", err
@@ -364,6 +468,95 @@ func (b *TxBuilder) CheckOutputs() error {
 	return errors.Join(errs...)
 }
 
+// getWitnessCount estimates the number of vkey witnesses. Keys of the inputs are not known to the builder,
+// so inputs, collateral inputs and required signers sharing the same key are counted separately
+// and the estimate is deliberately an upper bound. Only obvious duplicates are counted once:
+// collateral input which is also spent as a regular input and repeated required signers
+func (b *TxBuilder) getWitnessCount() (witnessCount int) {
+	spentInputs := make(map[TxInput]bool, len(b.inputs))
+
+	for _, inp := range b.inputs {
+		witnessCount += inp.GetWitnessCount()
+		spentInputs[inp.txInput] = true
+	}
+
+	for _, inp := range b.collateralInputs {
+		if !spentInputs[inp] {
+			spentInputs[inp] = true
+			witnessCount++
+		}
+	}
+
+	requiredSigners := make(map[string]bool, len(b.requiredSigners))
+
+	for _, signer := range b.requiredSigners {
+		requiredSigners[strings.ToLower(signer)] = true
+	}
+
+	witnessCount += len(requiredSigners)
+	witnessCount += getCertfificatesWitnessCount(b.certificates)
+	witnessCount += b.withdrawalData.GetWitnessCount()
+	witnessCount += getVotesWitnessCount(b.votes)
+
+	return max(witnessCount, 1)
+}
+
+// getReferenceScriptsSize returns total size of the scripts used by reference (each reference input is counted once)
+func (b *TxBuilder) getReferenceScriptsSize() (uint64, error) {
+	sizes := map[TxInput]uint64{}
+
+	for _, inp := range b.inputs {
+		switch {
+		case inp.scriptRefInput != nil:
+			policyScript, err := NewPolicyScriptFromIPolicyScript(inp.policyScript)
+			if err != nil {
+				return 0, err
+			}
+
+			scriptCbor, err := policyScript.GetBytesCBOR()
+			if err != nil {
+				return 0, err
+			}
+
+			sizes[*inp.scriptRefInput] = uint64(len(scriptCbor))
+		case inp.plutusWitness != nil && inp.plutusWitness.ReferenceInput != nil:
+			size, err := inp.plutusWitness.getScriptSize()
+			if err != nil {
+				return 0, err
+			}
+
+			sizes[*inp.plutusWitness.ReferenceInput] = size
+		}
+	}
+
+	for _, plutusMint := range b.mints.plutusMints {
+		if plutusMint.witness.ReferenceInput != nil {
+			size, err := plutusMint.witness.getScriptSize()
+			if err != nil {
+				return 0, err
+			}
+
+			sizes[*plutusMint.witness.ReferenceInput] = size
+		}
+	}
+
+	totalSize := uint64(0)
+	for _, size := range sizes {
+		totalSize += size
+	}
+
+	return totalSize, nil
+}
+
+func (b *TxBuilder) getReferenceScriptSizeArgs() []string {
+	size, err := b.getReferenceScriptsSize()
+	if err != nil || size == 0 {
+		return nil
+	}
+
+	return []string{"--reference-script-size", strconv.FormatUint(size, 10)}
+}
+
 func (b *TxBuilder) buildRawTx(protocolParamsFilePath string, fee uint64) error {
 	args := []string{
 		b.era, "transaction", "build-raw",
@@ -402,8 +595,34 @@ func (b *TxBuilder) buildRawTx(protocolParamsFilePath string, fee uint64) error
 		}
 	}
 
-	for _, out := range b.outputs {
-		args = append(args, "--tx-out", out.String())
+	for _, inp := range b.collateralInputs {
+		args = append(args, "--tx-in-collateral", inp.String())
+	}
+
+	if b.collateralReturn != nil {
+		args = append(args,
+			"--tx-out-return-collateral", b.collateralReturn.String(),
+			"--tx-total-collateral", strconv.FormatUint(b.totalCollateral, 10))
+	}
+
+	for _, keyHash := range b.requiredSigners {
+		args = append(args, "--required-signer-hash", keyHash)
+	}
+
+	for _, inp := range b.referenceInputs {
+		args = append(args, "--read-only-tx-in-reference", inp.String())
+	}
+
+	for i, vote := range b.votes {
+		if err := vote.Apply(&args, b.cardanoCliBinary, b.era, b.baseDirectory, i); err != nil {
+			return err
+		}
+	}
+
+	for i, out := range b.outputs {
+		if err := out.Apply(&args, b.baseDirectory, i); err != nil {
+			return err
+		}
 	}
 
 	_, err := runCommand(b.cardanoCliBinary, args)
@@ -426,6 +645,10 @@ func (b *TxBuilder) SignTx(txRaw []byte, signers []ITxSigner) (res []byte, err e
 
 // CreateTxWitness signs transaction hash and creates witness cbor
 func (b *TxBuilder) CreateTxWitness(txRaw []byte, wallet ITxSigner) ([]byte, error) {
+	if b.IsNative() {
+		return b.createTxWitnessNative(txRaw, wallet)
+	}
+
 	outFilePath := filepath.Join(b.baseDirectory, "tx.wit")
 	txFilePath := filepath.Join(b.baseDirectory, "tx.raw")
 	signingKeyPath := filepath.Join(b.baseDirectory, "tx.skey")
@@ -477,6 +700,10 @@ func (b *TxBuilder) CreateTxWitness(txRaw []byte, wallet ITxSigner) ([]byte, err
 
 // AssembleTxWitnesses assembles final signed transaction
 func (b *TxBuilder) AssembleTxWitnesses(txRaw []byte, witnesses [][]byte) ([]byte, error) {
+	if b.IsNative() {
+		return b.assembleTxWitnessesNative(txRaw, witnesses)
+	}
+
 	outFilePath := filepath.Join(b.baseDirectory, "tx.sig")
 	txFilePath := filepath.Join(b.baseDirectory, "tx.raw")
 	witnessesFilePaths := make([]string, len(witnesses))
@@ -524,9 +751,45 @@ func (b *TxBuilder) AssembleTxWitnesses(txRaw []byte, witnesses [][]byte) ([]byt
 	return newTransactionWitnessedRawFromJSON(bytes)
 }
 
+func (o TxOutput) Apply(args *[]string, basePath string, indx int) error {
+	*args = append(*args, "--tx-out", o.String())
+
+	if o.DatumHash != "" {
+		*args = append(*args, "--tx-out-datum-hash", o.DatumHash)
+	}
+
+	if o.InlineDatum != nil {
+		filePath := filepath.Join(basePath, fmt.Sprintf("out_datum_%d.cbor", indx))
+		if err := os.WriteFile(filePath, o.InlineDatum, FilePermission); err != nil {
+			return err
+		}
+
+		*args = append(*args, "--tx-out-inline-datum-cbor-file", filePath)
+	}
+
+	if o.ScriptRef != nil {
+		script, err := newScriptRefTextEnvelope(o.ScriptRef)
+		if err != nil {
+			return err
+		}
+
+		filePath, err := writeSerializableToFile(script, basePath, fmt.Sprintf("out_script_%d.json", indx))
+		if err != nil {
+			return err
+		}
+
+		*args = append(*args, "--tx-out-reference-script-file", filePath)
+	}
+
+	return nil
+}
+
 type txInputWithPolicyScript struct {
-	txInput      TxInput
-	policyScript IPolicyScript
+	txInput       TxInput
+	policyScript  IPolicyScript
+	plutusWitness *PlutusScriptWitness
+	// scriptRefInput is set if policyScript is not included in the transaction but used by reference
+	scriptRefInput *TxInput
 }
 
 func (txInputPS txInputWithPolicyScript) Apply(
@@ -534,7 +797,9 @@ func (txInputPS txInputWithPolicyScript) Apply(
 ) error {
 	*args = append(*args, "--tx-in", txInputPS.txInput.String())
 
-	if txInputPS.policyScript != nil {
+	if txInputPS.scriptRefInput != nil {
+		*args = append(*args, "--simple-script-tx-in-reference", txInputPS.scriptRefInput.String())
+	} else if txInputPS.policyScript != nil {
 		filePath, err := writeSerializableToFile(txInputPS.policyScript, basePath, fmt.Sprintf("ps_%d.json", indx))
 		if err != nil {
 			return err
@@ -543,6 +808,10 @@ func (txInputPS txInputWithPolicyScript) Apply(
 		*args = append(*args, "--tx-in-script-file", filePath)
 	}
 
+	if txInputPS.plutusWitness != nil {
+		return txInputPS.plutusWitness.Apply(args, basePath, fmt.Sprintf("in_%d", indx), false)
+	}
+
 	return nil
 }
 
@@ -551,24 +820,45 @@ func (txInputPS txInputWithPolicyScript) GetWitnessCount() int {
 		return txInputPS.policyScript.GetCount()
 	}
 
+	if txInputPS.plutusWitness != nil {
+		return 0
+	}
+
 	return 1
 }
 
+type txPlutusTokenMint struct {
+	witness PlutusScriptWitness
+	tokens  []TokenAmount
+}
+
 type txTokenMintInputs struct {
 	tokens        []TokenAmount
 	policyScripts []IPolicyScript
+	plutusMints   []txPlutusTokenMint
+}
+
+// getAllTokens returns tokens minted with both native and plutus scripts
+func (txMint txTokenMintInputs) getAllTokens() []TokenAmount {
+	tokens := slices.Clone(txMint.tokens)
+	for _, x := range txMint.plutusMints {
+		tokens = append(tokens, x.tokens...)
+	}
+
+	return tokens
 }
 
 func (txMint txTokenMintInputs) Apply(
 	args *[]string, basePath string,
 ) error {
-	if len(txMint.tokens) == 0 {
+	tokens := txMint.getAllTokens()
+	if len(tokens) == 0 {
 		return nil
 	}
 
 	var sb strings.Builder
 
-	for _, token := range txMint.tokens {
+	for _, token := range tokens {
 		if sb.Len() > 0 {
 			sb.WriteRune('+')
 		}
@@ -587,6 +877,12 @@ func (txMint txTokenMintInputs) Apply(
 		*args = append(*args, "--minting-script-file", policyFilePath)
 	}
 
+	for indx, plutusMint := range txMint.plutusMints {
+		if err := plutusMint.witness.Apply(args, basePath, fmt.Sprintf("mint_%d", indx), true); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -660,6 +956,101 @@ func (txCert txCertificateWithPolicyScript) GetWitnessCount() int {
 	return 0
 }
 
+type txVoteWithPolicyScript struct {
+	vote         Vote
+	policyScript IPolicyScript
+}
+
+// Apply creates vote file with cardano-cli and appends it (and its script) to the arguments
+func (txVote txVoteWithPolicyScript) Apply(
+	args *[]string, cardanoCliBinary, era, basePath string, index int,
+) error {
+	voteFilePath := filepath.Join(basePath, fmt.Sprintf("vote_%d.vote", index))
+	vote := txVote.vote
+
+	voteArgs := []string{
+		era, "governance", "vote", "create",
+		"--governance-action-tx-id", vote.GovActionID.TxHash,
+		"--governance-action-index", strconv.FormatUint(uint64(vote.GovActionID.Index), 10),
+		"--out-file", voteFilePath,
+	}
+
+	switch vote.Choice {
+	case VoteNo:
+		voteArgs = append(voteArgs, "--no")
+	case VoteYes:
+		voteArgs = append(voteArgs, "--yes")
+	case VoteAbstain:
+		voteArgs = append(voteArgs, "--abstain")
+	default:
+		return fmt.Errorf("unsupported vote: %d", vote.Choice)
+	}
+
+	switch vote.Voter.Type {
+	case VoterTypeCommitteeHotKeyHash:
+		voteArgs = append(voteArgs, "--cc-hot-key-hash", vote.Voter.Hash)
+	case VoterTypeCommitteeHotScriptHash:
+		voteArgs = append(voteArgs, "--cc-hot-script-hash", vote.Voter.Hash)
+	case VoterTypeDRepKeyHash:
+		voteArgs = append(voteArgs, "--drep-key-hash", vote.Voter.Hash)
+	case VoterTypeDRepScriptHash:
+		voteArgs = append(voteArgs, "--drep-script-hash", vote.Voter.Hash)
+	case VoterTypeStakePoolKeyHash:
+		voteArgs = append(voteArgs, "--stake-pool-id", vote.Voter.Hash)
+	default:
+		return fmt.Errorf("unsupported voter type: %d", vote.Voter.Type)
+	}
+
+	if vote.Anchor != nil {
+		voteArgs = append(voteArgs, "--anchor-url", vote.Anchor.URL, "--anchor-data-hash", vote.Anchor.DataHash)
+	}
+
+	if _, err := runCommand(cardanoCliBinary, voteArgs); err != nil {
+		return fmt.Errorf("failed to create vote: %w", err)
+	}
+
+	*args = append(*args, "--vote-file", voteFilePath)
+
+	if txVote.policyScript == nil {
+		return nil
+	}
+
+	policyFilePath, err := writeSerializableToFile(
+		txVote.policyScript,
+		basePath,
+		fmt.Sprintf("vote_policy_%d.json", index),
+	)
+	if err != nil {
+		return err
+	}
+
+	*args = append(*args, "--vote-script-file", policyFilePath)
+
+	return nil
+}
+
+func getVotesWitnessCount(votes []txVoteWithPolicyScript) int {
+	witnessCount := 0
+	// the same voter signs once no matter how many governance actions it votes for
+	processedVoters := make([]Voter, 0, len(votes))
+
+	for _, vote := range votes {
+		if slices.Contains(processedVoters, vote.vote.Voter) {
+			continue
+		}
+
+		processedVoters = append(processedVoters, vote.vote.Voter)
+
+		if vote.policyScript != nil {
+			witnessCount += vote.policyScript.GetCount()
+		} else {
+			witnessCount++
+		}
+	}
+
+	return witnessCount
+}
+
 type txWithdrawalDataPolicyScript struct {
 	stakeAddress string
 	rewardAmount uint64
@@ -0,0 +1,429 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	metadataMaxStringLength = 64
+	auxiliaryDataTag        = 259
+	encodedCborTag          = 24
+	blake2b256HashSize      = 32
+
+	datumOptionHash   = 0
+	datumOptionInline = 1
+)
+
+var (
+	cborEncMode, _ = cbor.CoreDetEncOptions().EncMode()
+
+	errMetadataInvalid = errors.New("invalid metadata")
+)
+
+type txInputCbor struct {
+	_     struct{} `cbor:",toarray"`
+	Hash  []byte
+	Index uint32
+}
+
+type txLegacyOutputCbor struct {
+	_      struct{} `cbor:",toarray"`
+	Addr   []byte
+	Amount cbor.RawMessage
+}
+
+// txOutputCbor is post-alonzo output format (map) used when output has datum or script reference
+type txOutputCbor struct {
+	Addr        []byte          `cbor:"0,keyasint"`
+	Amount      cbor.RawMessage `cbor:"1,keyasint"`
+	DatumOption []any           `cbor:"2,keyasint,omitempty"`
+	ScriptRef   *cbor.Tag       `cbor:"3,keyasint,omitempty"`
+}
+
+type txRedeemerCbor struct {
+	_       struct{} `cbor:",toarray"`
+	Tag     uint64
+	Index   uint64
+	Data    cbor.RawMessage
+	ExUnits txExUnitsCbor
+}
+
+type txExUnitsCbor struct {
+	_      struct{} `cbor:",toarray"`
+	Memory uint64
+	Steps  uint64
+}
+
+type txMultiAssetCbor[T int64 | uint64] map[cbor.ByteString]map[cbor.ByteString]T
+
+type txValueCbor struct {
+	_          struct{} `cbor:",toarray"`
+	Amount     uint64
+	MultiAsset txMultiAssetCbor[uint64]
+}
+
+type txVKeyWitnessCbor struct {
+	_         struct{} `cbor:",toarray"`
+	VKey      []byte
+	Signature []byte
+}
+
+type txBodyCbor struct {
+	Inputs           []txInputCbor              `cbor:"0,keyasint"`
+	Outputs          []cbor.RawMessage          `cbor:"1,keyasint"`
+	Fee              uint64                     `cbor:"2,keyasint"`
+	TimeToLive       uint64                     `cbor:"3,keyasint"`
+	Certificates     []cbor.RawMessage          `cbor:"4,keyasint,omitempty"`
+	Withdrawals      map[cbor.ByteString]uint64 `cbor:"5,keyasint,omitempty"`
+	AuxDataHash      []byte                     `cbor:"7,keyasint,omitempty"`
+	Mint             txMultiAssetCbor[int64]    `cbor:"9,keyasint,omitempty"`
+	ScriptDataHash   []byte                     `cbor:"11,keyasint,omitempty"`
+	CollateralInputs []txInputCbor              `cbor:"13,keyasint,omitempty"`
+	RequiredSigners  [][]byte                   `cbor:"14,keyasint,omitempty"`
+	CollateralReturn cbor.RawMessage            `cbor:"16,keyasint,omitempty"`
+	TotalCollateral  uint64                     `cbor:"17,keyasint,omitempty"`
+	ReferenceInputs  []txInputCbor              `cbor:"18,keyasint,omitempty"`
+	VotingProcedures cbor.RawMessage            `cbor:"19,keyasint,omitempty"`
+}
+
+type txWitnessSetCbor struct {
+	VKeyWitnesses   []txVKeyWitnessCbor `cbor:"0,keyasint,omitempty"`
+	NativeScripts   []cbor.RawMessage   `cbor:"1,keyasint,omitempty"`
+	PlutusData      []cbor.RawMessage   `cbor:"4,keyasint,omitempty"`
+	Redeemers       []txRedeemerCbor    `cbor:"5,keyasint,omitempty"`
+	PlutusV2Scripts [][]byte            `cbor:"6,keyasint,omitempty"`
+	PlutusV3Scripts [][]byte            `cbor:"7,keyasint,omitempty"`
+}
+
+type txCbor struct {
+	_          struct{} `cbor:",toarray"`
+	Body       cbor.RawMessage
+	WitnessSet cbor.RawMessage
+	IsValid    bool
+	AuxData    cbor.RawMessage
+}
+
+// txMetadataMapCbor is a cbor map which preserves the order of the keys (cardano-cli sorts them lexicographically)
+type txMetadataMapCbor []txMetadataMapEntryCbor
+
+type txMetadataMapEntryCbor struct {
+	Key   any
+	Value any
+}
+
+func (m txMetadataMapCbor) MarshalCBOR() ([]byte, error) {
+	const majorTypeMap = 0xa0
+
+	var buf bytes.Buffer
+
+	// header is the same as for the integer with the same length, only major type differs
+	header, err := cborEncMode.Marshal(uint64(len(m)))
+	if err != nil {
+		return nil, err
+	}
+
+	header[0] |= majorTypeMap
+
+	buf.Write(header)
+
+	for _, entry := range m {
+		for _, x := range []any{entry.Key, entry.Value} {
+			bytes, err := cborEncMode.Marshal(x)
+			if err != nil {
+				return nil, err
+			}
+
+			buf.Write(bytes)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newTxInputCbor(input TxInput) (txInputCbor, error) {
+	hash, err := hex.DecodeString(input.Hash)
+	if err != nil {
+		return txInputCbor{}, fmt.Errorf("invalid input hash %s: %w", input.Hash, err)
+	}
+
+	return txInputCbor{
+		Hash:  hash,
+		Index: input.Index,
+	}, nil
+}
+
+// newTxInputsCbor converts inputs to cbor representation sorted the same way as ledger does (hash, index)
+func newTxInputsCbor(inputs []TxInput) ([]txInputCbor, error) {
+	result := make([]txInputCbor, len(inputs))
+
+	for i, inp := range inputs {
+		value, err := newTxInputCbor(inp)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = value
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if cmp := bytes.Compare(result[i].Hash, result[j].Hash); cmp != 0 {
+			return cmp < 0
+		}
+
+		return result[i].Index < result[j].Index
+	})
+
+	return result, nil
+}
+
+func newTxMultiAssetCbor[T int64 | uint64](
+	tokens []TokenAmount, convert func(uint64) T,
+) (txMultiAssetCbor[T], error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	result := txMultiAssetCbor[T]{}
+
+	for _, token := range tokens {
+		policyID, err := hex.DecodeString(token.PolicyID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy id %s: %w", token.PolicyID, err)
+		}
+
+		key := cbor.ByteString(policyID)
+		if _, exists := result[key]; !exists {
+			result[key] = map[cbor.ByteString]T{}
+		}
+
+		result[key][cbor.ByteString(token.Name)] += convert(token.Amount)
+	}
+
+	return result, nil
+}
+
+func newTxOutputCbor(output TxOutput) (cbor.RawMessage, error) {
+	addr, err := NewCardanoAddressFromString(output.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output address %s: %w", output.Addr, err)
+	}
+
+	amount, err := newTxAmountCbor(output.Amount, output.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	if output.InlineDatum == nil && output.DatumHash == "" && output.ScriptRef == nil {
+		return cborEncMode.Marshal(txLegacyOutputCbor{
+			Addr:   addr.GetBytes(),
+			Amount: amount,
+		})
+	}
+
+	result := txOutputCbor{
+		Addr:   addr.GetBytes(),
+		Amount: amount,
+	}
+
+	if output.InlineDatum != nil {
+		result.DatumOption = []any{
+			datumOptionInline,
+			cbor.Tag{Number: encodedCborTag, Content: output.InlineDatum},
+		}
+	} else if output.DatumHash != "" {
+		datumHash, err := hex.DecodeString(output.DatumHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid datum hash %s: %w", output.DatumHash, err)
+		}
+
+		result.DatumOption = []any{datumOptionHash, datumHash}
+	}
+
+	if output.ScriptRef != nil {
+		result.ScriptRef = &cbor.Tag{Number: encodedCborTag, Content: output.ScriptRef}
+	}
+
+	return cborEncMode.Marshal(result)
+}
+
+func newTxAmountCbor(amount uint64, tokens []TokenAmount) (cbor.RawMessage, error) {
+	multiAsset, err := newTxMultiAssetCbor(tokens, func(x uint64) uint64 { return x })
+	if err != nil {
+		return nil, err
+	}
+
+	if len(multiAsset) == 0 {
+		return cborEncMode.Marshal(amount)
+	}
+
+	return cborEncMode.Marshal(txValueCbor{
+		Amount:     amount,
+		MultiAsset: multiAsset,
+	})
+}
+
+// newTxAuxDataCbor converts json metadata (cardano-cli no schema format) to cbor auxiliary data
+func newTxAuxDataCbor(metadata []byte) (cbor.RawMessage, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(metadata))
+	decoder.UseNumber()
+
+	var raw map[string]any
+
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, errors.Join(errMetadataInvalid, err)
+	}
+
+	labels := make([]uint64, 0, len(raw))
+	labelToKey := make(map[uint64]string, len(raw))
+
+	for key := range raw {
+		label, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: label %s is not a number", errMetadataInvalid, key)
+		}
+
+		labels = append(labels, label)
+		labelToKey[label] = key
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i] < labels[j]
+	})
+
+	result := make(txMetadataMapCbor, len(labels))
+
+	for i, label := range labels {
+		value, err := convertMetadataValue(raw[labelToKey[label]])
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = txMetadataMapEntryCbor{
+			Key:   label,
+			Value: value,
+		}
+	}
+
+	return cborEncMode.Marshal(cbor.Tag{
+		Number:  auxiliaryDataTag,
+		Content: map[int]any{0: result},
+	})
+}
+
+func convertMetadataValue(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if intValue, err := v.Int64(); err == nil {
+			return intValue, nil
+		}
+
+		if uintValue, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return uintValue, nil
+		}
+
+		return nil, fmt.Errorf("%w: number %s is not an integer", errMetadataInvalid, v)
+	case string:
+		if hexStr, ok := strings.CutPrefix(v, "0x"); ok {
+			if bytes, err := hex.DecodeString(hexStr); err == nil {
+				if len(bytes) > metadataMaxStringLength {
+					return nil, fmt.Errorf("%w: bytes %s are longer than %d", errMetadataInvalid, v, metadataMaxStringLength)
+				}
+
+				return bytes, nil
+			}
+		}
+
+		return convertMetadataString(v)
+	case []any:
+		result := make([]any, len(v))
+
+		for i, x := range v {
+			item, err := convertMetadataValue(x)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = item
+		}
+
+		return result, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		result := make(txMetadataMapCbor, len(keys))
+
+		for i, key := range keys {
+			item, err := convertMetadataValue(v[key])
+			if err != nil {
+				return nil, err
+			}
+
+			keyValue, err := convertMetadataKey(key)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = txMetadataMapEntryCbor{
+				Key:   keyValue,
+				Value: item,
+			}
+		}
+
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported value %v", errMetadataInvalid, value)
+	}
+}
+
+func convertMetadataKey(key string) (any, error) {
+	if intValue, err := strconv.ParseInt(key, 10, 64); err == nil {
+		return intValue, nil
+	}
+
+	return convertMetadataValue(key)
+}
+
+func convertMetadataString(value string) (any, error) {
+	if len(value) > metadataMaxStringLength {
+		return nil, fmt.Errorf("%w: string %s is longer than %d", errMetadataInvalid, value, metadataMaxStringLength)
+	}
+
+	return value, nil
+}
+
+// getTxBodyFromRaw extracts transaction body cbor from full transaction cbor
+func getTxBodyFromRaw(txRaw []byte) (cbor.RawMessage, error) {
+	var tx txCbor
+
+	if err := cbor.Unmarshal(txRaw, &tx); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+
+	return tx.Body, nil
+}
+
+// getBlake2b256Hash returns blake2b-256 hash (used for transaction body and auxiliary data hashes)
+func getBlake2b256Hash(data []byte) []byte {
+	hash := blake2b.Sum256(data)
+
+	return hash[:]
+}
@@ -147,7 +147,13 @@ func GetMinUtxoForSumMap(
 		}
 	}
 
-	return txBuilder.CalculateMinUtxo(txOutput)
+	// calculated in-process even for cardano-cli builder because result is the same and it is much faster
+	protocolParameters, err := txBuilder.getProtocolParameters()
+	if err != nil {
+		return 0, err
+	}
+
+	return CalculateMinUtxo(*protocolParameters, txOutput)
 }
 
 // CreateTxOutputChange generates a TxOutput representing the change
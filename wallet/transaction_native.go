@@ -0,0 +1,651 @@
+package wallet
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	nativeEraName = "Conway"
+	// stake registration certificate (legacy) does not require witness so its script is not part of the tx
+	stakeRegistrationCertificateType = 0
+)
+
+// NewTxBuilderNative creates TxBuilder which builds transactions, calculates fees and min utxo
+// and signs transactions in-process without cardano-cli binary and temporary files
+func NewTxBuilderNative() *TxBuilder {
+	return &TxBuilder{
+		era:         DefaultEra,
+		realEraName: nativeEraName,
+	}
+}
+
+// IsNative returns true if builder does not use cardano-cli
+func (b *TxBuilder) IsNative() bool {
+	return b.cardanoCliBinary == ""
+}
+
+// getProtocolParameters returns parsed protocol parameters. Parsed value is cached until parameters are changed
+func (b *TxBuilder) getProtocolParameters() (*ProtocolParameters, error) {
+	if b.protocolParametersParsed != nil {
+		return b.protocolParametersParsed, nil
+	}
+
+	if b.protocolParameters == nil {
+		return nil, errors.New("protocol parameters not set")
+	}
+
+	var protocolParameters ProtocolParameters
+
+	if err := json.Unmarshal(b.protocolParameters, &protocolParameters); err != nil {
+		return nil, fmt.Errorf("invalid protocol parameters: %w", err)
+	}
+
+	b.protocolParametersParsed = &protocolParameters
+
+	return b.protocolParametersParsed, nil
+}
+
+func (b *TxBuilder) calculateFeeNative(witnessCount int) (uint64, error) {
+	protocolParameters, err := b.getProtocolParameters()
+	if err != nil {
+		return 0, err
+	}
+
+	txRaw, _, err := b.buildRawTxNative(0)
+	if err != nil {
+		return 0, err
+	}
+
+	referenceScriptsSize, err := b.getReferenceScriptsSize()
+	if err != nil {
+		return 0, err
+	}
+
+	fee, err := CalculateMinFee(*protocolParameters, txRaw, witnessCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return fee + CalculateRefScriptFee(*protocolParameters, referenceScriptsSize), nil
+}
+
+func (b *TxBuilder) calculateMinUtxoNative(output TxOutput) (uint64, error) {
+	protocolParameters, err := b.getProtocolParameters()
+	if err != nil {
+		return 0, err
+	}
+
+	return CalculateMinUtxo(*protocolParameters, output)
+}
+
+func (b *TxBuilder) buildNative() ([]byte, string, error) {
+	txRaw, txBody, err := b.buildRawTxNative(b.fee)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return txRaw, hex.EncodeToString(getBlake2b256Hash(txBody)), nil
+}
+
+// buildRawTxNative returns full transaction cbor (without vkey witnesses) and transaction body cbor
+func (b *TxBuilder) buildRawTxNative(fee uint64) ([]byte, []byte, error) {
+	body, err := b.getTxBodyCbor(fee)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	auxData, err := newTxAuxDataCbor(b.metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if auxData != nil {
+		body.AuxDataHash = getBlake2b256Hash(auxData)
+	}
+
+	nativeScripts, err := b.getNativeScriptsCbor()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	witnessSet := txWitnessSetCbor{
+		NativeScripts: nativeScripts,
+	}
+
+	if err := b.fillPlutusWitnesses(body, &witnessSet); err != nil {
+		return nil, nil, err
+	}
+
+	bodyRaw, err := cborEncMode.Marshal(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	witnessSetRaw, err := cborEncMode.Marshal(witnessSet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txRaw, err := cborEncMode.Marshal(txCbor{
+		Body:       bodyRaw,
+		WitnessSet: witnessSetRaw,
+		IsValid:    true,
+		AuxData:    auxData,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return txRaw, bodyRaw, nil
+}
+
+func (b *TxBuilder) getTxBodyCbor(fee uint64) (*txBodyCbor, error) {
+	inputs := make([]TxInput, len(b.inputs))
+	for i, inp := range b.inputs {
+		inputs[i] = inp.txInput
+	}
+
+	inputsCbor, err := newTxInputsCbor(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	outputsCbor := make([]cbor.RawMessage, len(b.outputs))
+
+	for i, out := range b.outputs {
+		outputsCbor[i], err = newTxOutputCbor(out)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	certificatesCbor := make([]cbor.RawMessage, 0, len(b.certificates))
+
+	for _, cert := range b.certificates {
+		certificateCbor, err := cert.GetBytesCBOR()
+		if err != nil {
+			return nil, err
+		}
+
+		if certificateCbor != nil {
+			certificatesCbor = append(certificatesCbor, certificateCbor)
+		}
+	}
+
+	withdrawalsCbor, err := b.withdrawalData.GetCborValue()
+	if err != nil {
+		return nil, err
+	}
+
+	mintCbor, err := newTxMultiAssetCbor(b.mints.getAllTokens(), func(x uint64) int64 { return int64(x) }) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	collateralInputsCbor, err := newTxInputsCbor(b.collateralInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	var collateralReturnCbor cbor.RawMessage
+
+	if b.collateralReturn != nil {
+		collateralReturnCbor, err = newTxOutputCbor(*b.collateralReturn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	referenceInputsCbor, err := newTxInputsCbor(b.getReferenceInputs())
+	if err != nil {
+		return nil, err
+	}
+
+	votes := make([]Vote, len(b.votes))
+	for i, vote := range b.votes {
+		votes[i] = vote.vote
+	}
+
+	votingProceduresCbor, err := newVotingProceduresCbor(votes)
+	if err != nil {
+		return nil, err
+	}
+
+	requiredSigners := make([][]byte, len(b.requiredSigners))
+
+	for i, keyHash := range b.requiredSigners {
+		requiredSigners[i], err = hex.DecodeString(keyHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid required signer %s: %w", keyHash, err)
+		}
+	}
+
+	return &txBodyCbor{
+		Inputs:           inputsCbor,
+		Outputs:          outputsCbor,
+		Fee:              fee,
+		TimeToLive:       b.timeToLive,
+		Certificates:     certificatesCbor,
+		Withdrawals:      withdrawalsCbor,
+		Mint:             mintCbor,
+		CollateralInputs: collateralInputsCbor,
+		RequiredSigners:  requiredSigners,
+		CollateralReturn: collateralReturnCbor,
+		TotalCollateral:  b.totalCollateral,
+		ReferenceInputs:  referenceInputsCbor,
+		VotingProcedures: votingProceduresCbor,
+	}, nil
+}
+
+// getReferenceInputs returns unique read-only inputs and inputs with scripts used by reference
+func (b *TxBuilder) getReferenceInputs() []TxInput {
+	inputs := slices.Clone(b.referenceInputs)
+
+	for _, inp := range b.inputs {
+		if inp.scriptRefInput != nil {
+			inputs = append(inputs, *inp.scriptRefInput)
+		} else if inp.plutusWitness != nil && inp.plutusWitness.ReferenceInput != nil {
+			inputs = append(inputs, *inp.plutusWitness.ReferenceInput)
+		}
+	}
+
+	for _, plutusMint := range b.mints.plutusMints {
+		if plutusMint.witness.ReferenceInput != nil {
+			inputs = append(inputs, *plutusMint.witness.ReferenceInput)
+		}
+	}
+
+	// the same input with hash in different hex case must be compacted too
+	for i := range inputs {
+		inputs[i].Hash = strings.ToLower(inputs[i].Hash)
+	}
+
+	// canonical order (hash bytes, numeric index) is the same as the one used for the regular inputs
+	slices.SortFunc(inputs, func(a, b TxInput) int {
+		return cmp.Or(strings.Compare(a.Hash, b.Hash), cmp.Compare(a.Index, b.Index))
+	})
+
+	return slices.Compact(inputs)
+}
+
+// fillPlutusWitnesses adds plutus scripts, datums and redeemers to the witness set
+// and sets script data hash of the transaction body
+func (b *TxBuilder) fillPlutusWitnesses(body *txBodyCbor, witnessSet *txWitnessSetCbor) error {
+	type redeemerWitness struct {
+		tag     uint64
+		index   int
+		witness PlutusScriptWitness
+	}
+
+	redeemerWitnesses := []redeemerWitness(nil)
+
+	for _, inp := range b.inputs {
+		if inp.plutusWitness == nil {
+			continue
+		}
+
+		inputCbor, err := newTxInputCbor(inp.txInput)
+		if err != nil {
+			return err
+		}
+
+		// spend redeemer points to the input position in the sorted inputs
+		index := slices.IndexFunc(body.Inputs, func(x txInputCbor) bool {
+			return bytes.Equal(x.Hash, inputCbor.Hash) && x.Index == inputCbor.Index
+		})
+
+		redeemerWitnesses = append(redeemerWitnesses, redeemerWitness{
+			tag:     redeemerTagSpend,
+			index:   index,
+			witness: *inp.plutusWitness,
+		})
+	}
+
+	// mint redeemer points to the policy id position in the sorted policy ids
+	policyIDs := make([]cbor.ByteString, 0, len(body.Mint))
+	for policyID := range body.Mint {
+		policyIDs = append(policyIDs, policyID)
+	}
+
+	slices.Sort(policyIDs)
+
+	for _, plutusMint := range b.mints.plutusMints {
+		if plutusMint.witness.Script == nil {
+			return errors.New("plutus minting script not set")
+		}
+
+		policyID, err := plutusMint.witness.Script.getScriptHashBytes()
+		if err != nil {
+			return err
+		}
+
+		index := slices.Index(policyIDs, cbor.ByteString(policyID))
+		if index < 0 {
+			return fmt.Errorf("no tokens minted with plutus policy %s", hex.EncodeToString(policyID))
+		}
+
+		redeemerWitnesses = append(redeemerWitnesses, redeemerWitness{
+			tag:     redeemerTagMint,
+			index:   index,
+			witness: plutusMint.witness,
+		})
+	}
+
+	if len(redeemerWitnesses) == 0 {
+		return nil
+	}
+
+	type scriptWithLanguage struct {
+		hash     []byte
+		language uint64
+		bytes    []byte
+	}
+
+	var (
+		uniqueScripts = map[string]scriptWithLanguage{}
+		uniqueDatums  = map[string]cbor.RawMessage{}
+		languages     = map[uint64]bool{}
+	)
+
+	witnessSet.Redeemers = make([]txRedeemerCbor, len(redeemerWitnesses))
+
+	for i, x := range redeemerWitnesses {
+		if x.witness.Script == nil {
+			return errors.New("plutus script not set")
+		}
+
+		language, err := x.witness.Script.GetLanguage()
+		if err != nil {
+			return err
+		}
+
+		scriptBytes, err := x.witness.Script.GetBytes()
+		if err != nil {
+			return err
+		}
+
+		hash, err := x.witness.Script.getScriptHashBytes()
+		if err != nil {
+			return err
+		}
+
+		// script used by reference is not part of the witness set but its language is part of the script data hash
+		if x.witness.ReferenceInput == nil {
+			uniqueScripts[string(hash)] = scriptWithLanguage{
+				hash:     hash,
+				language: language,
+				bytes:    scriptBytes,
+			}
+		}
+
+		languages[language] = true
+
+		if x.witness.Datum != nil {
+			uniqueDatums[string(getBlake2b256Hash(x.witness.Datum))] = x.witness.Datum
+		}
+
+		witnessSet.Redeemers[i] = txRedeemerCbor{
+			Tag:   x.tag,
+			Index: uint64(x.index), //nolint:gosec
+			Data:  x.witness.Redeemer,
+			ExUnits: txExUnitsCbor{
+				Memory: x.witness.ExUnits.Memory,
+				Steps:  x.witness.ExUnits.Steps,
+			},
+		}
+	}
+
+	sort.Slice(witnessSet.Redeemers, func(i, j int) bool {
+		if witnessSet.Redeemers[i].Tag != witnessSet.Redeemers[j].Tag {
+			return witnessSet.Redeemers[i].Tag < witnessSet.Redeemers[j].Tag
+		}
+
+		return witnessSet.Redeemers[i].Index < witnessSet.Redeemers[j].Index
+	})
+
+	for _, hash := range slices.Sorted(maps.Keys(uniqueScripts)) {
+		script := uniqueScripts[hash]
+
+		switch script.language {
+		case plutusLanguageV2:
+			witnessSet.PlutusV2Scripts = append(witnessSet.PlutusV2Scripts, script.bytes)
+		case plutusLanguageV3:
+			witnessSet.PlutusV3Scripts = append(witnessSet.PlutusV3Scripts, script.bytes)
+		}
+	}
+
+	for _, hash := range slices.Sorted(maps.Keys(uniqueDatums)) {
+		witnessSet.PlutusData = append(witnessSet.PlutusData, uniqueDatums[hash])
+	}
+
+	protocolParameters, err := b.getProtocolParameters()
+	if err != nil {
+		return err
+	}
+
+	body.ScriptDataHash, err = getScriptDataHash(
+		*protocolParameters, witnessSet.Redeemers, witnessSet.PlutusData, slices.Collect(maps.Keys(languages)))
+
+	return err
+}
+
+// getNativeScriptsCbor returns all unique native scripts sorted by their hashes (the same way ledger does)
+func (b *TxBuilder) getNativeScriptsCbor() ([]cbor.RawMessage, error) {
+	scripts := make([]IPolicyScript, 0, len(b.inputs)+len(b.certificates)+len(b.votes)+len(b.mints.policyScripts)+1)
+
+	for _, inp := range b.inputs {
+		// script used by reference is not part of the witness set
+		if inp.scriptRefInput == nil {
+			scripts = append(scripts, inp.policyScript)
+		}
+	}
+
+	for _, cert := range b.certificates {
+		isWitnessRequired, err := cert.IsWitnessRequired()
+		if err != nil {
+			return nil, err
+		}
+
+		if isWitnessRequired {
+			scripts = append(scripts, cert.policyScript)
+		}
+	}
+
+	for _, vote := range b.votes {
+		scripts = append(scripts, vote.policyScript)
+	}
+
+	scripts = append(scripts, b.withdrawalData.policyScript)
+	scripts = append(scripts, b.mints.policyScripts...)
+
+	type scriptWithHash struct {
+		hash []byte
+		cbor []byte
+	}
+
+	uniqueScripts := map[string]scriptWithHash{}
+
+	for _, script := range scripts {
+		if script == nil {
+			continue
+		}
+
+		policyScript, err := NewPolicyScriptFromIPolicyScript(script)
+		if err != nil {
+			return nil, err
+		}
+
+		scriptCbor, err := policyScript.GetBytesCBOR()
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := getNativeScriptHash(scriptCbor)
+		if err != nil {
+			return nil, err
+		}
+
+		uniqueScripts[string(hash)] = scriptWithHash{
+			hash: hash,
+			cbor: scriptCbor,
+		}
+	}
+
+	sortedScripts := make([]scriptWithHash, 0, len(uniqueScripts))
+	for _, x := range uniqueScripts {
+		sortedScripts = append(sortedScripts, x)
+	}
+
+	sort.Slice(sortedScripts, func(i, j int) bool {
+		return bytes.Compare(sortedScripts[i].hash, sortedScripts[j].hash) < 0
+	})
+
+	result := make([]cbor.RawMessage, len(sortedScripts))
+	for i, x := range sortedScripts {
+		result[i] = x.cbor
+	}
+
+	return result, nil
+}
+
+func (b *TxBuilder) createTxWitnessNative(txRaw []byte, wallet ITxSigner) ([]byte, error) {
+	txBody, err := getTxBodyFromRaw(txRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	witness, err := wallet.CreateTxWitness(getBlake2b256Hash(txBody))
+	if err != nil {
+		return nil, err
+	}
+
+	// the same format as witness created with `cardano-cli transaction witness` (key witness tag + witness)
+	return cborEncMode.Marshal([]any{0, cbor.RawMessage(witness)})
+}
+
+func (b *TxBuilder) assembleTxWitnessesNative(txRaw []byte, witnesses [][]byte) ([]byte, error) {
+	var (
+		tx            txCbor
+		witnessSet    map[uint64]cbor.RawMessage // keep other witnesses (scripts, redeemers, ...) as they are
+		vKeyWitnesses []txVKeyWitnessCbor
+	)
+
+	if err := cbor.Unmarshal(txRaw, &tx); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+
+	if err := cbor.Unmarshal(tx.WitnessSet, &witnessSet); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal witness set: %w", err)
+	}
+
+	if witnessSet == nil {
+		witnessSet = map[uint64]cbor.RawMessage{}
+	}
+
+	if raw, exists := witnessSet[0]; exists {
+		if err := cbor.Unmarshal(raw, &vKeyWitnesses); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal vkey witnesses: %w", err)
+		}
+	}
+
+	for _, witness := range witnesses {
+		signature, vKey, err := TxWitnessRaw(witness).GetSignatureAndVKey()
+		if err != nil {
+			return nil, err
+		}
+
+		vKeyWitnesses = append(vKeyWitnesses, txVKeyWitnessCbor{
+			VKey:      vKey,
+			Signature: signature,
+		})
+	}
+
+	vKeyWitnessesRaw, err := cborEncMode.Marshal(vKeyWitnesses)
+	if err != nil {
+		return nil, err
+	}
+
+	witnessSet[0] = vKeyWitnessesRaw
+
+	witnessSetRaw, err := cborEncMode.Marshal(witnessSet)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.WitnessSet = witnessSetRaw
+
+	return cborEncMode.Marshal(tx)
+}
+
+// GetBytesCBOR returns certificate cbor or nil if certificate is not set
+func (txCert txCertificateWithPolicyScript) GetBytesCBOR() ([]byte, error) {
+	if txCert.certificate == nil {
+		return nil, nil
+	}
+
+	bytes, err := txCert.certificate.GetBytesJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var certificate Certificate
+
+	if err := json.Unmarshal(bytes, &certificate); err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(certificate.CborHex)
+}
+
+// IsWitnessRequired returns false for certificates which do not require witness (stake registration)
+func (txCert txCertificateWithPolicyScript) IsWitnessRequired() (bool, error) {
+	certificateCbor, err := txCert.GetBytesCBOR()
+	if err != nil || certificateCbor == nil {
+		return false, err
+	}
+
+	var certificate []cbor.RawMessage
+
+	if err := cbor.Unmarshal(certificateCbor, &certificate); err != nil {
+		return false, fmt.Errorf("failed to unmarshal certificate: %w", err)
+	}
+
+	var certificateType uint64
+
+	if len(certificate) == 0 {
+		return false, errors.New("empty certificate")
+	}
+
+	if err := cbor.Unmarshal(certificate[0], &certificateType); err != nil {
+		return false, fmt.Errorf("failed to unmarshal certificate type: %w", err)
+	}
+
+	return certificateType != stakeRegistrationCertificateType, nil
+}
+
+// GetCborValue returns withdrawals map for transaction body
+func (txWithdrawalData txWithdrawalDataPolicyScript) GetCborValue() (map[cbor.ByteString]uint64, error) {
+	if txWithdrawalData.stakeAddress == "" {
+		return nil, nil
+	}
+
+	addr, err := NewCardanoAddressFromString(txWithdrawalData.stakeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stake address %s: %w", txWithdrawalData.stakeAddress, err)
+	}
+
+	return map[cbor.ByteString]uint64{
+		cbor.ByteString(addr.GetBytes()): txWithdrawalData.rewardAmount,
+	}, nil
+}
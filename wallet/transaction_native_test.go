@@ -0,0 +1,463 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyScript_GetPolicyID(t *testing.T) {
+	t.Parallel()
+
+	policyScript := NewPolicyScript([]string{
+		"0fb340e2fc18865fbf406dce76f743de13c46d2eb91d6e87e6eb63c6",
+		"41b46f772b622e7e5bc8970d128faccb7a457c610a48d514801a0411",
+		"5282885af1f234cb9407f05b120f2eb06872f297864ca9066a657011",
+		"6a2f73455484b658c168c18ed54222d189e7e746ec3dc2d8d8891e42",
+	}, 3)
+
+	policyID, err := policyScript.GetPolicyID()
+	require.NoError(t, err)
+
+	multiSigAddr, err := NewPolicyScriptEnterpriseAddress(TestNetNetwork, policyID)
+	require.NoError(t, err)
+
+	addr, err := NewCardanoAddressFromString(
+		"addr_test1xqdt3kene0l87agrdcsn7jzspfrj83h5svgmaw8rnzzva644n47f76yle0p2r8dzdz0elefvtaju8v79ddahutcg790s37mp24")
+	require.NoError(t, err)
+
+	assert.Equal(t, addr.GetInfo().Payment.Payload, multiSigAddr.GetInfo().Payment.Payload)
+
+	_, err = PolicyScript{Type: "unknown"}.GetPolicyID()
+	require.Error(t, err)
+}
+
+func TestTxBuilderNative(t *testing.T) {
+	t.Parallel()
+
+	walletsKeyHashes := []string{
+		"d6b67f93ffa4e2651271cc9bcdbdedb2539911266b534d9c163cba21",
+		"cba89c7084bf0ce4bf404346b668a7e83c8c9c250d1cafd8d8996e41",
+		"79df3577e4c7d7da04872c2182b8d8829d7b477912dbf35d89287c39",
+		"2368e8113bd5f32d713751791d29acee9e1b5a425b0454b963b2558b",
+		"06b4c7f5254d6395b527ac3de60c1d77194df7431d85fe55ca8f107d",
+	}
+	walletsFeeKeyHashes := []string{
+		"f0f4837b3a306752a2b3e52394168bc7391de3dce11364b723cc55cf",
+		"47344d5bd7b2fea56336ba789579705a944760032585ef64084c92db",
+		"f01018c1d8da54c2f557679243b09af1c4dd4d9c671512b01fa5f92b",
+		"6837232854849427dae7c45892032d7ded136c5beb13c68fda635d87",
+		"d215701e2eb17c741b9d306cba553f9fbaaca1e12a5925a065b90fa8",
+	}
+
+	policyScriptMultiSig := NewPolicyScript(walletsKeyHashes, len(walletsKeyHashes)*2/3+1)
+	policyScriptFeeMultiSig := NewPolicyScript(walletsFeeKeyHashes, len(walletsFeeKeyHashes)*2/3+1)
+
+	multisigPolicyID, err := policyScriptMultiSig.GetPolicyID()
+	require.NoError(t, err)
+
+	feeMultisigPolicyID, err := policyScriptFeeMultiSig.GetPolicyID()
+	require.NoError(t, err)
+
+	multiSigAddr, err := NewPolicyScriptEnterpriseAddress(TestNetNetwork, multisigPolicyID)
+	require.NoError(t, err)
+
+	multiSigFeeAddr, err := NewPolicyScriptEnterpriseAddress(TestNetNetwork, feeMultisigPolicyID)
+	require.NoError(t, err)
+
+	metadataBytes, err := json.Marshal(map[uint64]any{
+		0: map[string]any{
+			"type":       "multi",
+			"signers":    len(walletsKeyHashes),
+			"feeSigners": len(walletsFeeKeyHashes),
+		},
+		4: map[string]any{
+			"comp": "Ethernal",
+			"city": "Novi Sad",
+		},
+	})
+	require.NoError(t, err)
+
+	builder := NewTxBuilderNative()
+	defer builder.Dispose()
+
+	builder.SetTimeToLive(28096).SetProtocolParameters(protocolParameters)
+	builder.SetMetaData(metadataBytes).SetTestNetMagic(203)
+	builder.AddOutputs(TxOutput{
+		Addr:   "addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u",
+		Amount: uint64(1_000_000),
+	}, TxOutput{
+		Addr: multiSigAddr.String(),
+	}, TxOutput{
+		Addr: multiSigFeeAddr.String(),
+	})
+	builder.AddInputsWithScript(policyScriptMultiSig,
+		NewTxInput("e99a5bde15aa05f24fcc04b7eabc1520d3397283b1ee720de9fe2653abbb0c9f", 0),
+		NewTxInput("d1fd0d772be7741d9bfaf0b037d02d2867a987ccba3e6ba2ee9aa2a861b73145", 2))
+	builder.AddInputsWithScript(policyScriptFeeMultiSig,
+		NewTxInput("098236134e0f2077a6434dd9d7727126fa8b3627bcab3ae030a194d46eded73e", 0))
+
+	fee, err := builder.CalculateFee(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(264897), fee)
+
+	builder.SetFee(fee)
+
+	builder.UpdateOutputAmount(-2, uint64(1_000_000)*3-10-uint64(1_000_000))
+	builder.UpdateOutputAmount(-1, uint64(1_000_000)*2-fee)
+
+	txRaw, txHash, err := builder.Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "84a50083825820098236134e0f2077a6434dd9d7727126fa8b3627bcab3ae030a194d46eded73e00825820d1fd0d772be7741d9bfaf0b037d02d2867a987ccba3e6ba2ee9aa2a861b7314502825820e99a5bde15aa05f24fcc04b7eabc1520d3397283b1ee720de9fe2653abbb0c9f00018382581d60244877c1aeefc7fd5405a6e14d927d91758d45e37c20fa2ac89cb1671a000f424082581d704aaad0f0626a8ce7b097497e542055b6520842ade881f980e002ae661a001e847682581d703ea4c4aef89a27f111e78464d7d6717b099f85ce27109ee9e5fbddec1a001a79bf021a00040ac103196dc0075820802e4d6f15ce98826886a5451e94855e77aae779cb341d3aab1e3bae4fb2f78da10182830304858200581c47344d5bd7b2fea56336ba789579705a944760032585ef64084c92db8200581c6837232854849427dae7c45892032d7ded136c5beb13c68fda635d878200581cd215701e2eb17c741b9d306cba553f9fbaaca1e12a5925a065b90fa88200581cf01018c1d8da54c2f557679243b09af1c4dd4d9c671512b01fa5f92b8200581cf0f4837b3a306752a2b3e52394168bc7391de3dce11364b723cc55cf830304858200581c06b4c7f5254d6395b527ac3de60c1d77194df7431d85fe55ca8f107d8200581c2368e8113bd5f32d713751791d29acee9e1b5a425b0454b963b2558b8200581c79df3577e4c7d7da04872c2182b8d8829d7b477912dbf35d89287c398200581ccba89c7084bf0ce4bf404346b668a7e83c8c9c250d1cafd8d8996e418200581cd6b67f93ffa4e2651271cc9bcdbdedb2539911266b534d9c163cba21f5d90103a100a200a36a6665655369676e65727305677369676e657273056474797065656d756c746904a26463697479684e6f76692053616464636f6d706845746865726e616c", hex.EncodeToString(txRaw))
+	assert.Equal(t, "1b9298c51f4dc05c04cae37104124cfb76e9f98f04a7f6b8179cfe02913152ec", txHash)
+
+	signer, err := GenerateWallet(false)
+	require.NoError(t, err)
+
+	txSigned, err := builder.SignTx(txRaw, []ITxSigner{signer})
+	require.NoError(t, err)
+	require.NotEmpty(t, txSigned)
+
+	txHashBytes, err := hex.DecodeString(txHash)
+	require.NoError(t, err)
+
+	// witness for signed transaction is the same because the body is not changed
+	witness, err := builder.CreateTxWitness(txSigned, signer)
+	require.NoError(t, err)
+
+	signature, vkey, err := TxWitnessRaw(witness).GetSignatureAndVKey()
+	require.NoError(t, err)
+
+	assert.Equal(t, signer.VerificationKey, vkey)
+	require.NoError(t, VerifyMessage(txHashBytes, vkey, signature))
+}
+
+func TestTxBuilderNative_CertificatesAndWithdrawal(t *testing.T) {
+	t.Parallel()
+
+	policyScriptPaymentMultiSig := NewPolicyScript([]string{
+		"0fb340e2fc18865fbf406dce76f743de13c46d2eb91d6e87e6eb63c6",
+		"41b46f772b622e7e5bc8970d128faccb7a457c610a48d514801a0411",
+		"5282885af1f234cb9407f05b120f2eb06872f297864ca9066a657011",
+		"6a2f73455484b658c168c18ed54222d189e7e746ec3dc2d8d8891e42",
+	}, 3)
+	policyScriptStakeMultiSig := NewPolicyScript([]string{
+		"30356731c6f4d92598732163a68d9dcec7c386075d5da4f1dca5724d",
+		"794eb34ded015c701fcf7b6ec4e0476e3dc2054a8831f636361680c9",
+		"8d2f93fdc4dbe32b1cb6951a441f081d2d111cb4a4c79a69f27d00a9",
+		"9f584550989f8a6cd6ce152b1c34661a764e0237200359e0f553d7db",
+	}, 3)
+
+	const (
+		multiSigAddr       = "addr_test1xqdt3kene0l87agrdcsn7jzspfrj83h5svgmaw8rnzzva644n47f76yle0p2r8dzdz0elefvtaju8v79ddahutcg790s37mp24"
+		multiSigRewardAddr = "stake_test17z6e6lyldz0uhs4pnk3x38ulu5k97ewrk0zkk7m79uy0zhcp9x067"
+	)
+
+	registrationCertificate := &Certificate{
+		CborHex: "82008201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f",
+	}
+
+	testCases := []struct {
+		name          string
+		input         TxInput
+		sum           uint64
+		ttl           uint64
+		certificates  []ICertificate
+		rewardAmount  uint64
+		expectedFee   uint64
+		expectedHash  string
+		expectedTxRaw string
+	}{
+		{
+			name:          "registration",
+			input:         NewTxInput("bb88a2541d545044e400d37c3db3eeb7a452fd9f2c461c89451f7191cc4f4079", 0),
+			sum:           10_000_000,
+			ttl:           9211,
+			certificates:  []ICertificate{registrationCertificate},
+			expectedFee:   207917,
+			expectedHash:  "c6edbde4bf6421ddf7f51643da7ce602cd63ef396053c7a39bc081d332ca8009",
+			expectedTxRaw: "84a50081825820bb88a2541d545044e400d37c3db3eeb7a452fd9f2c461c89451f7191cc4f4079000181825839301ab8db33cbfe7f75036e213f48500a4723c6f48311beb8e39884ceeab59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f1a00956a53021a00032c2d031923fb048182008201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15fa10181830303848200581c0fb340e2fc18865fbf406dce76f743de13c46d2eb91d6e87e6eb63c68200581c41b46f772b622e7e5bc8970d128faccb7a457c610a48d514801a04118200581c5282885af1f234cb9407f05b120f2eb06872f297864ca9066a6570118200581c6a2f73455484b658c168c18ed54222d189e7e746ec3dc2d8d8891e42f5f6",
+		},
+		{
+			name:  "delegation",
+			input: NewTxInput("c6edbde4bf6421ddf7f51643da7ce602cd63ef396053c7a39bc081d332ca8009", 0),
+			sum:   9792083,
+			ttl:   11704,
+			certificates: []ICertificate{&Certificate{
+				CborHex: "83028201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f581c5acc3f8fbc6ecfb86ce73543217a860387c4281bb394b4a123f35b24",
+			}},
+			expectedFee:   215045,
+			expectedHash:  "19fc8df9a93cd82d0c3a36d2bf7b8b8d9bc00f1918b0e0ac1ec11ee49345d6ff",
+			expectedTxRaw: "84a50081825820c6edbde4bf6421ddf7f51643da7ce602cd63ef396053c7a39bc081d332ca8009000181825839301ab8db33cbfe7f75036e213f48500a4723c6f48311beb8e39884ceeab59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f1a0092224e021a0003480503192db8048183028201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f581c5acc3f8fbc6ecfb86ce73543217a860387c4281bb394b4a123f35b24a10182830303848200581c0fb340e2fc18865fbf406dce76f743de13c46d2eb91d6e87e6eb63c68200581c41b46f772b622e7e5bc8970d128faccb7a457c610a48d514801a04118200581c5282885af1f234cb9407f05b120f2eb06872f297864ca9066a6570118200581c6a2f73455484b658c168c18ed54222d189e7e746ec3dc2d8d8891e42830303848200581c30356731c6f4d92598732163a68d9dcec7c386075d5da4f1dca5724d8200581c794eb34ded015c701fcf7b6ec4e0476e3dc2054a8831f636361680c98200581c8d2f93fdc4dbe32b1cb6951a441f081d2d111cb4a4c79a69f27d00a98200581c9f584550989f8a6cd6ce152b1c34661a764e0237200359e0f553d7dbf5f6",
+		},
+		{
+			name:  "registration and delegation",
+			input: NewTxInput("a266468e13942a5a016c12f941864d13a6e82dce3073a7ec7e1a680c2011f1d4", 0),
+			sum:   10_000_000,
+			ttl:   2193,
+			certificates: []ICertificate{registrationCertificate, &Certificate{
+				CborHex: "83028201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f581c09ec0ea0c2a57205f31e5fced2964c2658bac5a3cc8dfc0e259c54cb",
+			}},
+			expectedFee:   216541,
+			expectedHash:  "f97a06232cd0998821768cf053964d8c265d28984a1ff29f50de097ed3add8b5",
+			expectedTxRaw: "84a50081825820a266468e13942a5a016c12f941864d13a6e82dce3073a7ec7e1a680c2011f1d4000181825839301ab8db33cbfe7f75036e213f48500a4723c6f48311beb8e39884ceeab59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f1a009548a3021a00034ddd03190891048282008201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f83028201581cb59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f581c09ec0ea0c2a57205f31e5fced2964c2658bac5a3cc8dfc0e259c54cba10182830303848200581c0fb340e2fc18865fbf406dce76f743de13c46d2eb91d6e87e6eb63c68200581c41b46f772b622e7e5bc8970d128faccb7a457c610a48d514801a04118200581c5282885af1f234cb9407f05b120f2eb06872f297864ca9066a6570118200581c6a2f73455484b658c168c18ed54222d189e7e746ec3dc2d8d8891e42830303848200581c30356731c6f4d92598732163a68d9dcec7c386075d5da4f1dca5724d8200581c794eb34ded015c701fcf7b6ec4e0476e3dc2054a8831f636361680c98200581c8d2f93fdc4dbe32b1cb6951a441f081d2d111cb4a4c79a69f27d00a98200581c9f584550989f8a6cd6ce152b1c34661a764e0237200359e0f553d7dbf5f6",
+		},
+		{
+			name:          "withdrawal",
+			input:         NewTxInput("19fc8df9a93cd82d0c3a36d2bf7b8b8d9bc00f1918b0e0ac1ec11ee49345d6ff", 0),
+			sum:           9577038,
+			ttl:           19910,
+			rewardAmount:  1539043,
+			expectedFee:   213813,
+			expectedHash:  "176a8396965f93426300f0cb88e0909b4e321c1a74e0f799f7af5124f81082a5",
+			expectedTxRaw: "84a5008182582019fc8df9a93cd82d0c3a36d2bf7b8b8d9bc00f1918b0e0ac1ec11ee49345d6ff000181825839301ab8db33cbfe7f75036e213f48500a4723c6f48311beb8e39884ceeab59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f1a00a65afc021a0003433503194dc605a1581df0b59d7c9f689fcbc2a19da2689f9fe52c5f65c3b3c56b7b7e2f08f15f1a00177be3a10182830303848200581c0fb340e2fc18865fbf406dce76f743de13c46d2eb91d6e87e6eb63c68200581c41b46f772b622e7e5bc8970d128faccb7a457c610a48d514801a04118200581c5282885af1f234cb9407f05b120f2eb06872f297864ca9066a6570118200581c6a2f73455484b658c168c18ed54222d189e7e746ec3dc2d8d8891e42830303848200581c30356731c6f4d92598732163a68d9dcec7c386075d5da4f1dca5724d8200581c794eb34ded015c701fcf7b6ec4e0476e3dc2054a8831f636361680c98200581c8d2f93fdc4dbe32b1cb6951a441f081d2d111cb4a4c79a69f27d00a98200581c9f584550989f8a6cd6ce152b1c34661a764e0237200359e0f553d7dbf5f6",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := NewTxBuilderNative()
+			defer builder.Dispose()
+
+			builder.SetTimeToLive(tc.ttl).SetProtocolParameters(protocolParameters)
+			builder.SetTestNetMagic(2)
+			builder.AddInputsWithScript(policyScriptPaymentMultiSig, tc.input)
+			builder.AddOutputs(TxOutput{
+				Addr: multiSigAddr,
+			})
+
+			if len(tc.certificates) > 0 {
+				builder.AddCertificates(policyScriptStakeMultiSig, tc.certificates...)
+			}
+
+			if tc.rewardAmount > 0 {
+				builder.SetWithdrawalData(multiSigRewardAddr, tc.rewardAmount, policyScriptStakeMultiSig)
+			}
+
+			fee, err := builder.CalculateFee(0)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedFee, fee)
+
+			builder.SetFee(fee)
+			builder.UpdateOutputAmount(-1, tc.sum+tc.rewardAmount-fee)
+
+			txRaw, txHash, err := builder.Build()
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expectedHash, txHash)
+			require.Equal(t, tc.expectedTxRaw, hex.EncodeToString(txRaw))
+		})
+	}
+}
+
+func TestTxBuilderNative_CreateTxWitnessAndAssembleTxWitnesses(t *testing.T) {
+	t.Parallel()
+
+	const (
+		skey        = "58800800c832ac40041bcbd83fc7b6be8f9a93c508d06f767518bad3266d62c3ad497d022a84b1b6663e0c3c62955c43bdfc333b3434ea232ab4e8c41d6b99c7ee12c73cd59dbfba2e07577ad69621e964d404c7bef56f69e1691438abd373561999899ccba5b358e8e3af736263283a472bb941c185ff4b523f532800766f1427c2"
+		witnessData = "825820c73cd59dbfba2e07577ad69621e964d404c7bef56f69e1691438abd37356199958408233a747b14fc78ba32fbe8501b842d3290c591a565f589dbeec1c1e8b3dfe27de19002784c6c7020871fd07a5dd70e1003b6d1449255985c823464123085a00"
+		txRaw       = "84a500818258201f55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb01018282581d70c4aab1955b120811d634e3a1b282ea090537d9e753842e8f46c280041a00200b2082583900712c77c7e146b95a569f2f7edf1dd81df2545edecb132701f17f84d4694c18049dcafc175d262c06eac9f52b86f205e38e8bfca6e6a545611a055e8308021a0002e908031a0152a319075820cb1b53bb62ee65e8ae893d04331dcc70d745298a32fcedf5ff9cc7a12d8471e3a0f5d90103a100a101a5616466766563746f726266611a0010c8e06173837828616464725f74657374317170636a63613738753972746a6b6a6b6e756868616863616d71776c793478287a376d6d39337866637037396c636634726666737671663877326c73743436663376716d34766e61781c6674736d6571746375773330373264653439673473737a333437377a61746662726964676562747881a26161827828766563746f725f7465737431766772677868347333356135706476306463347a6771333363726e33781934656d6e6b326537766e656e73663474657a7133746b6d396d616d1a000f4240"
+		txWitness   = "84a500818258201f55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb01018282581d70c4aab1955b120811d634e3a1b282ea090537d9e753842e8f46c280041a00200b2082583900712c77c7e146b95a569f2f7edf1dd81df2545edecb132701f17f84d4694c18049dcafc175d262c06eac9f52b86f205e38e8bfca6e6a545611a055e8308021a0002e908031a0152a319075820cb1b53bb62ee65e8ae893d04331dcc70d745298a32fcedf5ff9cc7a12d8471e3a10081825820c73cd59dbfba2e07577ad69621e964d404c7bef56f69e1691438abd37356199958408233a747b14fc78ba32fbe8501b842d3290c591a565f589dbeec1c1e8b3dfe27de19002784c6c7020871fd07a5dd70e1003b6d1449255985c823464123085a00f5d90103a100a101a5616466766563746f726266611a0010c8e06173837828616464725f74657374317170636a63613738753972746a6b6a6b6e756868616863616d71776c793478287a376d6d39337866637037396c636634726666737671663877326c73743436663376716d34766e61781c6674736d6571746375773330373264653439673473737a333437377a61746662726964676562747881a26161827828766563746f725f7465737431766772677868347333356135706476306463347a6771333363726e33781934656d6e6b326537766e656e73663474657a7133746b6d396d616d1a000f4240"
+	)
+
+	skeyBytes, err := GetKeyBytes(skey)
+	require.NoError(t, err)
+
+	txRawBytes, err := hex.DecodeString(txRaw)
+	require.NoError(t, err)
+
+	txBuilder := NewTxBuilderNative()
+	defer txBuilder.Dispose()
+
+	txWitnessBytes, err := txBuilder.CreateTxWitness(txRawBytes, NewWallet(skeyBytes, nil))
+	require.NoError(t, err)
+
+	require.Equal(t, "8200"+witnessData, hex.EncodeToString(txWitnessBytes))
+
+	txFinal, err := txBuilder.AssembleTxWitnesses(txRawBytes, [][]byte{txWitnessBytes})
+	require.NoError(t, err)
+
+	require.Equal(t, txWitness, hex.EncodeToString(txFinal))
+}
+
+func TestTxBuilderNative_CalculateMinUtxo(t *testing.T) {
+	t.Parallel()
+
+	token1, _ := NewTokenWithFullName("29f8873beb52e126f207a2dfd50f7cff556806b5b4cba9834a7b26a8.4b6173685f546f6b656e", true)
+	token2, _ := NewTokenWithFullName("29f8873beb52e126f207a2dfd50f7cff556806b5b4cba9834a7b26a8.Route3", false)
+	token3, _ := NewTokenWithFullName("29f8873beb52e126f207a2dfd50f7cff556806b5b4cba9834a7b26a8.Route345", false)
+
+	output := TxOutput{
+		Addr:   "addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u",
+		Amount: uint64(1_000_000),
+		Tokens: []TokenAmount{
+			NewTokenAmount(token1, 11_000_039),
+			NewTokenAmount(token2, 236_872_039),
+			NewTokenAmount(token3, 12_236_872_039),
+		},
+	}
+
+	txBuilder := NewTxBuilderNative()
+	defer txBuilder.Dispose()
+
+	txBuilder.SetProtocolParameters(protocolParameters)
+
+	minUtxo, err := txBuilder.CalculateMinUtxo(output)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1189560), minUtxo)
+
+	output.Tokens[0].Amount = 2
+
+	minUtxo, err = txBuilder.CalculateMinUtxo(output)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1172320), minUtxo)
+
+	output.Tokens[1].Amount = 3
+
+	minUtxo, err = txBuilder.CalculateMinUtxo(output)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1155080), minUtxo)
+
+	output.Tokens = output.Tokens[:len(output.Tokens)-1]
+
+	minUtxo, err = txBuilder.CalculateMinUtxo(output)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1077500), minUtxo)
+
+	output.Tokens = nil
+
+	minUtxo, err = txBuilder.CalculateMinUtxo(output)
+	require.NoError(t, err)
+	require.Equal(t, uint64(849070), minUtxo)
+
+	output.Amount = 3_600_000_348_100_893_234
+
+	minUtxo, err = txBuilder.CalculateMinUtxo(output)
+	require.NoError(t, err)
+	require.Equal(t, uint64(849070), minUtxo)
+}
+
+func TestNewTxAuxDataCbor(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTxAuxDataCbor([]byte(`{"a": 1}`))
+	require.ErrorIs(t, err, errMetadataInvalid)
+
+	_, err = newTxAuxDataCbor([]byte(`{"1": "` + strings.Repeat("a", metadataMaxStringLength+1) + `"}`))
+	require.ErrorIs(t, err, errMetadataInvalid)
+
+	_, err = newTxAuxDataCbor([]byte(`{"1": 1.5}`))
+	require.ErrorIs(t, err, errMetadataInvalid)
+
+	auxData, err := newTxAuxDataCbor([]byte(`{"1": {"b": "0x0102", "10": [1, -2]}}`))
+	require.NoError(t, err)
+	require.Equal(t, "d90103a100a101a20a8201216162420102", hex.EncodeToString(auxData))
+}
+
+func TestTxBuilderNative_ReferenceScripts(t *testing.T) {
+	t.Parallel()
+
+	const (
+		address       = "addr_test1vqjysa7p4mhu0l25qknwznvj0kghtr29ud7zp732ezwtzec0w8g3u"
+		scriptInpHash = "1f55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb"
+		refInpHash    = "ff55818892cc447cbf9fc27e04899ea98795538889555d3846a8071f4fdb75eb"
+	)
+
+	var pp ProtocolParameters
+
+	require.NoError(t, json.Unmarshal(protocolParameters, &pp))
+
+	policyScript := NewPolicyScript([]string{
+		"d6b67f93ffa4e2651271cc9bcdbdedb2539911266b534d9c163cba21",
+		"cba89c7084bf0ce4bf404346b668a7e83c8c9c250d1cafd8d8996e41",
+		"79df3577e4c7d7da04872c2182b8d8829d7b477912dbf35d89287c39",
+	}, 2)
+
+	scriptRef, err := NewScriptRefFromPolicyScript(policyScript)
+	require.NoError(t, err)
+
+	scriptCbor, err := policyScript.GetBytesCBOR()
+	require.NoError(t, err)
+
+	refInput := NewTxInput(refInpHash, 0)
+
+	txBuilder := NewTxBuilderNative()
+	defer txBuilder.Dispose()
+
+	txBuilder.SetProtocolParameters(protocolParameters).SetTimeToLive(1000).
+		AddInputsWithScriptReference(refInput, policyScript, NewTxInput(scriptInpHash, 1), NewTxInput(scriptInpHash, 2)).
+		AddReferenceInputs(NewTxInput(refInpHash, 3), refInput).
+		AddOutputs(TxOutput{Addr: address, Amount: 2_000_000, ScriptRef: scriptRef})
+
+	refScriptsSize, err := txBuilder.getReferenceScriptsSize()
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(scriptCbor)), refScriptsSize)
+
+	fee, err := txBuilder.CalculateFee(0)
+	require.NoError(t, err)
+
+	txRawWithoutFee, _, err := txBuilder.buildRawTxNative(0)
+	require.NoError(t, err)
+
+	feeWithoutRefScripts, err := CalculateMinFee(pp, txRawWithoutFee, txBuilder.getWitnessCount())
+	require.NoError(t, err)
+	require.Equal(t, feeWithoutRefScripts+CalculateRefScriptFee(pp, refScriptsSize), fee)
+
+	txRaw, _, err := txBuilder.SetFee(fee).Build()
+	require.NoError(t, err)
+
+	var (
+		tx   txCbor
+		body struct {
+			Inputs          []txInputCbor     `cbor:"0,keyasint"`
+			Outputs         []cbor.RawMessage `cbor:"1,keyasint"`
+			ReferenceInputs []txInputCbor     `cbor:"18,keyasint"`
+		}
+		witnessSet txWitnessSetCbor
+	)
+
+	require.NoError(t, cbor.Unmarshal(txRaw, &tx))
+	require.NoError(t, cbor.Unmarshal(tx.Body, &body))
+	require.NoError(t, cbor.Unmarshal(tx.WitnessSet, &witnessSet))
+
+	require.Len(t, body.Inputs, 2)
+	require.Len(t, body.ReferenceInputs, 2)
+	require.Equal(t, uint32(0), body.ReferenceInputs[0].Index)
+	require.Equal(t, uint32(3), body.ReferenceInputs[1].Index)
+	require.Empty(t, witnessSet.NativeScripts)
+	// script reference is the last field of the map output: 3 => 24(bytes)
+	require.True(t, strings.HasSuffix(hex.EncodeToString(body.Outputs[0]), "03d818"+
+		hex.EncodeToString([]byte{0x58, byte(len(scriptRef))})+hex.EncodeToString(scriptRef)))
+
+	minUtxoWithScriptRef, err := txBuilder.CalculateMinUtxo(TxOutput{Addr: address, ScriptRef: scriptRef})
+	require.NoError(t, err)
+
+	minUtxo, err := txBuilder.CalculateMinUtxo(TxOutput{Addr: address})
+	require.NoError(t, err)
+	require.Greater(t, minUtxoWithScriptRef, minUtxo)
+}
+
+func TestTxBuilderNative_GetReferenceInputs(t *testing.T) {
+	const (
+		hashA = "0a0b2e2b7f8c4ae6cfc6d61d1b1e4c5c4c0a1e0d0b1b4f1d5cf30b9a14d0c3cc"
+		hashB = "f70b2e2b7f8c4ae6cfc6d61d1b1e4c5c4c0a1e0d0b1b4f1d5cf30b9a14d0c3cc"
+	)
+
+	txBuilder := NewTxBuilderNative()
+	defer txBuilder.Dispose()
+
+	txBuilder.AddReferenceInputs(
+		NewTxInput(hashB, 1), NewTxInput(hashA, 10), NewTxInput(hashA, 9), NewTxInput(hashA, 10),
+		NewTxInput(strings.ToUpper(hashB), 1))
+
+	// numeric index order (9 before 10) and duplicates (regardless of the hex case) removed
+	require.Equal(t, []TxInput{
+		NewTxInput(hashA, 9), NewTxInput(hashA, 10), NewTxInput(hashB, 1),
+	}, txBuilder.getReferenceInputs())
+}
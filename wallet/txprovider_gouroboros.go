@@ -24,6 +24,7 @@ type txProviderGoUroBorosConfig struct {
 	keepAlive       bool
 	txSubmitTimeout time.Duration
 	acquireTimeout  time.Duration
+	txRetriever     ITxRetriever
 	logger          hclog.Logger
 }
 
@@ -47,6 +48,14 @@ func WithTxProviderGoUroBorosAcquireTimeout(acquireTimeout time.Duration) TxProv
 	}
 }
 
+// WithTxProviderGoUroBorosTxRetriever sets retriever used by GetTxByHash (for example indexer database retriever)
+// because txs can not be queried from the node
+func WithTxProviderGoUroBorosTxRetriever(txRetriever ITxRetriever) TxProviderGoUroBorosOption {
+	return func(c *txProviderGoUroBorosConfig) {
+		c.txRetriever = txRetriever
+	}
+}
+
 func WithTxProviderGoUroBorosLogger(logger hclog.Logger) TxProviderGoUroBorosOption {
 	return func(c *txProviderGoUroBorosConfig) {
 		c.logger = logger
@@ -221,7 +230,11 @@ func (b *TxProviderGoUroBoros) SubmitTx(ctx context.Context, txSigned []byte) er
 }
 
 func (b *TxProviderGoUroBoros) GetTxByHash(ctx context.Context, hash string) (map[string]interface{}, error) {
-	panic("not implemented") //nolint:gocritic
+	if b.config.txRetriever == nil {
+		return nil, errors.New("tx retriever is not set")
+	}
+
+	return b.config.txRetriever.GetTxByHash(ctx, hash)
 }
 
 func (b *TxProviderGoUroBoros) getConnection() (*gouroboros.Connection, error) {
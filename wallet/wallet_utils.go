@@ -3,11 +3,13 @@ package wallet
 import (
 	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/sha512"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 
+	"filippo.io/edwards25519"
 	"github.com/Ethernal-Tech/cardano-infrastructure/wallet/bech32"
 	"github.com/fxamacker/cbor/v2"
 	"golang.org/x/crypto/blake2b"
@@ -45,7 +47,7 @@ func VerifyWitness(txHash string, witness []byte) error {
 	return VerifyMessage(txHashBytes, vKey, signature)
 }
 
-// SignMessage signs message
+// SignMessage signs message. Both ed25519 and extended (bip32-ed25519) signing keys are supported
 func SignMessage(signingKey, verificationKey, message []byte) (result []byte, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -53,6 +55,10 @@ func SignMessage(signingKey, verificationKey, message []byte) (result []byte, er
 		}
 	}()
 
+	if len(signingKey) > KeySize {
+		return signMessageExtended(signingKey, verificationKey, message)
+	}
+
 	privateKey := make([]byte, len(signingKey)+len(verificationKey))
 
 	copy(privateKey, signingKey)
@@ -63,6 +69,51 @@ func SignMessage(signingKey, verificationKey, message []byte) (result []byte, er
 	return
 }
 
+// signMessageExtended signs message with extended signing key (kL || kR || ...)
+// kL is already clamped scalar and kR is used as nonce prefix instead of the seed hash
+func signMessageExtended(signingKey, verificationKey, message []byte) ([]byte, error) {
+	if len(signingKey) < 2*KeySize || len(verificationKey) != KeySize {
+		return nil, fmt.Errorf("invalid extended key sizes: %d, %d", len(signingKey), len(verificationKey))
+	}
+
+	scalarFromBytes := func(bytes []byte) (*edwards25519.Scalar, error) {
+		wide := make([]byte, 64)
+		copy(wide, bytes)
+
+		return edwards25519.NewScalar().SetUniformBytes(wide)
+	}
+
+	kL, err := scalarFromBytes(signingKey[:KeySize])
+	if err != nil {
+		return nil, err
+	}
+
+	nonceHash := sha512.New()
+	nonceHash.Write(signingKey[KeySize : 2*KeySize])
+	nonceHash.Write(message)
+
+	r, err := scalarFromBytes(nonceHash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	pointR := new(edwards25519.Point).ScalarBaseMult(r).Bytes()
+
+	challengeHash := sha512.New()
+	challengeHash.Write(pointR)
+	challengeHash.Write(verificationKey)
+	challengeHash.Write(message)
+
+	h, err := scalarFromBytes(challengeHash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	s := edwards25519.NewScalar().MultiplyAdd(h, kL, r)
+
+	return append(pointR, s.Bytes()...), nil
+}
+
 // VerifyMessage verifies message with verificationKey and signature
 func VerifyMessage(message, verificationKey, signature []byte) (err error) {
 	defer func() {